@@ -7,7 +7,9 @@ package plot
 import (
 	"image/color"
 	"math"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/gonum/floats"
 	"github.com/gonum/plot/vg"
@@ -23,6 +25,13 @@ type Ticker interface {
 	Ticks(min, max float64) []Tick
 }
 
+// TickFormatter formats the labels of major tick marks.
+type TickFormatter interface {
+	// Format returns the label of a
+	// major tick mark at the value v.
+	Format(v float64) string
+}
+
 // Normalizer rescales values from the data coordinate system to the
 // normalized coordinate system.
 type Normalizer interface {
@@ -56,6 +65,9 @@ type Axis struct {
 
 	Tick struct {
 		// Label is the TextStyle on the tick labels.
+		// Rotated tick labels are drawn with the end
+		// of the label nearest to the axis aligned with
+		// the tick mark.
 		Label draw.TextStyle
 
 		// LineStyle is the LineStyle of the tick lines.
@@ -70,12 +82,33 @@ type Axis struct {
 		// returned by the Marker function that are not in
 		// range of the axis are not drawn.
 		Marker Ticker
+
+		// Formatter, if not nil, formats the labels of
+		// the major tick marks returned by Marker in place
+		// of the labels given by Marker. It is intended
+		// for tickers of numeric values, such as
+		// DefaultTicks and LogTicks.
+		Formatter TickFormatter
+
+		// Thin specifies whether the labels of major tick
+		// marks that would overlap when drawn are dropped,
+		// leaving minor tick marks in their place. Labels
+		// are kept at every n'th major tick mark, for the
+		// smallest n for which no labels overlap.
+		Thin bool
 	}
 
 	// Scale transforms a value given in the data coordinate system
 	// to the normalized coordinate system of the axis—its distance
 	// along the axis as a fraction of the axis range.
 	Scale Normalizer
+
+	// AutoRange specifies whether Min and Max are
+	// recomputed from the data ranges of the plotters
+	// bound to the axis each time the plot is drawn,
+	// so that the axis follows data that change after
+	// the plotters are added to the plot.
+	AutoRange bool
 }
 
 // makeAxis returns a default Axis.
@@ -168,6 +201,22 @@ func (a *Axis) Norm(x float64) float64 {
 	return a.Scale.Normalize(a.Min, a.Max, x)
 }
 
+// ticks returns the tick marks of the axis, with the
+// labels of the major tick marks given by Tick.Formatter.
+func (a *Axis) ticks() []Tick {
+	marks := a.Tick.Marker.Ticks(a.Min, a.Max)
+	if a.Tick.Formatter == nil {
+		return marks
+	}
+	marks = append([]Tick(nil), marks...)
+	for i, t := range marks {
+		if !t.IsMinor() {
+			marks[i].Label = a.Tick.Formatter.Format(t.Value)
+		}
+	}
+	return marks
+}
+
 // drawTicks returns true if the tick marks should be drawn.
 func (a *Axis) drawTicks() bool {
 	return a.Tick.Width > 0 && a.Tick.Length > 0
@@ -185,7 +234,7 @@ func (a *horizontalAxis) size() (h vg.Length) {
 		h -= a.Label.Font.Extents().Descent
 		h += a.Label.Height(a.Label.Text)
 	}
-	if marks := a.Tick.Marker.Ticks(a.Min, a.Max); len(marks) > 0 {
+	if marks := a.ticks(); len(marks) > 0 {
 		if a.drawTicks() {
 			h += a.Tick.Length
 		}
@@ -205,17 +254,24 @@ func (a *horizontalAxis) draw(c draw.Canvas) {
 		y += a.Label.Height(a.Label.Text)
 	}
 
-	marks := a.Tick.Marker.Ticks(a.Min, a.Max)
+	marks := a.ticks()
+	height := tickLabelHeight(a.Tick.Label, marks)
+	xalign, yalign := horizontalTickAlign(a.Tick.Label, false)
+	marks = a.thin(marks, c, xalign, yalign, true)
 	for _, t := range marks {
 		x := c.X(a.Norm(t.Value))
 		if !c.ContainsX(x) || t.IsMinor() {
 			continue
 		}
-		c.FillText(a.Tick.Label, x, y, -0.5, 0, t.Label)
+		ty := y
+		if a.Tick.Label.Rotation != 0 {
+			ty += height - a.Tick.Label.AlignedRectangle(t.Label, xalign, yalign).Max.Y
+		}
+		c.FillText(a.Tick.Label, x, ty, xalign, yalign, t.Label)
 	}
 
 	if len(marks) > 0 {
-		y += tickLabelHeight(a.Tick.Label, marks)
+		y += height
 	} else {
 		y += a.Width / 2
 	}
@@ -233,23 +289,88 @@ func (a *horizontalAxis) draw(c draw.Canvas) {
 		y += len
 	}
 
-	c.StrokeLine2(a.LineStyle, c.Min.X, y, c.Max.X, y)
+	a.drawLine(c, true, y)
 }
 
 // GlyphBoxes returns the GlyphBoxes for the tick labels.
-func (a *horizontalAxis) GlyphBoxes(*Plot) (boxes []GlyphBox) {
-	for _, t := range a.Tick.Marker.Ticks(a.Min, a.Max) {
-		if t.IsMinor() {
+func (a *horizontalAxis) GlyphBoxes(*Plot) []GlyphBox {
+	xalign, yalign := horizontalTickAlign(a.Tick.Label, false)
+	return a.tickGlyphBoxes(xalign, yalign, true)
+}
+
+// A topAxis is a horizontalAxis drawn across the top of a plot,
+// with its labels above the axis line.
+type topAxis struct {
+	horizontalAxis
+}
+
+// draw draws the axis along the upper edge of a draw.Canvas.
+func (a *topAxis) draw(c draw.Canvas) {
+	y := c.Max.Y
+	if a.Label.Text != "" {
+		c.FillText(a.Label.TextStyle, c.Center().X, y, -0.5, -1, a.Label.Text)
+		y -= a.Label.Height(a.Label.Text) - a.Label.Font.Extents().Descent
+	}
+
+	marks := a.ticks()
+	height := tickLabelHeight(a.Tick.Label, marks)
+	xalign, yalign := horizontalTickAlign(a.Tick.Label, true)
+	marks = a.thin(marks, c, xalign, yalign, true)
+	for _, t := range marks {
+		x := c.X(a.Norm(t.Value))
+		if !c.ContainsX(x) || t.IsMinor() {
 			continue
 		}
-		w := a.Tick.Label.Width(t.Label)
-		box := GlyphBox{
-			X:         a.Norm(t.Value),
-			Rectangle: draw.Rectangle{draw.Point{X: -w / 2}, draw.Point{X: w / 2}},
+		ty := y
+		if a.Tick.Label.Rotation != 0 {
+			ty -= height + a.Tick.Label.AlignedRectangle(t.Label, xalign, yalign).Min.Y
 		}
-		boxes = append(boxes, box)
+		c.FillText(a.Tick.Label, x, ty, xalign, yalign, t.Label)
 	}
-	return
+
+	if len(marks) > 0 {
+		y -= height
+	} else {
+		y -= a.Width / 2
+	}
+
+	if len(marks) > 0 && a.drawTicks() {
+		len := a.Tick.Length
+		for _, t := range marks {
+			x := c.X(a.Norm(t.Value))
+			if !c.ContainsX(x) {
+				continue
+			}
+			start := t.lengthOffset(len)
+			c.StrokeLine2(a.Tick.LineStyle, x, y-start, x, y-len)
+		}
+		y -= len
+	}
+
+	a.drawLine(c, true, y)
+}
+
+// GlyphBoxes returns the GlyphBoxes for the tick labels.
+func (a *topAxis) GlyphBoxes(*Plot) []GlyphBox {
+	xalign, yalign := horizontalTickAlign(a.Tick.Label, true)
+	return a.tickGlyphBoxes(xalign, yalign, true)
+}
+
+// horizontalTickAlign returns the alignment of the tick labels
+// of a horizontal axis, drawn below the tick marks or above them
+// if top is true. Rotated labels are aligned at the middle of
+// the end that is nearest to the axis.
+func horizontalTickAlign(sty draw.TextStyle, top bool) (xalign, yalign float64) {
+	if sty.Rotation == 0 {
+		if top {
+			return -0.5, -1
+		}
+		return -0.5, 0
+	}
+	if (math.Sin(sty.Rotation) > 0) != top {
+		return -1, -0.5
+	}
+	return 0, -0.5
 }
 
 // A verticalAxis is drawn vertically up the left side of a plot.
@@ -261,9 +382,9 @@ type verticalAxis struct {
 func (a *verticalAxis) size() (w vg.Length) {
 	if a.Label.Text != "" {
 		w -= a.Label.Font.Extents().Descent
-		w += a.Label.Height(a.Label.Text)
+		w += a.labelStyle().Width(a.Label.Text)
 	}
-	if marks := a.Tick.Marker.Ticks(a.Min, a.Max); len(marks) > 0 {
+	if marks := a.ticks(); len(marks) > 0 {
 		if lwidth := tickLabelWidth(a.Tick.Label, marks); lwidth > 0 {
 			w += lwidth
 			w += a.Label.Width(" ")
@@ -281,24 +402,24 @@ func (a *verticalAxis) size() (w vg.Length) {
 func (a *verticalAxis) draw(c draw.Canvas) {
 	x := c.Min.X
 	if a.Label.Text != "" {
-		x += a.Label.Height(a.Label.Text)
-		c.Push()
-		c.Rotate(math.Pi / 2)
-		c.FillText(a.Label.TextStyle, c.Center().Y, -x, -0.5, 0, a.Label.Text)
-		c.Pop()
+		sty := a.labelStyle()
+		x += sty.Width(a.Label.Text)
+		c.FillText(sty, x, c.Center().Y, -0.5, 0, a.Label.Text)
 		x += -a.Label.Font.Extents().Descent
 	}
-	marks := a.Tick.Marker.Ticks(a.Min, a.Max)
+	marks := a.ticks()
 	if w := tickLabelWidth(a.Tick.Label, marks); len(marks) > 0 && w > 0 {
 		x += w
 	}
+	marks = a.thin(marks, c, -1, -0.5, false)
 	major := false
 	for _, t := range marks {
 		y := c.Y(a.Norm(t.Value))
 		if !c.ContainsY(y) || t.IsMinor() {
 			continue
 		}
-		c.FillText(a.Tick.Label, x, y, -1, -0.5, t.Label)
+		tx := x - a.Tick.Label.AlignedRectangle(t.Label, -1, -0.5).Max.X
+		c.FillText(a.Tick.Label, tx, y, -1, -0.5, t.Label)
 		major = true
 	}
 	if major {
@@ -316,25 +437,199 @@ func (a *verticalAxis) draw(c draw.Canvas) {
 		}
 		x += len
 	}
-	c.StrokeLine2(a.LineStyle, x, c.Min.Y, x, c.Max.Y)
+	a.drawLine(c, false, x)
+}
+
+// labelStyle returns the style of the axis label,
+// which is drawn rotated by π/2.
+func (a *verticalAxis) labelStyle() draw.TextStyle {
+	sty := a.Label.TextStyle
+	sty.Rotation = math.Pi / 2
+	return sty
 }
 
 // GlyphBoxes returns the GlyphBoxes for the tick labels
-func (a *verticalAxis) GlyphBoxes(*Plot) (boxes []GlyphBox) {
-	for _, t := range a.Tick.Marker.Ticks(a.Min, a.Max) {
+func (a *verticalAxis) GlyphBoxes(*Plot) []GlyphBox {
+	return a.tickGlyphBoxes(-1, -0.5, false)
+}
+
+// A rightAxis is a verticalAxis drawn up the right side of a plot,
+// with its labels to the right of the axis line.
+type rightAxis struct {
+	verticalAxis
+}
+
+// draw draws the axis along the right side of a draw.Canvas.
+func (a *rightAxis) draw(c draw.Canvas) {
+	x := c.Max.X
+	if a.Label.Text != "" {
+		sty := a.labelStyle()
+		x += a.Label.Font.Extents().Descent
+		c.FillText(sty, x, c.Center().Y, -0.5, 0, a.Label.Text)
+		x -= sty.Width(a.Label.Text)
+	}
+	marks := a.ticks()
+	if w := tickLabelWidth(a.Tick.Label, marks); len(marks) > 0 && w > 0 {
+		x -= w
+	}
+	marks = a.thin(marks, c, 0, -0.5, false)
+	major := false
+	for _, t := range marks {
+		y := c.Y(a.Norm(t.Value))
+		if !c.ContainsY(y) || t.IsMinor() {
+			continue
+		}
+		tx := x - a.Tick.Label.AlignedRectangle(t.Label, 0, -0.5).Min.X
+		c.FillText(a.Tick.Label, tx, y, 0, -0.5, t.Label)
+		major = true
+	}
+	if major {
+		x -= a.Tick.Label.Width(" ")
+	}
+	if a.drawTicks() && len(marks) > 0 {
+		len := a.Tick.Length
+		for _, t := range marks {
+			y := c.Y(a.Norm(t.Value))
+			if !c.ContainsY(y) {
+				continue
+			}
+			start := t.lengthOffset(len)
+			c.StrokeLine2(a.Tick.LineStyle, x-start, y, x-len, y)
+		}
+		x -= len
+	}
+	a.drawLine(c, false, x)
+}
+
+// GlyphBoxes returns the GlyphBoxes for the tick labels
+func (a *rightAxis) GlyphBoxes(*Plot) []GlyphBox {
+	return a.tickGlyphBoxes(0, -0.5, false)
+}
+
+// tickGlyphBoxes returns the GlyphBoxes for the major tick
+// labels drawn with the given alignment. The boxes extend
+// along the axis, horizontally if horizontal is true and
+// vertically otherwise.
+func (a *Axis) tickGlyphBoxes(xalign, yalign float64, horizontal bool) (boxes []GlyphBox) {
+	for _, t := range a.ticks() {
 		if t.IsMinor() {
 			continue
 		}
-		h := a.Tick.Label.Height(t.Label)
-		box := GlyphBox{
-			Y:         a.Norm(t.Value),
-			Rectangle: draw.Rectangle{draw.Point{Y: -h / 2}, draw.Point{Y: h / 2}},
+		r := a.Tick.Label.AlignedRectangle(t.Label, xalign, yalign)
+		box := GlyphBox{Rectangle: r}
+		if horizontal {
+			box.X = a.Norm(t.Value)
+			box.Min.Y, box.Max.Y = 0, 0
+		} else {
+			box.Y = a.Norm(t.Value)
+			box.Min.X, box.Max.X = 0, 0
 		}
 		boxes = append(boxes, box)
 	}
 	return
 }
 
+// thinLabel is a tick mark label considered for thinning.
+type thinLabel struct {
+	// index is the index of the tick mark of the label.
+	index int
+
+	// pos is the position of the label along the axis,
+	// lo and hi are the ends of its extent about pos,
+	// and breadth is its unrotated height.
+	pos     vg.Length
+	lo, hi  vg.Length
+	breadth vg.Length
+}
+
+// byPos sorts tick mark labels by their positions.
+type byPos []thinLabel
+
+func (l byPos) Len() int           { return len(l) }
+func (l byPos) Less(i, j int) bool { return l[i].pos < l[j].pos }
+func (l byPos) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+// thin returns the tick marks with the labels of major
+// tick marks dropped as described by Tick.Thin, for labels
+// drawn on c with the given alignment. The labels are drawn
+// along a horizontal axis if horizontal is true and along
+// a vertical axis otherwise. The size of the axis and its
+// GlyphBoxes do not take thinning into account, so that
+// they do not depend on the length of the axis.
+func (a *Axis) thin(marks []Tick, c draw.Canvas, xalign, yalign float64, horizontal bool) []Tick {
+	if !a.Tick.Thin {
+		return marks
+	}
+
+	flat := a.Tick.Label
+	flat.Rotation = 0
+	// labels holds the position along the axis of
+	// each drawn label and its extent about it.
+	var labels []thinLabel
+	for i, t := range marks {
+		if t.IsMinor() {
+			continue
+		}
+		r := a.Tick.Label.AlignedRectangle(t.Label, xalign, yalign)
+		l := thinLabel{index: i, breadth: flat.Height(t.Label)}
+		if horizontal {
+			l.pos, l.lo, l.hi = c.X(a.Norm(t.Value)), r.Min.X, r.Max.X
+			if !c.ContainsX(l.pos) {
+				continue
+			}
+		} else {
+			l.pos, l.lo, l.hi = c.Y(a.Norm(t.Value)), r.Min.Y, r.Max.Y
+			if !c.ContainsY(l.pos) {
+				continue
+			}
+		}
+		labels = append(labels, l)
+	}
+	sort.Sort(byPos(labels))
+
+	// Parallel rotated labels are separated by the
+	// distance between them along the axis times
+	// the sine of their angle to the axis.
+	angle := math.Abs(math.Sin(a.Tick.Label.Rotation))
+	if !horizontal {
+		angle = math.Abs(math.Cos(a.Tick.Label.Rotation))
+	}
+	gap := a.Tick.Label.Font.Width(" ")
+	overlap := func(l0, l1 thinLabel) bool {
+		if l1.pos+l1.lo >= l0.pos+l0.hi+gap {
+			return false
+		}
+		if a.Tick.Label.Rotation == 0 {
+			return true
+		}
+		return (l1.pos-l0.pos)*vg.Length(angle) < vg.Length(math.Max(float64(l0.breadth), float64(l1.breadth)))+gap
+	}
+
+	n := 1
+	for ; n < len(labels); n++ {
+		fits := true
+		for i := n; i < len(labels); i += n {
+			if overlap(labels[i-n], labels[i]) {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			break
+		}
+	}
+	if n == 1 {
+		return marks
+	}
+	thinned := append([]Tick(nil), marks...)
+	for i, l := range labels {
+		if i%n != 0 {
+			thinned[l.index].Label = ""
+		}
+	}
+	return thinned
+}
+
 // DefaultTicks is suitable for the Tick.Marker field of an Axis,
 // it returns a resonable default set of tick marks.
 type DefaultTicks struct{}
@@ -430,6 +725,276 @@ func (LogTicks) Ticks(min, max float64) []Tick {
 	return ticks
 }
 
+// TimeScale can be used as the value of an Axis.Scale function to
+// set the axis to a time scale.  Data values on a time axis are
+// given in seconds since the Unix epoch, see UnixTime, and are
+// scaled linearly.
+type TimeScale struct{}
+
+var _ Normalizer = TimeScale{}
+
+func (TimeScale) Normalize(min, max, x float64) float64 {
+	return (x - min) / (max - min)
+}
+
+// UnixTime returns t as the number of seconds elapsed since
+// January 1, 1970 UTC, the data value used by TimeTicks and
+// TimeScale.
+func UnixTime(t time.Time) float64 {
+	return float64(t.Unix()) + float64(t.Nanosecond())/1e9
+}
+
+// timeOf returns the time.Time corresponding to the value v,
+// given in seconds since the Unix epoch.
+func timeOf(v float64) time.Time {
+	sec := math.Floor(v)
+	return time.Unix(int64(sec), int64((v-sec)*1e9))
+}
+
+// TimeTicks is suitable for the Tick.Marker field of an Axis,
+// it returns tick marks at calendar-sensible times for an axis
+// whose values are seconds since the Unix epoch.  Major ticks
+// are placed on whole milliseconds, seconds, minutes, hours,
+// days, months or years, depending on the range of the axis.
+type TimeTicks struct {
+	// Format is the time layout, as used by time.Time.Format,
+	// of the tick labels.  If Format is the empty string then
+	// a layout appropriate to the tick spacing is used.
+	Format string
+
+	// Location is the time zone in which ticks are aligned
+	// and labels are formatted.  If Location is nil then
+	// UTC is used.
+	Location *time.Location
+}
+
+var _ Ticker = TimeTicks{}
+
+// timeUnit is a calendar unit used to step between time ticks.
+type timeUnit int
+
+const (
+	millisecond timeUnit = iota
+	second
+	minute
+	hour
+	day
+	month
+	year
+)
+
+// seconds returns the approximate length of the unit in seconds.
+func (u timeUnit) seconds() float64 {
+	switch u {
+	case millisecond:
+		return 1e-3
+	case second:
+		return 1
+	case minute:
+		return 60
+	case hour:
+		return 60 * 60
+	case day:
+		return 24 * 60 * 60
+	case month:
+		return 30.436875 * 24 * 60 * 60
+	default:
+		return 365.2425 * 24 * 60 * 60
+	}
+}
+
+// A timeStep is a calendar-aligned interval between ticks.
+type timeStep struct {
+	unit timeUnit
+	n    int
+}
+
+// seconds returns the approximate length of the step in seconds.
+func (s timeStep) seconds() float64 {
+	return float64(s.n) * s.unit.seconds()
+}
+
+// floor returns the latest time not after t that is aligned
+// to the step in t's location.
+func (s timeStep) floor(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	h, mi, sec := t.Clock()
+	loc := t.Location()
+	switch s.unit {
+	case millisecond:
+		ns := s.n * int(time.Millisecond)
+		return time.Date(y, mo, d, h, mi, sec, t.Nanosecond()/ns*ns, loc)
+	case second:
+		return time.Date(y, mo, d, h, mi, sec/s.n*s.n, 0, loc)
+	case minute:
+		return time.Date(y, mo, d, h, mi/s.n*s.n, 0, 0, loc)
+	case hour:
+		return time.Date(y, mo, d, h/s.n*s.n, 0, 0, 0, loc)
+	case day:
+		return time.Date(y, mo, (d-1)/s.n*s.n+1, 0, 0, 0, 0, loc)
+	case month:
+		return time.Date(y, time.Month((int(mo)-1)/s.n*s.n+1), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(floorDiv(y, s.n)*s.n, time.January, 1, 0, 0, 0, 0, loc)
+	}
+}
+
+// next returns the first aligned time after the aligned time t.
+func (s timeStep) next(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	h, mi, sec := t.Clock()
+	loc := t.Location()
+	switch s.unit {
+	case millisecond:
+		return t.Add(time.Duration(s.n) * time.Millisecond)
+	case second:
+		t = time.Date(y, mo, d, h, mi, sec+s.n, 0, loc)
+	case minute:
+		t = time.Date(y, mo, d, h, mi+s.n, 0, 0, loc)
+	case hour:
+		t = time.Date(y, mo, d, h+s.n, 0, 0, 0, loc)
+	case day:
+		t = time.Date(y, mo, d+s.n, 0, 0, 0, 0, loc)
+	case month:
+		t = time.Date(y, mo+time.Month(s.n), 1, 0, 0, 0, 0, loc)
+	default:
+		t = time.Date(y+s.n, time.January, 1, 0, 0, 0, 0, loc)
+	}
+	return s.floor(t)
+}
+
+// format returns a default label layout for ticks separated
+// by the step.  The date is included in labels of sub-day
+// steps if the ticks span more than one day.
+func (s timeStep) format(multiday bool) string {
+	switch s.unit {
+	case millisecond:
+		return "15:04:05.000"
+	case second:
+		if multiday {
+			return "Jan 2 15:04:05"
+		}
+		return "15:04:05"
+	case minute, hour:
+		if multiday {
+			return "Jan 2 15:04"
+		}
+		return "15:04"
+	case day:
+		return "Jan 2"
+	case month:
+		return "Jan 2006"
+	default:
+		return "2006"
+	}
+}
+
+// timeSteps lists the major tick steps in increasing order,
+// each paired with the step used for its minor ticks.
+var timeSteps = []struct{ major, minor timeStep }{
+	{timeStep{millisecond, 1}, timeStep{millisecond, 1}},
+	{timeStep{millisecond, 2}, timeStep{millisecond, 1}},
+	{timeStep{millisecond, 5}, timeStep{millisecond, 1}},
+	{timeStep{millisecond, 10}, timeStep{millisecond, 5}},
+	{timeStep{millisecond, 20}, timeStep{millisecond, 10}},
+	{timeStep{millisecond, 50}, timeStep{millisecond, 10}},
+	{timeStep{millisecond, 100}, timeStep{millisecond, 50}},
+	{timeStep{millisecond, 200}, timeStep{millisecond, 100}},
+	{timeStep{millisecond, 500}, timeStep{millisecond, 100}},
+	{timeStep{second, 1}, timeStep{millisecond, 500}},
+	{timeStep{second, 2}, timeStep{second, 1}},
+	{timeStep{second, 5}, timeStep{second, 1}},
+	{timeStep{second, 10}, timeStep{second, 5}},
+	{timeStep{second, 15}, timeStep{second, 5}},
+	{timeStep{second, 30}, timeStep{second, 10}},
+	{timeStep{minute, 1}, timeStep{second, 30}},
+	{timeStep{minute, 2}, timeStep{minute, 1}},
+	{timeStep{minute, 5}, timeStep{minute, 1}},
+	{timeStep{minute, 10}, timeStep{minute, 5}},
+	{timeStep{minute, 15}, timeStep{minute, 5}},
+	{timeStep{minute, 30}, timeStep{minute, 10}},
+	{timeStep{hour, 1}, timeStep{minute, 30}},
+	{timeStep{hour, 2}, timeStep{hour, 1}},
+	{timeStep{hour, 3}, timeStep{hour, 1}},
+	{timeStep{hour, 6}, timeStep{hour, 3}},
+	{timeStep{hour, 12}, timeStep{hour, 6}},
+	{timeStep{day, 1}, timeStep{hour, 6}},
+	{timeStep{day, 2}, timeStep{day, 1}},
+	{timeStep{day, 7}, timeStep{day, 1}},
+	{timeStep{month, 1}, timeStep{day, 7}},
+	{timeStep{month, 2}, timeStep{month, 1}},
+	{timeStep{month, 3}, timeStep{month, 1}},
+	{timeStep{month, 6}, timeStep{month, 3}},
+	{timeStep{year, 1}, timeStep{month, 3}},
+	{timeStep{year, 2}, timeStep{year, 1}},
+	{timeStep{year, 5}, timeStep{year, 1}},
+}
+
+// chooseTimeStep returns the major and minor steps that give
+// at most maxTicks major ticks over the given span in seconds.
+func chooseTimeStep(span float64, maxTicks int) (major, minor timeStep) {
+	for _, s := range timeSteps {
+		if span/s.major.seconds() <= float64(maxTicks) {
+			return s.major, s.minor
+		}
+	}
+
+	// Spans of more than a few decades use 1, 2, 5 multiples
+	// of a power of ten years.
+	years := span / year.seconds() / float64(maxTicks)
+	tens := math.Pow10(int(math.Floor(math.Log10(years))))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if n := m * tens; n >= years {
+			major = timeStep{year, int(n)}
+			minor = timeStep{year, int(n / 2)}
+			if m == 5 {
+				minor.n = int(tens)
+			}
+			return major, minor
+		}
+	}
+	panic("unreachable")
+}
+
+// Ticks returns Ticks in a specified range, given in seconds
+// since the Unix epoch.
+func (t TimeTicks) Ticks(min, max float64) []Tick {
+	const maxTicks = 6
+	if max < min {
+		panic("illegal range")
+	}
+	loc := t.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	major, minor := chooseTimeStep(max-min, maxTicks)
+	tmin, tmax := timeOf(min).In(loc), timeOf(max).In(loc)
+	format := t.Format
+	if format == "" {
+		y0, m0, d0 := tmin.Date()
+		y1, m1, d1 := tmax.Date()
+		format = major.format(y0 != y1 || m0 != m1 || d0 != d1)
+	}
+
+	var ticks []Tick
+	for tt := major.floor(tmin); !tt.After(tmax); tt = major.next(tt) {
+		if tt.Before(tmin) {
+			continue
+		}
+		ticks = append(ticks, Tick{Value: UnixTime(tt), Label: tt.Format(format)})
+	}
+	if minor == major {
+		return ticks
+	}
+	for tt := minor.floor(tmin); !tt.After(tmax); tt = minor.next(tt) {
+		if tt.Before(tmin) || major.floor(tt).Equal(tt) {
+			continue
+		}
+		ticks = append(ticks, Tick{Value: UnixTime(tt)})
+	}
+	return ticks
+}
+
 // ConstantTicks is suitable for the Tick.Marker field of an Axis.
 // This function returns the given set of ticks.
 type ConstantTicks []Tick
@@ -516,6 +1081,15 @@ func precisionOf(x float64) int {
 	return int(math.Max(math.Ceil(-math.Log10(math.Abs(x))), displayPrecision))
 }
 
+// floorDiv returns a/b rounded towards negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
 func maxInt(a, b int) int {
 	if a > b {
 		return a
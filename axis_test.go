@@ -6,7 +6,14 @@ package plot
 
 import (
 	"math"
+	"reflect"
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/recorder"
 )
 
 func TestAxisSmallTick(t *testing.T) {
@@ -51,3 +58,141 @@ func TestAxisSmallTick(t *testing.T) {
 		}
 	}
 }
+
+func TestTimeTicks(t *testing.T) {
+	for _, test := range []struct {
+		Min, Max time.Time
+		Format   string
+		Location *time.Location
+		Labels   []string
+	}{
+		{
+			Min:    time.Date(2015, time.March, 1, 10, 0, 0, 0, time.UTC),
+			Max:    time.Date(2015, time.March, 1, 10, 0, 20, 0, time.UTC),
+			Labels: []string{"10:00:00", "10:00:05", "10:00:10", "10:00:15", "10:00:20"},
+		},
+		{
+			Min:    time.Date(2015, time.March, 1, 9, 50, 0, 0, time.UTC),
+			Max:    time.Date(2015, time.March, 1, 11, 10, 0, 0, time.UTC),
+			Labels: []string{"10:00", "10:15", "10:30", "10:45", "11:00"},
+		},
+		{
+			Min:    time.Date(2015, time.January, 30, 0, 0, 0, 0, time.UTC),
+			Max:    time.Date(2015, time.March, 5, 0, 0, 0, 0, time.UTC),
+			Labels: []string{"Feb 1", "Feb 8", "Feb 15", "Feb 22", "Mar 1"},
+		},
+		{
+			Min:    time.Date(2014, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Max:    time.Date(2015, time.September, 10, 0, 0, 0, 0, time.UTC),
+			Labels: []string{"Jan 2015", "Mar 2015", "May 2015", "Jul 2015", "Sep 2015"},
+		},
+		{
+			Min:    time.Date(1987, time.June, 1, 0, 0, 0, 0, time.UTC),
+			Max:    time.Date(2015, time.June, 1, 0, 0, 0, 0, time.UTC),
+			Labels: []string{"1990", "1995", "2000", "2005", "2010", "2015"},
+		},
+		{
+			Min:      time.Date(2015, time.March, 1, 0, 0, 0, 0, time.UTC),
+			Max:      time.Date(2015, time.March, 2, 0, 0, 0, 0, time.UTC),
+			Format:   "15h",
+			Location: time.FixedZone("UTC+3", 3*60*60),
+			Labels:   []string{"06h", "12h", "18h", "00h"},
+		},
+	} {
+		ticks := TimeTicks{Format: test.Format, Location: test.Location}.Ticks(UnixTime(test.Min), UnixTime(test.Max))
+		var labels []string
+		for _, tick := range ticks {
+			if tick.IsMinor() {
+				continue
+			}
+			labels = append(labels, tick.Label)
+		}
+		if !reflect.DeepEqual(labels, test.Labels) {
+			t.Errorf("unexpected tick labels for %v to %v: got:%q want:%q", test.Min, test.Max, labels, test.Labels)
+		}
+	}
+}
+
+func TestRotatedTickLabels(t *testing.T) {
+	p, err := New()
+	if err != nil {
+		t.Fatalf("failed to create plot: %v", err)
+	}
+	p.NominalX("first category", "second category", "third category")
+	p.X.Min, p.X.Max = -0.5, 2.5
+
+	x := horizontalAxis{p.X}
+	w := x.Tick.Label.Width("second category")
+	h := x.Tick.Label.Height("second category")
+	plain := x.size()
+
+	p.X.Tick.Label.Rotation = math.Pi / 4
+	x = horizontalAxis{p.X}
+	want := plain - h + (w+h)*vg.Length(math.Sqrt2/2)
+	if got := x.size(); math.Abs(float64(got-want)) > 1e-9 {
+		t.Errorf("unexpected rotated axis height: got:%v want:%v", got, want)
+	}
+
+	// Labels rotated counter-clockwise end at their tick mark,
+	// so they extend to the left.
+	for _, b := range x.GlyphBoxes(p) {
+		if b.Max.X > h || b.Min.X >= -w/2 {
+			t.Errorf("unexpected glyph box for rotated label: got:%+v", b.Rectangle)
+		}
+	}
+	p.X.Tick.Label.Rotation = -math.Pi / 4
+	x = horizontalAxis{p.X}
+	for _, b := range x.GlyphBoxes(p) {
+		if b.Min.X < -h || b.Max.X <= w/2 {
+			t.Errorf("unexpected glyph box for clockwise rotated label: got:%+v", b.Rectangle)
+		}
+	}
+}
+
+func TestThinTicks(t *testing.T) {
+	p, err := New()
+	if err != nil {
+		t.Fatalf("failed to create plot: %v", err)
+	}
+	var ticks []Tick
+	for i := 0; i <= 10; i++ {
+		ticks = append(ticks, Tick{Value: float64(i), Label: strconv.Itoa(100000 + i)})
+	}
+	p.X.Min, p.X.Max = 0, 10
+	p.X.Tick.Marker = ConstantTicks(ticks)
+	x := horizontalAxis{p.X}
+	c := draw.NewCanvas(new(recorder.Canvas), 200, 100)
+	xalign, yalign := horizontalTickAlign(x.Tick.Label, false)
+
+	if got := x.thin(ticks, c, xalign, yalign, true); !reflect.DeepEqual(got, ticks) {
+		t.Errorf("ticks thinned when Thin is false")
+	}
+
+	x.Tick.Thin = true
+	w := x.Tick.Label.Width("100000")
+	for _, test := range []struct {
+		rotation float64
+		want     int
+	}{
+		// Labels are 20 apart and a little over
+		// 30 wide, so every second label is kept.
+		{rotation: 0, want: 2},
+		// Vertical labels are narrower than 20.
+		{rotation: math.Pi / 2, want: 1},
+	} {
+		x.Tick.Label.Rotation = test.rotation
+		xalign, yalign = horizontalTickAlign(x.Tick.Label, false)
+		got := x.thin(ticks, c, xalign, yalign, true)
+		for i, tk := range got {
+			if kept := !tk.IsMinor(); kept != (i%test.want == 0) {
+				t.Errorf("unexpected thinning of tick %d for rotation %v: got kept:%t (label width %v)", i, test.rotation, kept, w)
+			}
+			if tk.Value != ticks[i].Value {
+				t.Errorf("unexpected tick value: got:%v want:%v", tk.Value, ticks[i].Value)
+			}
+		}
+	}
+	if ticks[1].IsMinor() {
+		t.Error("thinning modified the ticks of the marker")
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Plot renders a plot of CSV or TSV data.
+//
+// Usage:
+//
+//	plot [flags] [file]
+//
+// The data is read from file, or from standard input if file is
+// absent or "-". The first row of the data holds the column names
+// unless -header=false is given. Columns are selected by name or by
+// 1-based index. Several Y columns may be given separated by commas,
+// each producing one series; the -err and -label columns, when given,
+// pair with the Y columns in order.
+//
+// The output format is taken from the -format flag or, failing that,
+// from the extension of the -o file. Supported formats are those of
+// draw.NewFormattedCanvas: eps, jpg, pdf, png, svg and tiff.
+//
+// For example, to draw two series of a TSV file as lines on a
+// logarithmic Y axis:
+//
+//	plot -x time -y load,queue -yscale log -o load.svg data.tsv
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/plotter"
+	"github.com/gonum/plot/plotutil"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if err == flag.ErrHelp {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "plot: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// options holds the parsed command line options.
+type options struct {
+	delim  string
+	header bool
+
+	x, y, err, label string
+
+	kind           string
+	bins           int
+	xscale, yscale string
+
+	title, xlabel, ylabel string
+
+	width, height string
+	out, format   string
+}
+
+// run parses the command line arguments and renders the plot.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var o options
+	fs := flag.NewFlagSet("plot", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&o.delim, "d", "", `field delimiter; default "\t" for .tsv files and "," otherwise`)
+	fs.BoolVar(&o.header, "header", true, "first row holds the column names")
+	fs.StringVar(&o.x, "x", "", "X column; default is the row number")
+	fs.StringVar(&o.y, "y", "", "comma separated Y columns; default is the first column that is not X")
+	fs.StringVar(&o.err, "err", "", "comma separated symmetric Y error columns")
+	fs.StringVar(&o.label, "label", "", "comma separated point label columns")
+	fs.StringVar(&o.kind, "kind", "line", "plot kind: line, scatter, linepoints, bar, hist or box")
+	fs.IntVar(&o.bins, "bins", 16, "number of histogram bins")
+	fs.StringVar(&o.xscale, "xscale", "linear", "X axis scale: linear or log")
+	fs.StringVar(&o.yscale, "yscale", "linear", "Y axis scale: linear or log")
+	fs.StringVar(&o.title, "title", "", "plot title")
+	fs.StringVar(&o.xlabel, "xlabel", "", "X axis label; default is the X column name")
+	fs.StringVar(&o.ylabel, "ylabel", "", "Y axis label")
+	fs.StringVar(&o.width, "width", "4in", "output width, with unit in, cm, mm or pt")
+	fs.StringVar(&o.height, "height", "4in", "output height, with unit in, cm, mm or pt")
+	fs.StringVar(&o.out, "o", "plot.png", `output file, or "-" for standard output`)
+	fs.StringVar(&o.format, "format", "", "output format; default is the output file extension")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	var (
+		in   = stdin
+		name = "-"
+	)
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		name = fs.Arg(0)
+	default:
+		return errors.New("too many input files")
+	}
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	delim := o.delim
+	if delim == "" {
+		delim = ","
+		if strings.EqualFold(filepath.Ext(name), ".tsv") {
+			delim = "\t"
+		}
+	}
+	t, err := readTable(in, delim, o.header)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+
+	p, err := o.plot(t)
+	if err != nil {
+		return err
+	}
+
+	w, err := parseLength(o.width)
+	if err != nil {
+		return fmt.Errorf("-width: %v", err)
+	}
+	h, err := parseLength(o.height)
+	if err != nil {
+		return fmt.Errorf("-height: %v", err)
+	}
+	format := o.format
+	if format == "" {
+		if o.out == "-" {
+			return errors.New("-format is required when writing to standard output")
+		}
+		format = strings.TrimPrefix(filepath.Ext(o.out), ".")
+	}
+	c, err := draw.NewFormattedCanvas(w, h, strings.ToLower(format))
+	if err != nil {
+		return err
+	}
+	p.Draw(draw.New(c))
+
+	if o.out == "-" {
+		_, err = c.WriteTo(stdout)
+		return err
+	}
+	f, err := os.Create(o.out)
+	if err != nil {
+		return err
+	}
+	_, err = c.WriteTo(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// plot returns the plot of the table described by the options.
+func (o *options) plot(t *table) (*plot.Plot, error) {
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	p.Title.Text = o.title
+
+	xcol := -1
+	if o.x != "" {
+		xcol, err = t.column(o.x)
+		if err != nil {
+			return nil, fmt.Errorf("-x: %v", err)
+		}
+	}
+	var ycols []int
+	if o.y == "" {
+		for i := range t.header {
+			if i != xcol {
+				ycols = append(ycols, i)
+				break
+			}
+		}
+		if ycols == nil {
+			return nil, errors.New("-y: no column available")
+		}
+	} else {
+		ycols, err = t.columns(o.y)
+		if err != nil {
+			return nil, fmt.Errorf("-y: %v", err)
+		}
+	}
+	errcols, err := t.columns(o.err)
+	if err != nil {
+		return nil, fmt.Errorf("-err: %v", err)
+	}
+	if errcols != nil && len(errcols) != len(ycols) {
+		return nil, fmt.Errorf("-err: %d columns given for %d Y columns", len(errcols), len(ycols))
+	}
+	labelcols, err := t.columns(o.label)
+	if err != nil {
+		return nil, fmt.Errorf("-label: %v", err)
+	}
+	if labelcols != nil && len(labelcols) != len(ycols) {
+		return nil, fmt.Errorf("-label: %d columns given for %d Y columns", len(labelcols), len(ycols))
+	}
+
+	switch o.kind {
+	case "line", "scatter", "linepoints":
+		var xs []float64
+		if xcol < 0 {
+			xs = make([]float64, len(t.rows))
+			for i := range xs {
+				xs[i] = float64(i)
+			}
+		} else {
+			xs, err = t.floats(xcol)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for i, yc := range ycols {
+			ys, err := t.floats(yc)
+			if err != nil {
+				return nil, err
+			}
+			xys := make(plotter.XYs, len(xs))
+			for j := range xys {
+				xys[j].X, xys[j].Y = xs[j], ys[j]
+			}
+			var thumbs []plot.Thumbnailer
+			if o.kind != "scatter" {
+				l, err := plotter.NewLine(xys)
+				if err != nil {
+					return nil, err
+				}
+				l.Color = plotutil.Color(i)
+				l.Dashes = plotutil.Dashes(i)
+				p.Add(l)
+				thumbs = append(thumbs, l)
+			}
+			if o.kind != "line" {
+				s, err := plotter.NewScatter(xys)
+				if err != nil {
+					return nil, err
+				}
+				s.Color = plotutil.Color(i)
+				s.Shape = plotutil.Shape(i)
+				p.Add(s)
+				thumbs = append(thumbs, s)
+			}
+			if errcols != nil {
+				es, err := t.floats(errcols[i])
+				if err != nil {
+					return nil, err
+				}
+				yerrs := make(plotter.YErrors, len(es))
+				for j, e := range es {
+					yerrs[j].Low, yerrs[j].High = e, e
+				}
+				eb, err := plotter.NewYErrorBars(struct {
+					plotter.XYs
+					plotter.YErrors
+				}{xys, yerrs})
+				if err != nil {
+					return nil, err
+				}
+				eb.Color = plotutil.Color(i)
+				p.Add(eb)
+			}
+			if labelcols != nil {
+				l, err := plotter.NewLabels(plotter.XYLabels{XYs: xys, Labels: t.strings(labelcols[i])})
+				if err != nil {
+					return nil, err
+				}
+				p.Add(l)
+			}
+			if len(ycols) > 1 {
+				p.Legend.Add(t.header[yc], thumbs...)
+			}
+		}
+
+	case "bar":
+		if errcols != nil || labelcols != nil {
+			return nil, errors.New("-err and -label are not supported for bar plots")
+		}
+		const width = 10
+		for i, yc := range ycols {
+			ys, err := t.floats(yc)
+			if err != nil {
+				return nil, err
+			}
+			b, err := plotter.NewBarChart(plotter.Values(ys), vg.Points(width))
+			if err != nil {
+				return nil, err
+			}
+			b.Color = plotutil.Color(i)
+			b.Offset = vg.Points(width * (float64(i) - float64(len(ycols)-1)/2))
+			p.Add(b)
+			if len(ycols) > 1 {
+				p.Legend.Add(t.header[yc], b)
+			}
+		}
+		if xcol >= 0 {
+			p.NominalX(t.strings(xcol)...)
+		}
+
+	case "hist":
+		if xcol >= 0 || errcols != nil || labelcols != nil {
+			return nil, errors.New("-x, -err and -label are not supported for histograms")
+		}
+		for i, yc := range ycols {
+			ys, err := t.floats(yc)
+			if err != nil {
+				return nil, err
+			}
+			h, err := plotter.NewHist(plotter.Values(ys), o.bins)
+			if err != nil {
+				return nil, err
+			}
+			h.FillColor = plotutil.Color(i)
+			p.Add(h)
+			if len(ycols) > 1 {
+				p.Legend.Add(t.header[yc], h)
+			}
+		}
+
+	case "box":
+		if xcol >= 0 || errcols != nil || labelcols != nil {
+			return nil, errors.New("-x, -err and -label are not supported for box plots")
+		}
+		names := make([]string, len(ycols))
+		for i, yc := range ycols {
+			ys, err := t.floats(yc)
+			if err != nil {
+				return nil, err
+			}
+			b, err := plotter.NewBoxPlot(vg.Points(20), float64(i), plotter.Values(ys))
+			if err != nil {
+				return nil, err
+			}
+			p.Add(b)
+			names[i] = t.header[yc]
+		}
+		p.NominalX(names...)
+
+	default:
+		return nil, fmt.Errorf("-kind: unknown plot kind %q", o.kind)
+	}
+
+	if xcol >= 0 && o.kind != "bar" {
+		p.X.Label.Text = t.header[xcol]
+	}
+	if o.xlabel != "" {
+		p.X.Label.Text = o.xlabel
+	}
+	p.Y.Label.Text = o.ylabel
+	if o.ylabel == "" && len(ycols) == 1 && o.kind != "hist" {
+		p.Y.Label.Text = t.header[ycols[0]]
+	}
+
+	err = setScale(&p.X, o.xscale)
+	if err != nil {
+		return nil, fmt.Errorf("-xscale: %v", err)
+	}
+	err = setScale(&p.Y, o.yscale)
+	if err != nil {
+		return nil, fmt.Errorf("-yscale: %v", err)
+	}
+	return p, nil
+}
+
+// setScale sets the named scale and matching ticker on a.
+func setScale(a *plot.Axis, scale string) error {
+	switch scale {
+	case "linear":
+	case "log":
+		if a.Min <= 0 {
+			return fmt.Errorf("non-positive data %v on log scale", a.Min)
+		}
+		a.Scale = plot.LogScale{}
+		a.Tick.Marker = plot.LogTicks{}
+	default:
+		return fmt.Errorf("unknown scale %q", scale)
+	}
+	return nil
+}
+
+// parseLength parses a length with a unit suffix of
+// in, cm, mm or pt.
+func parseLength(s string) (vg.Length, error) {
+	units := []struct {
+		suffix string
+		unit   vg.Length
+	}{
+		{"in", vg.Inch},
+		{"cm", vg.Centimeter},
+		{"mm", vg.Millimeter},
+		{"pt", 1},
+	}
+	for _, u := range units {
+		if !strings.HasSuffix(s, u.suffix) {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), 64)
+		if err != nil || v <= 0 {
+			return 0, fmt.Errorf("invalid length %q", s)
+		}
+		return vg.Length(v) * u.unit, nil
+	}
+	return 0, fmt.Errorf("missing unit in length %q", s)
+}
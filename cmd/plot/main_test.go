@@ -0,0 +1,110 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/gonum/plot/vg"
+)
+
+const testData = `# Load test results.
+time,load,queue,err,note
+1,0.5,3,0.1,a
+2,0.7,5,0.2,b
+3,0.6,8,0.1,c
+4,0.9,13,0.3,d
+`
+
+var runTests = []struct {
+	args []string
+	want string // want is a prefix of the output or error text.
+	err  bool
+}{
+	{args: []string{"-format", "svg"}, want: "<?xml"},
+	{args: []string{"-format", "eps", "-x", "time", "-y", "load,queue", "-err", "err,err", "-label", "note,note"}, want: "%%!PS-Adobe"},
+	{args: []string{"-format", "svg", "-kind", "linepoints", "-x", "1", "-y", "3", "-yscale", "log"}, want: "<?xml"},
+	{args: []string{"-format", "svg", "-kind", "bar", "-x", "note", "-y", "load,queue"}, want: "<?xml"},
+	{args: []string{"-format", "svg", "-kind", "hist", "-y", "queue", "-bins", "3"}, want: "<?xml"},
+	{args: []string{"-format", "png", "-kind", "box", "-y", "load,queue", "-width", "10cm", "-height", "300pt"}, want: "\x89PNG"},
+
+	{args: []string{"-format", "svg", "-y", "missing"}, want: `-y: no column "missing"`, err: true},
+	{args: []string{"-format", "svg", "-y", "note"}, want: `row 1: column "note": invalid number "a"`, err: true},
+	{args: []string{"-format", "svg", "-y", "load,queue", "-err", "err"}, want: "-err: 1 columns given for 2 Y columns", err: true},
+	{args: []string{"-format", "svg", "-kind", "pie"}, want: `-kind: unknown plot kind "pie"`, err: true},
+	{args: []string{"-format", "svg", "-yscale", "log", "-y", "err", "-x", "time", "-xscale", "sqrt"}, want: `-xscale: unknown scale "sqrt"`, err: true},
+	{args: []string{"-format", "gif"}, want: `unsupported format: "gif"`, err: true},
+	{args: []string{"-width", "4"}, want: `-width: missing unit in length "4"`, err: true},
+	{args: nil, want: "-format is required", err: true},
+}
+
+func TestRun(t *testing.T) {
+	for i, test := range runTests {
+		var out bytes.Buffer
+		args := append([]string{"-o", "-"}, test.args...)
+		err := run(args, strings.NewReader(testData), &out, ioutil.Discard)
+		if test.err {
+			if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+				t.Errorf("unexpected error for test %d: got:%v want:%s", i, err, test.want)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		if !strings.HasPrefix(out.String(), test.want) {
+			t.Errorf("unexpected output for test %d: got:%.20q want prefix:%q", i, out.String(), test.want)
+		}
+	}
+}
+
+func TestHelp(t *testing.T) {
+	for _, arg := range []string{"-h", "-help"} {
+		var stderr bytes.Buffer
+		err := run([]string{arg}, strings.NewReader(testData), ioutil.Discard, &stderr)
+		if err != flag.ErrHelp {
+			t.Errorf("unexpected error for %s: got:%v want:%v", arg, err, flag.ErrHelp)
+		}
+		if !strings.Contains(stderr.String(), "-height") {
+			t.Errorf("usage for %s does not mention -height: %q", arg, stderr.String())
+		}
+	}
+}
+
+func TestReadTable(t *testing.T) {
+	tab, err := readTable(strings.NewReader("1\t2\n3\t4\n"), "\t", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tab.header) != 2 || tab.header[1] != "2" || len(tab.rows) != 2 {
+		t.Errorf("unexpected table: got:%+v", tab)
+	}
+	c, err := tab.column("2")
+	if err != nil || c != 1 {
+		t.Errorf("unexpected column: got:%d err:%v want:1", c, err)
+	}
+}
+
+func TestParseLength(t *testing.T) {
+	for _, test := range []struct {
+		s    string
+		want vg.Length
+	}{
+		{"4in", 4 * vg.Inch},
+		{"10cm", 10 * vg.Centimeter},
+		{"25 mm", 25 * vg.Millimeter},
+		{"300pt", 300},
+	} {
+		got, err := parseLength(test.s)
+		if err != nil || got != test.want {
+			t.Errorf("unexpected length for %q: got:%v err:%v want:%v", test.s, got, err, test.want)
+		}
+	}
+}
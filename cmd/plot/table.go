@@ -0,0 +1,110 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// table is a table of delimited text data.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// readTable reads the delimited data in r. If header is false
+// the columns are named by their 1-based index.
+func readTable(r io.Reader, delim string, header bool) (*table, error) {
+	comma, n := utf8.DecodeRuneInString(delim)
+	if n == 0 || n != len(delim) {
+		return nil, fmt.Errorf("invalid delimiter %q", delim)
+	}
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = comma != ' ' && comma != '\t'
+	recs, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, errors.New("no data")
+	}
+
+	var t table
+	if header {
+		t.header, recs = recs[0], recs[1:]
+	} else {
+		t.header = make([]string, len(recs[0]))
+		for i := range t.header {
+			t.header[i] = strconv.Itoa(i + 1)
+		}
+	}
+	if len(recs) == 0 {
+		return nil, errors.New("no data rows")
+	}
+	t.rows = recs
+	return &t, nil
+}
+
+// column returns the index of the column with the given
+// name or 1-based index.
+func (t *table) column(name string) (int, error) {
+	for i, h := range t.header {
+		if h == name {
+			return i, nil
+		}
+	}
+	i, err := strconv.Atoi(name)
+	if err != nil || i < 1 || i > len(t.header) {
+		return 0, fmt.Errorf("no column %q", name)
+	}
+	return i - 1, nil
+}
+
+// columns returns the indices of the comma separated
+// list of columns. It returns nil for an empty list.
+func (t *table) columns(list string) ([]int, error) {
+	if list == "" {
+		return nil, nil
+	}
+	var cols []int
+	for _, name := range strings.Split(list, ",") {
+		c, err := t.column(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, c)
+	}
+	return cols, nil
+}
+
+// floats returns the values of the column c.
+func (t *table) floats(c int) ([]float64, error) {
+	vs := make([]float64, len(t.rows))
+	for i, row := range t.rows {
+		v, err := strconv.ParseFloat(strings.TrimSpace(row[c]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: column %q: invalid number %q", i+1, t.header[c], row[c])
+		}
+		vs[i] = v
+	}
+	return vs, nil
+}
+
+// strings returns the text of the column c.
+func (t *table) strings(c int) []string {
+	ss := make([]string, len(t.rows))
+	for i, row := range t.rows {
+		ss[i] = row[c]
+	}
+	return ss
+}
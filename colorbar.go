@@ -0,0 +1,361 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plot
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/gonum/plot/palette"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// ColorBar shows the mapping of values to the colors of a
+// palette, as used by heat maps and contour plots. A color
+// bar is drawn beside the data area of a plot when it is the
+// ColorBar of the plot.
+type ColorBar struct {
+	// Palette is the palette of the colors shown
+	// by the bar.
+	Palette palette.Palette
+
+	// Min and Max are the range of values that is
+	// spread uniformly across the palette.
+	Min, Max float64
+
+	// Underflow and Overflow are the colors of values
+	// below Min and above Max. If they are not nil,
+	// they are shown as triangles at the ends of a
+	// continuous bar.
+	Underflow color.Color
+	Overflow  color.Color
+
+	// Levels, if not empty, are the levels of a contour
+	// plot. The bar is then drawn as equal bands, one for
+	// each level in increasing order, in the color of the
+	// level. As for contour plots, the palette is spread
+	// across the range of the levels and levels below Min
+	// or above Max have the Underflow and Overflow colors.
+	Levels []float64
+
+	// Filled specifies whether a bar with Levels shows
+	// the bands between consecutive levels of a filled
+	// contour plot rather than the levels themselves.
+	// The bar then has a band for each pair of levels,
+	// in the color of the value midway between them,
+	// with tick marks at the levels.
+	Filled bool
+
+	// Horizontal specifies whether the bar is drawn
+	// horizontally below the plot. Otherwise the bar
+	// is drawn vertically to the right of the plot.
+	Horizontal bool
+
+	// Width is the width of the bar across its length.
+	Width vg.Length
+
+	// Padding is the space between the bar and the
+	// rest of the plot.
+	Padding vg.Length
+
+	// Axis is the axis drawn along the bar, with the
+	// tick marks and label of the bar. The range of
+	// Axis is set from the bar when it is drawn, and
+	// its tick marks are the levels of a bar with
+	// Levels.
+	Axis Axis
+}
+
+// NewColorBar returns a new color bar showing
+// the palette spread across the range from min
+// to max.
+func NewColorBar(pal palette.Palette, min, max float64) (*ColorBar, error) {
+	a, err := makeAxis()
+	if err != nil {
+		return nil, err
+	}
+	a.Padding = 0
+	return &ColorBar{
+		Palette: pal,
+		Min:     min,
+		Max:     max,
+		Width:   vg.Points(10),
+		Padding: vg.Points(10),
+		Axis:    a,
+	}, nil
+}
+
+// axis returns the axis of the bar, with its range
+// and, for a bar with levels, its tick marks set.
+func (b *ColorBar) axis() Axis {
+	a := b.Axis
+	a.Scale = LinearScale{}
+	if len(b.Levels) == 0 {
+		a.Min, a.Max = b.Min, b.Max
+		a.sanitizeRange()
+		return a
+	}
+	levels := b.levels()
+	off := 0.5
+	if b.Filled {
+		off = 0
+	}
+	// The ticks are placed by band index, so the
+	// levels are formatted here rather than by the
+	// Formatter of the axis.
+	ticks := make(ConstantTicks, len(levels))
+	for i, z := range levels {
+		label := strconv.FormatFloat(z, 'g', displayPrecision, 64)
+		if a.Tick.Formatter != nil {
+			label = a.Tick.Formatter.Format(z)
+		}
+		ticks[i] = Tick{Value: float64(i) + off, Label: label}
+	}
+	a.Min, a.Max = 0, float64(b.bands(levels))
+	a.Tick.Marker = ticks
+	a.Tick.Formatter = nil
+	return a
+}
+
+// levels returns the levels of the bar in increasing
+// order, omitting NaN levels.
+func (b *ColorBar) levels() []float64 {
+	var l []float64
+	for _, z := range b.Levels {
+		if !math.IsNaN(z) {
+			l = append(l, z)
+		}
+	}
+	sort.Float64s(l)
+	return l
+}
+
+// bands returns the number of bands of a bar with
+// the given sorted levels.
+func (b *ColorBar) bands(levels []float64) int {
+	if b.Filled && len(levels) > 0 {
+		return len(levels) - 1
+	}
+	return len(levels)
+}
+
+// size returns the space taken by the bar and its
+// axis across the length of the bar.
+func (b *ColorBar) size() vg.Length {
+	a := b.axis()
+	if b.Horizontal {
+		return b.Padding + b.Width + (&horizontalAxis{a}).size()
+	}
+	return b.Padding + b.Width + (&verticalAxis{a}).size()
+}
+
+// reserve returns the canvas c less the space taken
+// by the bar, and the space taken by the bar. If b is
+// nil, c is returned unchanged.
+func (b *ColorBar) reserve(c draw.Canvas) (rest, bar draw.Canvas) {
+	if b == nil {
+		return c, c
+	}
+	s := b.size()
+	if b.Horizontal {
+		return draw.Crop(c, 0, 0, s, 0), draw.Crop(c, 0, 0, 0, s-c.Size().Y)
+	}
+	return draw.Crop(c, 0, -s, 0, 0), draw.Crop(c, c.Size().X-s, 0, 0, 0)
+}
+
+// draw draws the bar in the space reserved for it,
+// alongside the data area of the plot, dataC. If b
+// is nil, nothing is drawn.
+func (b *ColorBar) draw(reserved, dataC draw.Canvas) {
+	if b == nil {
+		return
+	}
+	pal := b.Palette.Colors()
+	if len(pal) == 0 {
+		panic("colorbar: empty palette")
+	}
+
+	// min and max are the ends of the bar along its
+	// length, and lo and hi are its sides across it.
+	var min, max, lo, hi vg.Length
+	if b.Horizontal {
+		min, max = dataC.Min.X, dataC.Max.X
+		hi = reserved.Max.Y - b.Padding
+		lo = hi - b.Width
+	} else {
+		min, max = dataC.Min.Y, dataC.Max.Y
+		lo = reserved.Min.X + b.Padding
+		hi = lo + b.Width
+	}
+	continuous := len(b.Levels) == 0
+	tri := b.Width
+	if continuous && b.Underflow != nil {
+		min += tri
+	}
+	if continuous && b.Overflow != nil {
+		max -= tri
+	}
+	if min >= max {
+		return
+	}
+
+	// rect returns the rectangle of the bar from
+	// s to e along its length.
+	rect := func(s, e vg.Length) draw.Rectangle {
+		if b.Horizontal {
+			return draw.Rectangle{Min: draw.Point{X: s, Y: lo}, Max: draw.Point{X: e, Y: hi}}
+		}
+		return draw.Rectangle{Min: draw.Point{X: lo, Y: s}, Max: draw.Point{X: hi, Y: e}}
+	}
+	// pt returns the point at t along the bar
+	// and u across it.
+	pt := func(t, u vg.Length) draw.Point {
+		if b.Horizontal {
+			return draw.Point{X: t, Y: u}
+		}
+		return draw.Point{X: u, Y: t}
+	}
+
+	var outline []draw.Point
+	if continuous {
+		b.drawGradient(reserved, rect(min, max), pal)
+		outline = []draw.Point{pt(min, lo)}
+		if b.Underflow != nil {
+			tip := []draw.Point{pt(min, lo), pt(min-tri, (lo+hi)/2), pt(min, hi)}
+			reserved.FillPolygon(b.Underflow, tip)
+			outline = tip
+		}
+		outline = append(outline, pt(min, hi), pt(max, hi))
+		if b.Overflow != nil {
+			tip := []draw.Point{pt(max, hi), pt(max+tri, (lo+hi)/2), pt(max, lo)}
+			reserved.FillPolygon(b.Overflow, tip)
+			outline = append(outline, tip[1:]...)
+		}
+		outline = append(outline, pt(max, lo), outline[0])
+	} else {
+		levels := b.levels()
+		n := b.bands(levels)
+		if n == 0 {
+			return
+		}
+		band := (max - min) / vg.Length(n)
+		for i := 0; i < n; i++ {
+			var col color.Color
+			if b.Filled {
+				col = b.bandColor(i, levels, pal)
+			} else {
+				col = b.levelColor(levels[i], levels, pal)
+			}
+			if col != nil {
+				r := rect(min+vg.Length(i)*band, min+vg.Length(i+1)*band)
+				reserved.FillPolygon(col, []draw.Point{r.Min, {X: r.Min.X, Y: r.Max.Y}, r.Max, {X: r.Max.X, Y: r.Min.Y}})
+			}
+		}
+		r := rect(min, max)
+		outline = []draw.Point{r.Min, {X: r.Min.X, Y: r.Max.Y}, r.Max, {X: r.Max.X, Y: r.Min.Y}, r.Min}
+	}
+	reserved.StrokeLines(b.Axis.LineStyle, outline)
+
+	a := b.axis()
+	if b.Horizontal {
+		x := horizontalAxis{a}
+		s := x.size()
+		x.draw(draw.Canvas{
+			Canvas:    reserved.Canvas,
+			Rectangle: draw.Rectangle{Min: draw.Point{X: min, Y: lo - s}, Max: draw.Point{X: max, Y: lo}},
+		})
+		return
+	}
+	y := rightAxis{verticalAxis{a}}
+	s := y.size()
+	y.draw(draw.Canvas{
+		Canvas:    reserved.Canvas,
+		Rectangle: draw.Rectangle{Min: draw.Point{X: hi, Y: min}, Max: draw.Point{X: hi + s, Y: max}},
+	})
+}
+
+// drawGradient draws the palette colors spread across
+// the rectangle r of c, from the bottom to the top of a
+// vertical bar or from the left to the right of a
+// horizontal bar. The colors at the ends take half of
+// the space of the others, so that each color is drawn
+// at the values that are drawn in it by a heat map.
+func (b *ColorBar) drawGradient(c draw.Canvas, r draw.Rectangle, pal []color.Color) {
+	n := 2 * (len(pal) - 1)
+	if n == 0 {
+		n = 1
+	}
+	var img *image.NRGBA
+	if b.Horizontal {
+		img = image.NewNRGBA(image.Rect(0, 0, n, 1))
+	} else {
+		img = image.NewNRGBA(image.Rect(0, 0, 1, n))
+	}
+	for j := 0; j < n; j++ {
+		col := pal[(j+1)/2]
+		if b.Horizontal {
+			img.Set(j, 0, col)
+		} else {
+			img.Set(0, n-1-j, col)
+		}
+	}
+	size := r.Size()
+	c.DrawImage(r.Min.X, r.Min.Y, size.X, size.Y, img)
+}
+
+// levelColor returns the color of the level z of the sorted
+// levels, as drawn by a contour plot, or nil if the level is
+// not drawn.
+func (b *ColorBar) levelColor(z float64, levels []float64, pal []color.Color) color.Color {
+	switch {
+	case z < b.Min:
+		return b.Underflow
+	case z > b.Max:
+		return b.Overflow
+	}
+	if len(levels) == 1 {
+		return pal[0]
+	}
+	ps := float64(len(pal)-1) / (levels[len(levels)-1] - levels[0])
+	return pal[int((z-levels[0])*ps+0.5)]
+}
+
+// BandColor returns the color of the kth band between
+// consecutive levels of the bar in increasing order, as
+// drawn by a filled contour plot, or nil if the band is
+// not drawn. The palette is spread uniformly across the
+// values midway between the levels, and bands midway
+// below Min or above Max have the Underflow and Overflow
+// colors.
+func (b *ColorBar) BandColor(k int) color.Color {
+	return b.bandColor(k, b.levels(), b.Palette.Colors())
+}
+
+// bandColor returns the color of the band between levels[k]
+// and levels[k+1] of the sorted levels, as drawn by a filled
+// contour plot, or nil if the band is not drawn.
+func (b *ColorBar) bandColor(k int, levels []float64, pal []color.Color) color.Color {
+	z := (levels[k] + levels[k+1]) / 2
+	switch {
+	case z < b.Min:
+		return b.Underflow
+	case z > b.Max:
+		return b.Overflow
+	}
+	n := len(levels) - 1
+	if n == 1 {
+		return pal[0]
+	}
+	// ps is a palette scaling factor to scale the palette
+	// uniformly across the midway values of the bands.
+	lo := (levels[0] + levels[1]) / 2
+	hi := (levels[n-1] + levels[n]) / 2
+	ps := float64(len(pal)-1) / (hi - lo)
+	return pal[int((z-lo)*ps+0.5)]
+}
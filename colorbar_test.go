@@ -0,0 +1,167 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plot_test
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/palette"
+	"github.com/gonum/plot/plotter"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/recorder"
+)
+
+type colorBarGrid struct{}
+
+func (colorBarGrid) Dims() (c, r int)   { return 3, 2 }
+func (colorBarGrid) Z(c, r int) float64 { return float64(c + 3*r) }
+func (colorBarGrid) X(c int) float64    { return float64(c) }
+func (colorBarGrid) Y(r int) float64    { return float64(r) }
+
+func TestColorBar(t *testing.T) {
+	for _, horizontal := range []bool{false, true} {
+		p, err := plot.New()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		h := plotter.NewHeatMap(colorBarGrid{}, palette.Heat(8, 1))
+		h.Rasterization = plotter.RasterNever
+		p.Add(h)
+		b, err := h.ColorBar()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if b.Min != 0 || b.Max != 5 {
+			t.Errorf("unexpected color bar range: got:[%v, %v] want:[0, 5]", b.Min, b.Max)
+		}
+		b.Horizontal = horizontal
+		b.Underflow = color.Black
+
+		var r recorder.Canvas
+		c := draw.NewCanvas(&r, 300, 200)
+		before := p.DataCanvas(c)
+		p.ColorBar = b
+		after := p.DataCanvas(c)
+		if horizontal {
+			if after.Min.Y <= before.Min.Y || after.Max.X != before.Max.X {
+				t.Errorf("unexpected data area with horizontal color bar: got:%v without bar:%v", after.Rectangle, before.Rectangle)
+			}
+		} else {
+			if after.Max.X >= before.Max.X || after.Min.Y != before.Min.Y {
+				t.Errorf("unexpected data area with vertical color bar: got:%v without bar:%v", after.Rectangle, before.Rectangle)
+			}
+		}
+
+		p.Draw(c)
+		var images []*recorder.DrawImage
+		for _, a := range r.Actions {
+			if img, ok := a.(*recorder.DrawImage); ok {
+				images = append(images, img)
+			}
+		}
+		if len(images) != 1 {
+			t.Fatalf("unexpected number of images drawn: got:%d want:1", len(images))
+		}
+		img := images[0]
+		if horizontal {
+			if img.Y+img.H > after.Min.Y || img.X != after.Min.X+b.Width || img.X+img.W != after.Max.X {
+				t.Errorf("unexpected horizontal gradient position: got:(%v, %v, %v, %v) data:%v", img.X, img.Y, img.W, img.H, after.Rectangle)
+			}
+			if got := img.Image.Bounds().Dx(); got != 14 {
+				t.Errorf("unexpected gradient size: got:%d want:14", got)
+			}
+		} else {
+			if img.X < after.Max.X || img.Y != after.Min.Y+b.Width || img.Y+img.H != after.Max.Y {
+				t.Errorf("unexpected vertical gradient position: got:(%v, %v, %v, %v) data:%v", img.X, img.Y, img.W, img.H, after.Rectangle)
+			}
+			if got := img.Image.Bounds().Dy(); got != 14 {
+				t.Errorf("unexpected gradient size: got:%d want:14", got)
+			}
+		}
+	}
+}
+
+func TestContourColorBar(t *testing.T) {
+	h := plotter.NewContour(colorBarGrid{}, []float64{4, 1, 2}, nil)
+	_, err := h.ColorBar()
+	if err == nil {
+		t.Errorf("expected error for contour without palette")
+	}
+
+	h.Palette = palette.Heat(3, 1)
+	h.Max = 3
+	h.Overflow = color.Black
+	b, err := h.ColorBar()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(b.Levels, h.Levels) {
+		t.Errorf("unexpected color bar levels: got:%v want:%v", b.Levels, h.Levels)
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Add(h)
+	p.ColorBar = b
+	var r recorder.Canvas
+	c := draw.NewCanvas(&r, 300, 200)
+	p.Draw(c)
+
+	pal := h.Palette.Colors()
+	want := []color.Color{pal[0], pal[1], color.Black}
+	var got []color.Color
+	var fill color.Color
+	for _, a := range r.Actions {
+		switch a := a.(type) {
+		case *recorder.DrawImage:
+			t.Errorf("unexpected image drawn for contour color bar")
+		case *recorder.SetColor:
+			fill = a.Color
+		case *recorder.Fill:
+			got = append(got, fill)
+		}
+	}
+	// The first fill is the background of the plot.
+	if len(got) > 0 {
+		got = got[1:]
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected color bar band colors: got:%v want:%v", got, want)
+	}
+}
+
+func TestColorBarFormatter(t *testing.T) {
+	b, err := plot.NewColorBar(palette.Heat(4, 1), 0.1, 0.3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b.Levels = []float64{0.1, 0.2, 0.3}
+	b.Axis.Tick.Formatter = plot.PercentFormat{}
+
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.HideAxes()
+	p.ColorBar = b
+	var r recorder.Canvas
+	p.Draw(draw.NewCanvas(&r, 300, 200))
+
+	var got []string
+	for _, a := range r.Actions {
+		if s, ok := a.(*recorder.FillString); ok {
+			got = append(got, s.String)
+		}
+	}
+	want := []string{"10%", "20%", "30%"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected color bar labels: got:%q want:%q", got, want)
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plot
+
+import (
+	"errors"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Figure is a grid of plots, its panels, drawn together
+// on a single canvas.  The data areas of the panels in
+// each row and in each column are aligned with each other.
+type Figure struct {
+	Title struct {
+		// Text is the text of the figure title.  If
+		// Text is the empty string then the figure
+		// will not have a title.
+		Text string
+
+		// Padding is the amount of padding
+		// between the bottom of the title and
+		// the top of the panels.
+		Padding vg.Length
+
+		draw.TextStyle
+	}
+
+	// BackgroundColor is the background color of the figure.
+	// The default is White.
+	BackgroundColor color.Color
+
+	// Tiles specifies the padding around the panels.
+	// The number of rows and columns of panels is set
+	// by NewFigure, and the Rows and Cols of Tiles are
+	// ignored.
+	Tiles draw.Tiles
+
+	// SharedX and SharedY specify whether the panels in each
+	// column share the range of their X axes, and whether the
+	// panels in each row share the range of their Y axes.
+	SharedX, SharedY bool
+
+	// Legend is the figure's common legend.  If it has
+	// entries it is drawn to the right of the panels.
+	Legend Legend
+
+	// panels holds the plots indexed by row then column.
+	panels [][]*Plot
+}
+
+// NewFigure returns a new figure with the given number of
+// rows and columns of panels and some reasonable default
+// settings.  The panels of the figure are initially empty.
+func NewFigure(rows, cols int) (*Figure, error) {
+	if rows < 1 || cols < 1 {
+		return nil, errors.New("plot: figure must have at least one row and column")
+	}
+	titleFont, err := vg.MakeFont(DefaultFont, 14)
+	if err != nil {
+		return nil, err
+	}
+	legend, err := makeLegend()
+	if err != nil {
+		return nil, err
+	}
+	f := &Figure{
+		BackgroundColor: color.White,
+		Tiles: draw.Tiles{
+			Rows: rows,
+			Cols: cols,
+			PadX: vg.Points(10),
+			PadY: vg.Points(10),
+		},
+		Legend: legend,
+		panels: make([][]*Plot, rows),
+	}
+	for i := range f.panels {
+		f.panels[i] = make([]*Plot, cols)
+	}
+	f.Title.TextStyle = draw.TextStyle{
+		Color: color.Black,
+		Font:  titleFont,
+	}
+	return f, nil
+}
+
+// Set sets the panel at the given row and column to p.
+// A nil p leaves the panel empty.
+func (f *Figure) Set(row, col int, p *Plot) {
+	f.panels[row][col] = p
+}
+
+// At returns the plot in the panel at the given row
+// and column, or nil if the panel is empty.
+func (f *Figure) At(row, col int) *Plot {
+	return f.panels[row][col]
+}
+
+// Draw draws the figure to a draw.Canvas.
+//
+// If SharedX or SharedY are true then the panels are
+// drawn with the ranges of their axes set to the union
+// of the ranges of the panels in their column or row.
+// The plots of the panels are not changed.
+func (f *Figure) Draw(c draw.Canvas) {
+	if f.BackgroundColor != nil {
+		c.SetColor(f.BackgroundColor)
+		c.Fill(c.Rectangle.Path())
+	}
+	if f.Title.Text != "" {
+		c.FillText(f.Title.TextStyle, c.Center().X, c.Max.Y, -0.5, -1, f.Title.Text)
+		c.Max.Y -= f.Title.Height(f.Title.Text) - f.Title.Font.Extents().Descent
+		c.Max.Y -= f.Title.Padding
+	}
+	if len(f.Legend.entries) > 0 {
+		w := f.Legend.width() + f.Legend.TextStyle.Width(" ")
+		f.Legend.draw(draw.Crop(c, c.Size().X-w, 0, 0, 0))
+		c.Max.X -= w
+	}
+
+	panels := f.sharedPanels()
+	tiles := f.Tiles
+	tiles.Rows, tiles.Cols = len(panels), len(panels[0])
+
+	// Find the largest offsets of the data area from the edges
+	// of the tile within each column and each row, so that the
+	// data areas of all of the panels can be aligned.
+	left := make([]vg.Length, tiles.Cols)
+	right := make([]vg.Length, tiles.Cols)
+	bottom := make([]vg.Length, tiles.Rows)
+	top := make([]vg.Length, tiles.Rows)
+	for i, row := range panels {
+		for j, p := range row {
+			if p == nil {
+				continue
+			}
+			tile := tiles.At(c, j, i)
+			da := p.DataCanvas(tile)
+			left[j] = maxLength(left[j], da.Min.X-tile.Min.X)
+			right[j] = maxLength(right[j], tile.Max.X-da.Max.X)
+			bottom[i] = maxLength(bottom[i], da.Min.Y-tile.Min.Y)
+			top[i] = maxLength(top[i], tile.Max.Y-da.Max.Y)
+		}
+	}
+
+	for i, row := range panels {
+		for j, p := range row {
+			if p == nil {
+				continue
+			}
+			tile := tiles.At(c, j, i)
+			da := p.DataCanvas(tile)
+			p.Draw(draw.Crop(tile,
+				left[j]-(da.Min.X-tile.Min.X),
+				-(right[j] - (tile.Max.X - da.Max.X)),
+				bottom[i]-(da.Min.Y-tile.Min.Y),
+				-(top[i] - (tile.Max.Y - da.Max.Y)),
+			))
+		}
+	}
+}
+
+// sharedPanels returns copies of the plots of the panels
+// with the axis ranges in each column and row shared as
+// specified by SharedX and SharedY. The ranges of axes
+// with AutoRange set are computed from the data first,
+// and AutoRange is turned off on the shared axes of the
+// copies so that drawing them keeps the shared ranges.
+func (f *Figure) sharedPanels() [][]*Plot {
+	panels := make([][]*Plot, len(f.panels))
+	for i, row := range f.panels {
+		panels[i] = make([]*Plot, len(row))
+		for j, p := range row {
+			if p == nil {
+				continue
+			}
+			cpy := *p
+			if f.SharedX || f.SharedY {
+				cpy.autoRange()
+			}
+			panels[i][j] = &cpy
+		}
+	}
+	if f.SharedX {
+		col := make([]*Plot, len(panels))
+		for j := range panels[0] {
+			for i := range panels {
+				col[i] = panels[i][j]
+			}
+			shareRange(col, func(p *Plot) *Axis { return &p.X }, true)
+			shareRange(col, func(p *Plot) *Axis { return &p.X2 }, false)
+		}
+	}
+	if f.SharedY {
+		for _, row := range panels {
+			shareRange(row, func(p *Plot) *Axis { return &p.Y }, true)
+			shareRange(row, func(p *Plot) *Axis { return &p.Y2 }, false)
+		}
+	}
+	return panels
+}
+
+// shareRange sets the ranges of the axes of the plots
+// given by axis to the union of their ranges. If always
+// is false, axes without a range, such as unused secondary
+// axes, are left without one.
+func shareRange(ps []*Plot, axis func(*Plot) *Axis, always bool) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, p := range ps {
+		if p != nil {
+			a := axis(p)
+			min = math.Min(min, a.Min)
+			max = math.Max(max, a.Max)
+		}
+	}
+	for _, p := range ps {
+		if p == nil {
+			continue
+		}
+		if a := axis(p); always || a.hasRange() {
+			a.Min, a.Max = min, max
+			a.AutoRange = false
+		}
+	}
+}
+
+// WriterTo returns an io.WriterTo that will write the figure as
+// the specified image format.
+//
+// Supported formats are the same as for Plot.WriterTo.
+func (f *Figure) WriterTo(w, h vg.Length, format string) (io.WriterTo, error) {
+	c, err := draw.NewFormattedCanvas(w, h, format)
+	if err != nil {
+		return nil, err
+	}
+	f.Draw(draw.New(c))
+	return c, nil
+}
+
+// Save saves the figure to an image file.  The file format is
+// determined by the extension.
+//
+// Supported extensions are the same as for Plot.Save.
+func (f *Figure) Save(w, h vg.Length, file string) error {
+	return save(w, h, file, f.WriterTo)
+}
+
+func maxLength(a, b vg.Length) vg.Length {
+	if a > b {
+		return a
+	}
+	return b
+}
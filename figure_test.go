@@ -0,0 +1,167 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plot_test
+
+import (
+	"testing"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/recorder"
+)
+
+// canvasRecorder is a Plotter that records the data
+// canvas and axis ranges it is drawn with.
+type canvasRecorder struct {
+	xmin, xmax, ymin, ymax float64
+
+	c          draw.Canvas
+	gotX, gotY [2]float64
+}
+
+func (r *canvasRecorder) Plot(c draw.Canvas, plt *plot.Plot) {
+	r.c = c
+	r.gotX = [2]float64{plt.X.Min, plt.X.Max}
+	r.gotY = [2]float64{plt.Y.Min, plt.Y.Max}
+}
+
+func (r *canvasRecorder) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return r.xmin, r.xmax, r.ymin, r.ymax
+}
+
+func TestFigureAlignment(t *testing.T) {
+	f, err := plot.NewFigure(2, 2)
+	if err != nil {
+		t.Fatalf("failed to create figure: %v", err)
+	}
+	f.SharedX = true
+	f.SharedY = true
+
+	data := [2][2]*canvasRecorder{
+		{{xmin: 0, xmax: 1, ymin: 0, ymax: 1}, {xmin: 0, xmax: 100, ymin: -1000, ymax: 1000}},
+		{{xmin: -5, xmax: 1, ymin: 0, ymax: 0.001}, {xmin: 0, xmax: 1, ymin: 0, ymax: 1}},
+	}
+	for i, row := range data {
+		for j, d := range row {
+			p, err := plot.New()
+			if err != nil {
+				t.Fatalf("failed to create plot: %v", err)
+			}
+			if i == 0 && j == 1 {
+				p.Title.Text = "Panel with title"
+				p.Y.Label.Text = "Y"
+			}
+			p.Add(d)
+			f.Set(i, j, p)
+		}
+	}
+
+	f.Draw(draw.NewCanvas(new(recorder.Canvas), 400, 400))
+
+	for i := 0; i < 2; i++ {
+		if got, want := data[i][0].c.Min.Y, data[i][1].c.Min.Y; got != want {
+			t.Errorf("data areas in row %d not aligned at bottom: %v != %v", i, got, want)
+		}
+		if got, want := data[i][0].c.Max.Y, data[i][1].c.Max.Y; got != want {
+			t.Errorf("data areas in row %d not aligned at top: %v != %v", i, got, want)
+		}
+		if got, want := data[i][0].gotY, data[i][1].gotY; got != want {
+			t.Errorf("Y ranges in row %d not shared: %v != %v", i, got, want)
+		}
+	}
+	for j := 0; j < 2; j++ {
+		if got, want := data[0][j].c.Min.X, data[1][j].c.Min.X; got != want {
+			t.Errorf("data areas in column %d not aligned at left: %v != %v", j, got, want)
+		}
+		if got, want := data[0][j].c.Max.X, data[1][j].c.Max.X; got != want {
+			t.Errorf("data areas in column %d not aligned at right: %v != %v", j, got, want)
+		}
+		if got, want := data[0][j].gotX, data[1][j].gotX; got != want {
+			t.Errorf("X ranges in column %d not shared: %v != %v", j, got, want)
+		}
+	}
+}
+
+func TestFigureAutoRange(t *testing.T) {
+	f, err := plot.NewFigure(2, 1)
+	if err != nil {
+		t.Fatalf("failed to create figure: %v", err)
+	}
+	f.SharedX = true
+
+	data := []*canvasRecorder{
+		{xmin: 0, xmax: 1, ymin: 0, ymax: 1},
+		{xmin: 0, xmax: 2, ymin: 0, ymax: 1},
+	}
+	for i, d := range data {
+		p, err := plot.New()
+		if err != nil {
+			t.Fatalf("failed to create plot: %v", err)
+		}
+		p.X.AutoRange = true
+		p.Add(d)
+		f.Set(i, 0, p)
+	}
+
+	// The data change after the plotters are added.
+	data[1].xmax = 5
+	f.Draw(draw.NewCanvas(new(recorder.Canvas), 400, 400))
+	for i, d := range data {
+		if want := [2]float64{0, 5}; d.gotX != want {
+			t.Errorf("unexpected X range of panel %d: got:%v want:%v", i, d.gotX, want)
+		}
+	}
+}
+
+func TestFigureSharedCopies(t *testing.T) {
+	if _, err := plot.NewFigure(0, 1); err == nil {
+		t.Errorf("expected error for figure without rows")
+	}
+
+	f, err := plot.NewFigure(2, 1)
+	if err != nil {
+		t.Fatalf("failed to create figure: %v", err)
+	}
+	f.SharedX = true
+
+	// The plotters bound to X2 see it as their X axis.
+	secondary := []*canvasRecorder{
+		{xmin: 0, xmax: 1, ymin: 0, ymax: 1},
+		{xmin: 0, xmax: 2, ymin: 0, ymax: 1},
+	}
+	primary := []*canvasRecorder{
+		{xmin: 0, xmax: 1, ymin: 0, ymax: 1},
+		{xmin: 1, xmax: 1, ymin: 0, ymax: 1},
+	}
+	var plots []*plot.Plot
+	for i := range primary {
+		p, err := plot.New()
+		if err != nil {
+			t.Fatalf("failed to create plot: %v", err)
+		}
+		p.Add(primary[i])
+		p.AddOn(plot.X2Y, secondary[i])
+		f.Set(i, 0, p)
+		plots = append(plots, p)
+	}
+
+	// The size of the grid is that given to NewFigure.
+	f.Tiles.Rows, f.Tiles.Cols = 5, 5
+	f.Draw(draw.NewCanvas(new(recorder.Canvas), 400, 400))
+	for i := range primary {
+		if want := [2]float64{0, 1}; primary[i].gotX != want {
+			t.Errorf("unexpected X range of panel %d: got:%v want:%v", i, primary[i].gotX, want)
+		}
+		if want := [2]float64{0, 2}; secondary[i].gotX != want {
+			t.Errorf("unexpected X2 range of panel %d: got:%v want:%v", i, secondary[i].gotX, want)
+		}
+	}
+	if p := plots[1]; p.X.Min != 1 || p.X.Max != 1 {
+		t.Errorf("plot changed by drawing figure: X:[%v, %v]", p.X.Min, p.X.Max)
+	}
+	if p := plots[0]; p.X2.Min != 0 || p.X2.Max != 1 {
+		t.Errorf("plot changed by drawing figure: X2:[%v, %v]", p.X2.Min, p.X2.Max)
+	}
+}
@@ -1,3 +1,15 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gob registers the types of the plot packages with
+// encoding/gob so that a *plot.Plot can be gob encoded and
+// decoded, including its plotters and legend entries.
+//
+// Function plotters are decoded without their function, and
+// plotters, tickers, tick formatters, normalizers, colors and
+// palettes of types from other packages must be registered by
+// the user.
 package gob
 
 import (
@@ -5,41 +17,103 @@ import (
 	"image/color"
 
 	"github.com/gonum/plot"
+	"github.com/gonum/plot/palette"
+	"github.com/gonum/plot/palette/brewer"
 	"github.com/gonum/plot/plotter"
+	"github.com/gonum/plot/vg/draw"
 )
 
 func init() {
 	// register types for proper gob-encoding/decoding
+
+	// color.Color
+	gob.Register(color.Alpha{})
+	gob.Register(color.Alpha16{})
+	gob.Register(color.CMYK{})
+	gob.Register(color.Gray{})
 	gob.Register(color.Gray16{})
+	gob.Register(color.NRGBA{})
+	gob.Register(color.NRGBA64{})
+	gob.Register(color.RGBA{})
+	gob.Register(color.RGBA64{})
+	gob.Register(palette.HSVA{})
+	gob.Register(brewer.Color{})
+
+	// palette.Palette
+	gob.Register(palette.Heat(1, 1))
+	gob.Register(palette.Radial(2, 0, 0, 1))
+	gob.Register(brewer.DivergingPalette{})
+	gob.Register(brewer.NonDivergingPalette{})
+
+	// draw.GlyphDrawer
+	gob.Register(draw.BoxGlyph{})
+	gob.Register(draw.CircleGlyph{})
+	gob.Register(draw.CrossGlyph{})
+	gob.Register(draw.PlusGlyph{})
+	gob.Register(draw.PyramidGlyph{})
+	gob.Register(draw.RingGlyph{})
+	gob.Register(draw.SquareGlyph{})
+	gob.Register(draw.TriangleGlyph{})
 
 	// plot.Ticker
 	gob.Register(plot.ConstantTicks{})
 	gob.Register(plot.DefaultTicks{})
 	gob.Register(plot.LogTicks{})
+	gob.Register(plot.TimeTicks{})
+	gob.Register(plot.SymLogTicks{})
+	gob.Register(plot.SqrtTicks{})
+	gob.Register(plot.LogitTicks{})
+	gob.Register(plot.BrokenTicks{})
+
+	// plot.TickFormatter
+	gob.Register(plot.SIFormat{})
+	gob.Register(plot.EngineeringFormat{})
+	gob.Register(plot.FixedFormat{})
+	gob.Register(plot.PercentFormat{})
+	gob.Register(plot.CurrencyFormat{})
+	gob.Register(plot.ByteFormat{})
+	gob.Register(plot.DurationFormat{})
 
 	// plot.Normalizer
 	gob.Register(plot.LinearScale{})
 	gob.Register(plot.LogScale{})
+	gob.Register(plot.TimeScale{})
+	gob.Register(plot.SymLogScale{})
+	gob.Register(plot.SqrtScale{})
+	gob.Register(plot.LogitScale{})
+	gob.Register(plot.InvertedScale{})
+	gob.Register(plot.BrokenScale{})
 
 	// plot.Plotter
-	gob.Register(plotter.BarChart{})
-	gob.Register(plotter.Histogram{})
-	gob.Register(plotter.BoxPlot{})
+	//
+	// Plotters with pointer receivers are registered
+	// as pointers so that decoded values implement
+	// plot.Plotter.
+	gob.Register(&plotter.BarChart{})
+	gob.Register(&plotter.Histogram{})
+	gob.Register(&plotter.BoxPlot{})
 	gob.Register(plotter.HorizBoxPlot{})
-	gob.Register(plotter.Bubbles{})
-	gob.Register(plotter.YErrorBars{})
-	gob.Register(plotter.XErrorBars{})
-	gob.Register(plotter.Function{})
+	gob.Register(&plotter.Bubbles{})
+	gob.Register(&plotter.Contour{})
+	gob.Register(&plotter.YErrorBars{})
+	gob.Register(&plotter.XErrorBars{})
+	gob.Register(&plotter.Function{})
 	gob.Register(plotter.GlyphBoxes{})
-	gob.Register(plotter.Grid{})
-	gob.Register(plotter.Labels{})
-	gob.Register(plotter.Line{})
-	gob.Register(plotter.QuartPlot{})
+	gob.Register(&plotter.Grid{})
+	gob.Register(&plotter.HeatMap{})
+	gob.Register(&plotter.Labels{})
+	gob.Register(&plotter.Line{})
+	gob.Register(&plotter.QuartPlot{})
 	gob.Register(plotter.HorizQuartPlot{})
-	gob.Register(plotter.Scatter{})
+	gob.Register(&plotter.Scatter{})
+	gob.Register(&plotter.StreamLine{})
+	gob.Register(&plotter.StreamScatter{})
 
 	// plotter.XYZer
 	gob.Register(plotter.XYZs{})
 	gob.Register(plotter.XYValues{})
 
+	// plotter.XYer
+	gob.Register(plotter.XYs{})
+	gob.Register(&plotter.Ring{})
 }
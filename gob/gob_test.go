@@ -11,6 +11,7 @@ import (
 	"math/rand"
 	"os"
 	"testing"
+	"time"
 
 	_ "github.com/gonum/plot/gob"
 
@@ -92,22 +93,140 @@ func TestPersistency(t *testing.T) {
 		t.Fatalf("error gob-encoding plot: %v\n", err)
 	}
 
-	// TODO(sbinet): impl. BinaryMarshal for plot.Plot and vg.Font
-	// {
-	// 	dec := gob.NewDecoder(buf)
-	// 	var p plot.Plot
-	// 	err = dec.Decode(&p)
-	// 	if err != nil {
-	// 		t.Fatalf("error gob-decoding plot: %v\n", err)
-	// 	}
-	// 	// Save the plot to a PNG file.
-	// 	err = p.Save(4, 4, "test-persistency-readback.png")
-	// 	if err != nil {
-	// 		t.Fatalf("error saving to PNG: %v\n", err)
-	// 	}
-	//  defer os.Remove("test-persistency-readback.png")
-	// }
+	dec := gob.NewDecoder(buf)
+	var got plot.Plot
+	err = dec.Decode(&got)
+	if err != nil {
+		t.Fatalf("error gob-decoding plot: %v\n", err)
+	}
+	// Save the plot to a PNG file.
+	err = got.Save(4, 4, "test-persistency-readback.png")
+	if err != nil {
+		t.Fatalf("error saving to PNG: %v\n", err)
+	}
+	defer os.Remove("test-persistency-readback.png")
+
+	checkRoundTrip(t, p, &got)
+}
+
+func TestPersistencyPlotters(t *testing.T) {
+	rand.Seed(1)
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("error creating plot: %v\n", err)
+	}
+	p.Title.Text = "Plotters"
+	p.X.Tick.Marker = plot.TimeTicks{Format: "15:04", Location: time.UTC}
+	p.X.Scale = plot.TimeScale{}
+	p.Y2.Label.Text = "Y2"
+
+	bars, err := plotter.NewBarChart(plotter.Values{1, 2, 3}, vg.Points(10))
+	if err != nil {
+		t.Fatalf("error creating bar chart: %v\n", err)
+	}
+	stacked, err := plotter.NewBarChart(plotter.Values{3, 2, 1}, vg.Points(10))
+	if err != nil {
+		t.Fatalf("error creating bar chart: %v\n", err)
+	}
+	stacked.StackOn(bars)
+	stacked.Color = color.NRGBA{R: 200, A: 128}
 
+	box, err := plotter.NewBoxPlot(vg.Points(20), 4, plotter.Values{1, 2, 3, 4, 5, 20})
+	if err != nil {
+		t.Fatalf("error creating box plot: %v\n", err)
+	}
+	quart, err := plotter.NewQuartPlot(5, plotter.Values{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatalf("error creating quartile plot: %v\n", err)
+	}
+	hist, err := plotter.NewHist(plotter.Values{0.5, 1, 1.5, 1.5, 2}, 3)
+	if err != nil {
+		t.Fatalf("error creating histogram: %v\n", err)
+	}
+	labels, err := plotter.NewLabels(plotter.XYLabels{
+		XYs:    plotter.XYs{{X: 1, Y: 4}, {X: 2, Y: 5}},
+		Labels: []string{"a", "b"},
+	})
+	if err != nil {
+		t.Fatalf("error creating labels: %v\n", err)
+	}
+	l, err := plotter.NewLine(randomPoints(5))
+	if err != nil {
+		t.Fatalf("error creating line: %v\n", err)
+	}
+
+	ring := plotter.NewRing(4, 0)
+	for i := 0; i < 6; i++ {
+		ring.Append(float64(i), float64(i%3))
+	}
+
+	p.Add(bars, stacked, box, quart, hist, labels)
+	p.Add(plotter.NewStreamLine(ring), plotter.NewStreamScatter(ring))
+	p.AddOn(plot.XY2, l)
+	p.Legend.Add("bars", bars, stacked)
+	p.Legend.Add("line", l)
+	p.Legend.Add("extra", plotter.NewFunction(func(x float64) float64 { return x }))
+
+	var buf bytes.Buffer
+	err = gob.NewEncoder(&buf).Encode(p)
+	if err != nil {
+		t.Fatalf("error gob-encoding plot: %v\n", err)
+	}
+	var got plot.Plot
+	err = gob.NewDecoder(&buf).Decode(&got)
+	if err != nil {
+		t.Fatalf("error gob-decoding plot: %v\n", err)
+	}
+
+	if tt, ok := got.X.Tick.Marker.(plot.TimeTicks); !ok || tt.Location != time.UTC || tt.Format != "15:04" {
+		t.Errorf("unexpected X tick marker after round trip: got:%#v", got.X.Tick.Marker)
+	}
+	if name := got.Title.Font.Name(); name != plot.DefaultFont {
+		t.Errorf("unexpected title font after round trip: got:%q want:%q", name, plot.DefaultFont)
+	}
+	if got.Title.Font.Font() == nil {
+		t.Error("title font not resolved after round trip")
+	}
+	checkRoundTrip(t, p, &got)
+}
+
+// checkRoundTrip checks that the rendered outputs of
+// the plots want and got are identical.
+func checkRoundTrip(t *testing.T, want, got *plot.Plot) {
+	for _, format := range []string{"svg", "eps"} {
+		w, err := want.WriterTo(4*vg.Inch, 4*vg.Inch, format)
+		if err != nil {
+			t.Fatalf("error rendering plot: %v\n", err)
+		}
+		g, err := got.WriterTo(4*vg.Inch, 4*vg.Inch, format)
+		if err != nil {
+			t.Fatalf("error rendering decoded plot: %v\n", err)
+		}
+		var wbuf, gbuf bytes.Buffer
+		if _, err = w.WriteTo(&wbuf); err != nil {
+			t.Fatalf("error writing plot: %v\n", err)
+		}
+		if _, err = g.WriteTo(&gbuf); err != nil {
+			t.Fatalf("error writing decoded plot: %v\n", err)
+		}
+		if wbuf.Len() == 0 {
+			t.Errorf("empty %s output", format)
+		}
+		if !bytes.Equal(stripDate(wbuf.Bytes()), stripDate(gbuf.Bytes())) {
+			t.Errorf("%s output differs after gob round trip", format)
+		}
+	}
+}
+
+// stripDate removes the EPS creation date line from b.
+func stripDate(b []byte) []byte {
+	var lines [][]byte
+	for _, l := range bytes.Split(b, []byte("\n")) {
+		if !bytes.HasPrefix(l, []byte("%%CreationDate:")) {
+			lines = append(lines, l)
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
 }
 
 // randomPoints returns some random x, y points.
@@ -5,6 +5,11 @@
 package plot
 
 import (
+	"image/color"
+	"math"
+	"reflect"
+	"sort"
+
 	"github.com/gonum/plot/vg"
 	"github.com/gonum/plot/vg/draw"
 )
@@ -38,9 +43,49 @@ type Legend struct {
 	// final position.
 	XOffs, YOffs vg.Length
 
+	// Placement specifies where the legend is drawn.
+	// The default, LegendInside, draws the legend inside
+	// the data area at the corner given by Top and Left.
+	Placement LegendPlacement
+
 	// ThumbnailWidth is the width of legend thumbnails.
 	ThumbnailWidth vg.Length
 
+	// BackgroundColor is the color of the legend
+	// background.  If BackgroundColor is nil then
+	// no background is drawn.
+	BackgroundColor color.Color
+
+	// Border is the style of the border drawn around
+	// the legend.  If the width of the border is zero
+	// or its color is nil then no border is drawn.
+	Border draw.LineStyle
+
+	// Inset is the space between the legend border
+	// and the title and entries of the legend.
+	Inset vg.Length
+
+	// Title is the title drawn above the legend entries.
+	// If Title.Text is the empty string then the legend
+	// has no title.
+	Title struct {
+		Text string
+		draw.TextStyle
+	}
+
+	// Columns is the number of columns in which the
+	// entries are laid out, each column being filled
+	// before the next.  If Columns is less than two
+	// then the entries are laid out in a single column.
+	Columns int
+
+	// Horizontal specifies that the entries are laid
+	// out in a single row.  It overrides Columns.
+	Horizontal bool
+
+	// Order is the order in which entries are drawn.
+	Order LegendOrder
+
 	// entries are all of the legendEntries described
 	// by this legend.
 	entries []legendEntry
@@ -56,6 +101,13 @@ type legendEntry struct {
 	thumbs []Thumbnailer
 }
 
+// byText sorts legend entries by their text.
+type byText []legendEntry
+
+func (e byText) Len() int           { return len(e) }
+func (e byText) Less(i, j int) bool { return e[i].text < e[j].text }
+func (e byText) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+
 // Thumbnailer wraps the Thumbnail method, which
 // draws the small image in a legend representing the
 // style of data.
@@ -74,48 +126,433 @@ func makeLegend() (Legend, error) {
 	if err != nil {
 		return Legend{}, err
 	}
-	return Legend{
+	l := Legend{
 		ThumbnailWidth: vg.Points(20),
 		TextStyle:      draw.TextStyle{Font: font},
-	}, nil
+	}
+	l.Title.TextStyle = draw.TextStyle{Color: color.Black, Font: font}
+	return l, nil
 }
 
+// LegendPlacement specifies where a legend is drawn.
+type LegendPlacement int
+
+const (
+	// LegendInside draws the legend inside the data
+	// area at the corner given by the Top and Left
+	// fields of the Legend.
+	LegendInside LegendPlacement = iota
+
+	// LegendAuto draws the legend inside the data area
+	// at the corner or side where it covers the least
+	// data.  The corner given by Top and Left is chosen
+	// if it is as good as any other.
+	LegendAuto
+
+	// LegendRight draws the legend to the right of the
+	// plot, beside the data area, which is narrowed to
+	// make room for it.
+	LegendRight
+
+	// LegendBelow draws the legend below the X axis,
+	// under the data area, which is shortened to make
+	// room for it.
+	LegendBelow
+)
+
+// LegendOrder specifies the order in which legend
+// entries are drawn.
+type LegendOrder int
+
+const (
+	// LegendAdded draws entries in the order in
+	// which they were added.
+	LegendAdded LegendOrder = iota
+
+	// LegendReversed draws entries in the reverse of
+	// the order in which they were added.
+	LegendReversed
+
+	// LegendSorted draws entries sorted by their text.
+	LegendSorted
+)
+
 // draw draws the legend to the given draw.Canvas.
 func (l *Legend) draw(c draw.Canvas) {
-	iconx := c.Min.X
-	textx := iconx + l.ThumbnailWidth + l.TextStyle.Width(" ")
-	xalign := 0.0
-	if !l.Left {
-		iconx = c.Max.X - l.ThumbnailWidth
-		textx = iconx - l.TextStyle.Width(" ")
-		xalign = -1
+	l.drawIn(c, l.rectangle(c, l.Top, l.Left, false), l.Left)
+}
+
+// rectangle returns the rectangle occupied by the legend
+// when it is placed in c at the corner given by top and
+// left, or centered on the top or bottom side if center
+// is true.
+func (l *Legend) rectangle(c draw.Canvas, top, left, center bool) draw.Rectangle {
+	w, h := l.width(), l.height()
+	var r draw.Rectangle
+	switch {
+	case center:
+		r.Min.X = c.Center().X - w/2
+	case left:
+		r.Min.X = c.Min.X
+	default:
+		r.Min.X = c.Max.X - w
 	}
-	textx += l.XOffs
-	iconx += l.XOffs
+	if top {
+		r.Min.Y = c.Max.Y - h
+	} else {
+		r.Min.Y = c.Min.Y
+	}
+	r.Min.X += l.XOffs
+	r.Min.Y += l.YOffs
+	r.Max = draw.Point{X: r.Min.X + w, Y: r.Min.Y + h}
+	return r
+}
 
+// drawIn draws the legend in the rectangle r.  If left is
+// true the thumbnails are drawn to the left of the entry text,
+// otherwise they are drawn to the right.
+func (l *Legend) drawIn(c draw.Canvas, r draw.Rectangle, left bool) {
+	if l.BackgroundColor != nil {
+		c.SetColor(l.BackgroundColor)
+		c.Fill(r.Path())
+	}
+	if l.Border.Width > 0 && l.Border.Color != nil {
+		c.SetLineStyle(l.Border)
+		c.Stroke(r.Path())
+	}
+
+	r.Min.X += l.Inset
+	r.Min.Y += l.Inset
+	r.Max.X -= l.Inset
+	r.Max.Y -= l.Inset
+	top := r.Max.Y
+	if l.Title.Text != "" {
+		c.FillText(l.Title.TextStyle, (r.Min.X+r.Max.X)/2, top, -0.5, -1, l.Title.Text)
+		top -= l.titleHeight()
+	}
+
+	cols := l.columns()
+	x := r.Min.X
+	if !left {
+		x = r.Max.X - l.entriesWidth(cols)
+	}
 	enth := l.entryHeight()
-	y := c.Max.Y - enth
-	if !l.Top {
-		y = c.Min.Y + (enth+l.Padding)*(vg.Length(len(l.entries))-1)
+	for _, col := range cols {
+		colw := l.columnWidth(col)
+		iconx := x
+		textx := iconx + l.ThumbnailWidth + l.TextStyle.Width(" ")
+		xalign := 0.0
+		if !left {
+			iconx = x + colw - l.ThumbnailWidth
+			textx = iconx - l.TextStyle.Width(" ")
+			xalign = -1
+		}
+
+		y := top - enth
+		icon := &draw.Canvas{
+			Canvas: c.Canvas,
+			Rectangle: draw.Rectangle{
+				Min: draw.Point{iconx, y},
+				Max: draw.Point{iconx + l.ThumbnailWidth, y + enth},
+			},
+		}
+		for _, e := range col {
+			c.BeginGroup(vg.Annotation{Class: "legend-entry", Title: e.text})
+			for _, t := range e.thumbs {
+				t.Thumbnail(icon)
+			}
+			yoffs := (enth - l.TextStyle.Height(e.text)) / 2
+			c.FillText(l.TextStyle, textx, icon.Min.Y+yoffs, xalign, 0, e.text)
+			c.EndGroup()
+			icon.Min.Y -= enth + l.Padding
+			icon.Max.Y -= enth + l.Padding
+		}
+		x += colw + l.columnGap()
 	}
-	y += l.YOffs
+}
 
-	icon := &draw.Canvas{
-		Canvas: c.Canvas,
-		Rectangle: draw.Rectangle{
-			Min: draw.Point{iconx, y},
-			Max: draw.Point{iconx + l.ThumbnailWidth, y + enth},
-		},
+// columns returns the legend entries in drawing
+// order, divided into the columns of the layout.
+func (l *Legend) columns() [][]legendEntry {
+	n := len(l.entries)
+	if n == 0 {
+		return nil
 	}
-	for _, e := range l.entries {
-		for _, t := range e.thumbs {
-			t.Thumbnail(icon)
+	entries := make([]legendEntry, n)
+	copy(entries, l.entries)
+	switch l.Order {
+	case LegendReversed:
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	case LegendSorted:
+		sort.Stable(byText(entries))
+	}
+
+	ncols := l.Columns
+	if l.Horizontal {
+		ncols = n
+	}
+	if ncols < 1 {
+		ncols = 1
+	}
+	rows := (n + ncols - 1) / ncols
+	var cols [][]legendEntry
+	for i := 0; i < n; i += rows {
+		end := i + rows
+		if end > n {
+			end = n
+		}
+		cols = append(cols, entries[i:end])
+	}
+	return cols
+}
+
+// columnWidth returns the width of a column of entries:
+// the width of the thumbnails and the widest entry text.
+func (l *Legend) columnWidth(col []legendEntry) vg.Length {
+	var w vg.Length
+	for _, e := range col {
+		if tw := l.TextStyle.Width(e.text); tw > w {
+			w = tw
 		}
-		yoffs := (enth - l.TextStyle.Height(e.text)) / 2
-		c.FillText(l.TextStyle, textx, icon.Min.Y+yoffs, xalign, 0, e.text)
-		icon.Min.Y -= enth + l.Padding
-		icon.Max.Y -= enth + l.Padding
 	}
+	return l.ThumbnailWidth + l.TextStyle.Width(" ") + w
+}
+
+// columnGap returns the space between columns of entries.
+func (l *Legend) columnGap() vg.Length {
+	return l.TextStyle.Font.Size
+}
+
+// entriesWidth returns the width of the columns of entries.
+func (l *Legend) entriesWidth(cols [][]legendEntry) vg.Length {
+	var w vg.Length
+	for i, col := range cols {
+		if i > 0 {
+			w += l.columnGap()
+		}
+		w += l.columnWidth(col)
+	}
+	return w
+}
+
+// titleHeight returns the height taken by the legend
+// title, including the space below it.
+func (l *Legend) titleHeight() vg.Length {
+	if l.Title.Text == "" {
+		return 0
+	}
+	return l.Title.Height(l.Title.Text) + l.Padding
+}
+
+// reserve returns the canvas c less the space taken by a
+// legend placed outside the data area, and the space taken
+// by the legend.  If the legend is placed inside the data
+// area, c is returned unchanged.
+func (l *Legend) reserve(c draw.Canvas) (rest, legend draw.Canvas) {
+	if len(l.entries) == 0 {
+		return c, c
+	}
+	switch l.Placement {
+	case LegendRight:
+		w := l.width() + l.gap()
+		return draw.Crop(c, 0, -w, 0, 0), draw.Crop(c, c.Size().X-w, 0, 0, 0)
+	case LegendBelow:
+		h := l.height() + l.gap()
+		return draw.Crop(c, 0, 0, h, 0), draw.Crop(c, 0, 0, 0, h-c.Size().Y)
+	}
+	return c, c
+}
+
+// gap returns the space between a legend placed outside
+// the data area and the rest of the plot.
+func (l *Legend) gap() vg.Length {
+	return l.TextStyle.Width(" ")
+}
+
+// drawLegend draws the plot's legend.  The area is the
+// region bounded by the axes, dataC is the data canvas and
+// reserved is the space reserved for the legend by reserve.
+func (p *Plot) drawLegend(area, dataC, reserved draw.Canvas) {
+	l := &p.Legend
+	if len(l.entries) == 0 {
+		return
+	}
+	switch l.Placement {
+	case LegendAuto:
+		r, left := p.autoLegend(area, dataC)
+		l.drawIn(area, r, left)
+	case LegendRight:
+		w, h := l.width(), l.height()
+		r := draw.Rectangle{Min: draw.Point{
+			X: reserved.Max.X - w,
+			Y: dataC.Center().Y - h/2,
+		}}
+		r.Min.X += l.XOffs
+		r.Min.Y += l.YOffs
+		r.Max = draw.Point{X: r.Min.X + w, Y: r.Min.Y + h}
+		l.drawIn(reserved, r, l.Left)
+	case LegendBelow:
+		w, h := l.width(), l.height()
+		r := draw.Rectangle{Min: draw.Point{
+			X: dataC.Center().X - w/2,
+			Y: reserved.Min.Y,
+		}}
+		r.Min.X += l.XOffs
+		r.Min.Y += l.YOffs
+		r.Max = draw.Point{X: r.Min.X + w, Y: r.Min.Y + h}
+		l.drawIn(reserved, r, l.Left)
+	default:
+		l.draw(area)
+	}
+}
+
+// autoLegend returns the rectangle, among the corners and
+// sides of area, in which the legend covers the least data,
+// and whether the legend thumbnails should be drawn on the
+// left of the entries.
+func (p *Plot) autoLegend(area, dataC draw.Canvas) (r draw.Rectangle, left bool) {
+	l := &p.Legend
+	type candidate struct {
+		r    draw.Rectangle
+		left bool
+	}
+	// Ties are broken in favour of the earliest candidate, so
+	// the corner given by Top and Left is tried first, followed
+	// by the other corner on the same edge.
+	var cands []candidate
+	for _, top := range []bool{l.Top, !l.Top} {
+		for _, left := range []bool{l.Left, !l.Left} {
+			cands = append(cands, candidate{l.rectangle(area, top, left, false), left})
+		}
+	}
+	for _, top := range []bool{true, false} {
+		cands = append(cands, candidate{l.rectangle(area, top, l.Left, true), l.Left})
+	}
+	h := l.height()
+	mid := area.Center().Y - h/2 + l.YOffs
+	for _, left := range []bool{true, false} {
+		r := l.rectangle(area, false, left, false)
+		r.Min.Y, r.Max.Y = mid, mid+h
+		cands = append(cands, candidate{r, left})
+	}
+
+	boxes := p.GlyphBoxes(p)
+	best := -1
+	var min vg.Length
+	for i, c := range cands {
+		o := p.occlusion(dataC, boxes, c.r)
+		if best < 0 || o < min {
+			best, min = i, o
+		}
+	}
+	return cands[best].r, cands[best].left
+}
+
+// occlusion returns a measure of the amount of data
+// drawn in dataC that is covered by the rectangle r:
+// the area of the plot's glyph boxes in r, and the
+// length of the lines of other XYer plotters in r.
+func (p *Plot) occlusion(dataC draw.Canvas, boxes []GlyphBox, r draw.Rectangle) vg.Length {
+	var o vg.Length
+	for _, b := range boxes {
+		g := b.Rectangle
+		// Give zero sized glyphs some extent so that
+		// they are not ignored.
+		if g.Size().X < 1 {
+			g.Max.X = g.Min.X + 1
+		}
+		if g.Size().Y < 1 {
+			g.Max.Y = g.Min.Y + 1
+		}
+		x, y := dataC.X(b.X), dataC.Y(b.Y)
+		g.Min.X += x
+		g.Max.X += x
+		g.Min.Y += y
+		g.Max.Y += y
+		o += overlap(r, g)
+	}
+	for i, d := range p.plotters {
+		if _, ok := d.(GlyphBoxer); ok {
+			continue
+		}
+		xys, ok := d.(interface {
+			Len() int
+			XY(int) (float64, float64)
+		})
+		if !ok {
+			continue
+		}
+		trX, trY := p.TransformsOn(p.axes[i], &dataC)
+		var prev draw.Point
+		for j := 0; j < xys.Len(); j++ {
+			x, y := xys.XY(j)
+			pt := draw.Point{X: trX(x), Y: trY(y)}
+			if j > 0 {
+				o += clippedLength(r, prev, pt)
+			}
+			prev = pt
+		}
+	}
+	return o
+}
+
+// overlap returns the area of the intersection
+// of the rectangles a and b.
+func overlap(a, b draw.Rectangle) vg.Length {
+	w := minLength(a.Max.X, b.Max.X) - maxLength(a.Min.X, b.Min.X)
+	h := minLength(a.Max.Y, b.Max.Y) - maxLength(a.Min.Y, b.Min.Y)
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	return w * h
+}
+
+func minLength(a, b vg.Length) vg.Length {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// clippedLength returns the length of the part of
+// the line segment from a to b that is inside r.
+func clippedLength(r draw.Rectangle, a, b draw.Point) vg.Length {
+	// Liang-Barsky clipping of the segment
+	// parameterised as a + t*(b-a), t in [0, 1].
+	t0, t1 := 0.0, 1.0
+	dx, dy := float64(b.X-a.X), float64(b.Y-a.Y)
+	for _, e := range []struct{ p, q float64 }{
+		{-dx, float64(a.X - r.Min.X)},
+		{dx, float64(r.Max.X - a.X)},
+		{-dy, float64(a.Y - r.Min.Y)},
+		{dy, float64(r.Max.Y - a.Y)},
+	} {
+		if e.p == 0 {
+			if e.q < 0 {
+				return 0
+			}
+			continue
+		}
+		t := e.q / e.p
+		if e.p < 0 {
+			if t > t1 {
+				return 0
+			}
+			if t > t0 {
+				t0 = t
+			}
+		} else {
+			if t < t0 {
+				return 0
+			}
+			if t < t1 {
+				t1 = t
+			}
+		}
+	}
+	return vg.Length((t1 - t0) * math.Hypot(dx, dy))
 }
 
 // entryHeight returns the height of the tallest legend
@@ -129,6 +566,49 @@ func (l *Legend) entryHeight() (height vg.Length) {
 	return
 }
 
+// height returns the height of the legend: the height of
+// its title and of the longest column of entries with the
+// padding between them, and the inset.
+func (l *Legend) height() vg.Length {
+	cols := l.columns()
+	if len(cols) == 0 {
+		return 0
+	}
+	rows := vg.Length(len(cols[0]))
+	return l.titleHeight() + rows*l.entryHeight() + (rows-1)*l.Padding + 2*l.Inset
+}
+
+// width returns the width of the legend: the width of
+// the title or of the columns of entries, whichever is
+// the wider, and the inset.
+func (l *Legend) width() vg.Length {
+	w := l.entriesWidth(l.columns())
+	if l.Title.Text != "" {
+		if tw := l.Title.Width(l.Title.Text); tw > w {
+			w = tw
+		}
+	}
+	return w + 2*l.Inset
+}
+
+// nameOf returns the text of the first legend entry
+// with the given thumbnail, or the empty string if
+// there is no such entry.
+func (l *Legend) nameOf(thumb interface{}) string {
+	typ := reflect.TypeOf(thumb)
+	if typ == nil || !typ.Comparable() {
+		return ""
+	}
+	for _, e := range l.entries {
+		for _, t := range e.thumbs {
+			if reflect.TypeOf(t) == typ && interface{}(t) == thumb {
+				return e.text
+			}
+		}
+	}
+	return ""
+}
+
 // Add adds an entry to the legend with the given name.
 // The entry's thumbnail is drawn as the composite of all of the
 // thumbnails.
@@ -0,0 +1,177 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plot
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"image/color"
+	"reflect"
+	"time"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// plotGob is the gob encoding of a Plot.
+type plotGob struct {
+	Title struct {
+		Text    string
+		Padding vg.Length
+		draw.TextStyle
+	}
+	BackgroundColor color.Color
+	X, Y, X2, Y2    Axis
+	Legend          Legend
+	ColorBar        *ColorBar
+	Entries         []entryGob
+	Plotters        []Plotter
+	Axes            []AxisPair
+}
+
+// entryGob is the gob encoding of a legend entry.
+type entryGob struct {
+	Text   string
+	Thumbs []thumbGob
+}
+
+// thumbGob is the gob encoding of a legend thumbnail.
+// A thumbnail that is one of the plot's plotters is
+// encoded as the index of the plotter plus one, so
+// that the decoded entry refers to the decoded plotter.
+type thumbGob struct {
+	Plotter int
+	Thumb   Thumbnailer
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+//
+// The plotters, legend thumbnails, tickers, normalizers and
+// colors of the plot are encoded as interface values, so their
+// concrete types must be registered with the encoding/gob package.
+// Importing the github.com/gonum/plot/gob package registers the
+// types provided by this repository. Fonts are encoded by name
+// and size, and are looked up by name when decoded.
+func (p *Plot) GobEncode() ([]byte, error) {
+	g := plotGob{
+		Title:           p.Title,
+		BackgroundColor: p.BackgroundColor,
+		X:               p.X,
+		Y:               p.Y,
+		X2:              p.X2,
+		Y2:              p.Y2,
+		Legend:          p.Legend,
+		ColorBar:        p.ColorBar,
+		Plotters:        p.plotters,
+		Axes:            p.axes,
+	}
+	for _, e := range p.Legend.entries {
+		eg := entryGob{Text: e.text, Thumbs: make([]thumbGob, len(e.thumbs))}
+		for i, t := range e.thumbs {
+			if j := p.indexOf(t); j >= 0 {
+				eg.Thumbs[i].Plotter = j + 1
+			} else {
+				eg.Thumbs[i].Thumb = t
+			}
+		}
+		g.Entries = append(g.Entries, eg)
+	}
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(g)
+	return buf.Bytes(), err
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (p *Plot) GobDecode(b []byte) error {
+	var g plotGob
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&g)
+	if err != nil {
+		return err
+	}
+	*p = Plot{
+		Title:           g.Title,
+		BackgroundColor: g.BackgroundColor,
+		X:               g.X,
+		Y:               g.Y,
+		X2:              g.X2,
+		Y2:              g.Y2,
+		Legend:          g.Legend,
+		ColorBar:        g.ColorBar,
+		plotters:        g.Plotters,
+		axes:            g.Axes,
+	}
+	for len(p.axes) < len(p.plotters) {
+		p.axes = append(p.axes, XY)
+	}
+	p.Legend.entries = nil
+	for _, eg := range g.Entries {
+		e := legendEntry{text: eg.Text, thumbs: make([]Thumbnailer, len(eg.Thumbs))}
+		for i, t := range eg.Thumbs {
+			if t.Plotter == 0 {
+				e.thumbs[i] = t.Thumb
+				continue
+			}
+			if t.Plotter > len(p.plotters) {
+				return errors.New("plot: legend thumbnail plotter out of range")
+			}
+			th, ok := p.plotters[t.Plotter-1].(Thumbnailer)
+			if !ok {
+				return errors.New("plot: legend thumbnail is not a Thumbnailer")
+			}
+			e.thumbs[i] = th
+		}
+		p.Legend.entries = append(p.Legend.entries, e)
+	}
+	return nil
+}
+
+// indexOf returns the index of the plotter that is
+// identical to v, or -1 if there is no such plotter.
+func (p *Plot) indexOf(v interface{}) int {
+	typ := reflect.TypeOf(v)
+	if typ == nil || !typ.Comparable() {
+		return -1
+	}
+	for i, d := range p.plotters {
+		if reflect.TypeOf(d) == typ && interface{}(d) == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// timeTicksGob is the gob encoding of a TimeTicks.
+type timeTicksGob struct {
+	Format   string
+	Location string
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+// The location is encoded by name.
+func (t TimeTicks) GobEncode() ([]byte, error) {
+	g := timeTicksGob{Format: t.Format}
+	if t.Location != nil {
+		g.Location = t.Location.String()
+	}
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(g)
+	return buf.Bytes(), err
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (t *TimeTicks) GobDecode(b []byte) error {
+	var g timeTicksGob
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&g)
+	if err != nil {
+		return err
+	}
+	t.Format = g.Format
+	t.Location = nil
+	if g.Location != "" {
+		t.Location, err = time.LoadLocation(g.Location)
+	}
+	return err
+}
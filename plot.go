@@ -16,6 +16,7 @@
 package plot
 
 import (
+	"fmt"
 	"image/color"
 	"io"
 	"math"
@@ -56,12 +57,57 @@ type Plot struct {
 	// of the plot respectively.
 	X, Y Axis
 
+	// X2 and Y2 are the secondary horizontal and vertical
+	// axes of the plot, drawn along the top and right edges
+	// respectively.  A secondary axis is only drawn if it
+	// has a range, either because a Plotter bound to it
+	// has been added to the plot or because its Min and
+	// Max have been set explicitly.
+	X2, Y2 Axis
+
 	// Legend is the plot's legend.
 	Legend Legend
 
+	// ColorBar, if not nil, is the color bar
+	// drawn beside the data area of the plot.
+	ColorBar *ColorBar
+
 	// plotters are drawn by calling their Plot method
 	// after the axes are drawn.
 	plotters []Plotter
+
+	// axes holds the pair of axes that each of the
+	// plotters is bound to.
+	axes []AxisPair
+}
+
+// AxisPair identifies the horizontal and vertical axes
+// that a Plotter is drawn against.
+type AxisPair uint8
+
+const (
+	// XY is the primary pair of axes, X and Y.
+	XY AxisPair = 0
+
+	// XY2 pairs the primary X axis with the secondary
+	// Y axis.
+	XY2 AxisPair = 1
+
+	// X2Y pairs the secondary X axis with the primary
+	// Y axis.
+	X2Y AxisPair = 2
+
+	// X2Y2 is the secondary pair of axes, X2 and Y2.
+	X2Y2 = X2Y | XY2
+)
+
+// AxisBinder wraps the AxisPair method.  Plotters that
+// implement AxisBinder are drawn against the pair of axes
+// that it returns when they are added to a plot with Add.
+type AxisBinder interface {
+	// AxisPair returns the axes that the Plotter is
+	// drawn against.
+	AxisPair() AxisPair
 }
 
 // Plotter is an interface that wraps the Plot method.
@@ -95,6 +141,14 @@ func New() (*Plot, error) {
 	if err != nil {
 		return nil, err
 	}
+	x2, err := makeAxis()
+	if err != nil {
+		return nil, err
+	}
+	y2, err := makeAxis()
+	if err != nil {
+		return nil, err
+	}
 	legend, err := makeLegend()
 	if err != nil {
 		return nil, err
@@ -103,6 +157,8 @@ func New() (*Plot, error) {
 		BackgroundColor: color.White,
 		X:               x,
 		Y:               y,
+		X2:              x2,
+		Y2:              y2,
 		Legend:          legend,
 	}
 	p.Title.TextStyle = draw.TextStyle{
@@ -117,22 +173,123 @@ func New() (*Plot, error) {
 // If the plotters implements DataRanger then the
 // minimum and maximum values of the X and Y
 // axes are changed if necessary to fit the range of
-// the data.
+// the data.  Plotters that implement AxisBinder
+// are bound to, and change the range of, the axes
+// returned by their AxisPair method.
 //
 // When drawing the plot, Plotters are drawn in the
 // order in which they were added to the plot.
 func (p *Plot) Add(ps ...Plotter) {
 	for _, d := range ps {
-		if x, ok := d.(DataRanger); ok {
-			xmin, xmax, ymin, ymax := x.DataRange()
-			p.X.Min = math.Min(p.X.Min, xmin)
-			p.X.Max = math.Max(p.X.Max, xmax)
-			p.Y.Min = math.Min(p.Y.Min, ymin)
-			p.Y.Max = math.Max(p.Y.Max, ymax)
+		axes := XY
+		if b, ok := d.(AxisBinder); ok {
+			axes = b.AxisPair()
+		}
+		p.add(axes, d)
+	}
+}
+
+// AddOn adds Plotters to the plot, binding them to
+// the given pair of axes.  The axes' ranges are changed
+// if necessary to fit the range of the data as for Add.
+func (p *Plot) AddOn(axes AxisPair, ps ...Plotter) {
+	for _, d := range ps {
+		p.add(axes, d)
+	}
+}
+
+// add adds a single Plotter bound to the given axes.
+func (p *Plot) add(axes AxisPair, d Plotter) {
+	if x, ok := d.(DataRanger); ok {
+		xa, ya := p.axesOf(axes)
+		xmin, xmax, ymin, ymax := x.DataRange()
+		xa.Min = math.Min(xa.Min, xmin)
+		xa.Max = math.Max(xa.Max, xmax)
+		ya.Min = math.Min(ya.Min, ymin)
+		ya.Max = math.Max(ya.Max, ymax)
+	}
+	p.plotters = append(p.plotters, d)
+	p.axes = append(p.axes, axes)
+}
+
+// autoRange recomputes the ranges of the axes for which
+// AutoRange is set from the data ranges of the plotters.
+func (p *Plot) autoRange() {
+	var auto bool
+	for _, a := range []*Axis{&p.X, &p.Y, &p.X2, &p.Y2} {
+		if a.AutoRange {
+			a.Min, a.Max = math.Inf(1), math.Inf(-1)
+			auto = true
+		}
+	}
+	if !auto {
+		return
+	}
+	for i, d := range p.plotters {
+		x, ok := d.(DataRanger)
+		if !ok {
+			continue
+		}
+		xa, ya := p.axesOf(p.axes[i])
+		if !xa.AutoRange && !ya.AutoRange {
+			continue
+		}
+		xmin, xmax, ymin, ymax := x.DataRange()
+		if xa.AutoRange {
+			xa.Min = math.Min(xa.Min, xmin)
+			xa.Max = math.Max(xa.Max, xmax)
+		}
+		if ya.AutoRange {
+			ya.Min = math.Min(ya.Min, ymin)
+			ya.Max = math.Max(ya.Max, ymax)
 		}
 	}
+}
 
-	p.plotters = append(p.plotters, ps...)
+// axesOf returns the horizontal and vertical axes
+// of the given pair.
+func (p *Plot) axesOf(axes AxisPair) (x, y *Axis) {
+	x, y = &p.X, &p.Y
+	if axes&X2Y != 0 {
+		x = &p.X2
+	}
+	if axes&XY2 != 0 {
+		y = &p.Y2
+	}
+	return x, y
+}
+
+// view returns the plot as seen by a Plotter bound to
+// the given axes: a shallow copy of the plot whose X
+// and Y axes are the axes of the pair.  Plotters drawn
+// with the view can use its Transforms and its X and Y
+// axes' Norm methods without knowledge of the binding.
+func (p *Plot) view(axes AxisPair) *Plot {
+	if axes == XY {
+		return p
+	}
+	v := *p
+	x, y := p.axesOf(axes)
+	v.X, v.Y = *x, *y
+	return &v
+}
+
+// hasRange returns whether the axis has been given a range,
+// either explicitly or by the data of a Plotter.
+func (a *Axis) hasRange() bool {
+	return !math.IsInf(a.Min, 1) || !math.IsInf(a.Max, -1)
+}
+
+// secondaryAxes returns the secondary axes of the plot,
+// ready for drawing, and whether each of them is drawn.
+func (p *Plot) secondaryAxes() (x2 horizontalAxis, y2 verticalAxis, hasX2, hasY2 bool) {
+	if hasX2 = p.X2.hasRange(); hasX2 {
+		p.X2.sanitizeRange()
+	}
+	if hasY2 = p.Y2.hasRange(); hasY2 {
+		p.Y2.sanitizeRange()
+	}
+	return horizontalAxis{p.X2}, verticalAxis{p.Y2}, hasX2, hasY2
 }
 
 // Draw draws a plot to a draw.Canvas.
@@ -152,23 +309,62 @@ func (p *Plot) Draw(c draw.Canvas) {
 		c.Max.Y -= p.Title.Height(p.Title.Text) - p.Title.Font.Extents().Descent
 		c.Max.Y -= p.Title.Padding
 	}
+	c, legendC := p.Legend.reserve(c)
+	c, barC := p.ColorBar.reserve(c)
 
+	p.autoRange()
 	p.X.sanitizeRange()
 	x := horizontalAxis{p.X}
 	p.Y.sanitizeRange()
 	y := verticalAxis{p.Y}
+	x2, y2, hasX2, hasY2 := p.secondaryAxes()
+
+	var x2height, y2width vg.Length
+	if hasX2 {
+		x2height = x2.size()
+	}
+	if hasY2 {
+		y2width = y2.size()
+	}
 
 	ywidth := y.size()
-	x.draw(padX(p, draw.Crop(c, ywidth, 0, 0, 0)))
+	x.draw(padX(p, draw.Crop(c, ywidth, -y2width, 0, -x2height)))
 	xheight := x.size()
-	y.draw(padY(p, draw.Crop(c, 0, 0, xheight, 0)))
+	y.draw(padY(p, draw.Crop(c, 0, -y2width, xheight, -x2height)))
+	if hasX2 {
+		top := topAxis{x2}
+		top.draw(padX(p, draw.Crop(c, ywidth, -y2width, xheight, 0)))
+	}
+	if hasY2 {
+		right := rightAxis{y2}
+		right.draw(padY(p, draw.Crop(c, c.Size().X-y2width, 0, xheight, -x2height)))
+	}
 
-	dataC := padY(p, padX(p, draw.Crop(c, ywidth, 0, xheight, 0)))
-	for _, data := range p.plotters {
-		data.Plot(dataC, p)
+	dataC := padY(p, padX(p, draw.Crop(c, ywidth, -y2width, xheight, -x2height)))
+	annotate := dataC.Annotates()
+	for i, data := range p.plotters {
+		if annotate {
+			dataC.BeginGroup(p.annotation(i))
+		}
+		data.Plot(dataC, p.view(p.axes[i]))
+		if annotate {
+			dataC.EndGroup()
+		}
 	}
 
-	p.Legend.draw(draw.Crop(draw.Crop(c, ywidth, 0, 0, 0), 0, 0, xheight, 0))
+	p.ColorBar.draw(barC, dataC)
+	p.drawLegend(draw.Crop(c, ywidth, -y2width, xheight, -x2height), dataC, legendC)
+}
+
+// annotation returns the annotation of the group of
+// elements drawn by the ith plotter.  The title of the
+// group is the text of the plotter's legend entry.
+func (p *Plot) annotation(i int) vg.Annotation {
+	return vg.Annotation{
+		ID:    fmt.Sprintf("plotter%d", i),
+		Class: "plotter",
+		Title: p.Legend.nameOf(p.plotters[i]),
+	}
 }
 
 // DataCanvas returns a new draw.Canvas that
@@ -179,11 +375,22 @@ func (p *Plot) DataCanvas(da draw.Canvas) draw.Canvas {
 		da.Max.Y -= p.Title.Height(p.Title.Text) - p.Title.Font.Extents().Descent
 		da.Max.Y -= p.Title.Padding
 	}
+	da, _ = p.Legend.reserve(da)
+	da, _ = p.ColorBar.reserve(da)
+	p.autoRange()
 	p.X.sanitizeRange()
 	x := horizontalAxis{p.X}
 	p.Y.sanitizeRange()
 	y := verticalAxis{p.Y}
-	return padY(p, padX(p, draw.Crop(da, y.size(), x.size(), 0, 0)))
+	x2, y2, hasX2, hasY2 := p.secondaryAxes()
+	var x2height, y2width vg.Length
+	if hasX2 {
+		x2height = x2.size()
+	}
+	if hasY2 {
+		y2width = y2.size()
+	}
+	return padY(p, padX(p, draw.Crop(da, y.size(), -y2width, x.size(), -x2height)))
 }
 
 // DrawGlyphBoxes draws red outlines around the plot's
@@ -204,6 +411,10 @@ func padX(p *Plot, c draw.Canvas) draw.Canvas {
 	l := leftMost(&c, glyphs)
 	xAxis := horizontalAxis{p.X}
 	glyphs = append(glyphs, xAxis.GlyphBoxes(p)...)
+	if p.X2.hasRange() {
+		x2Axis := topAxis{horizontalAxis{p.X2}}
+		glyphs = append(glyphs, x2Axis.GlyphBoxes(p)...)
+	}
 	r := rightMost(&c, glyphs)
 
 	minx := c.Min.X - l.Min.X
@@ -260,6 +471,10 @@ func padY(p *Plot, c draw.Canvas) draw.Canvas {
 	b := bottomMost(&c, glyphs)
 	yAxis := verticalAxis{p.Y}
 	glyphs = append(glyphs, yAxis.GlyphBoxes(p)...)
+	if p.Y2.hasRange() {
+		y2Axis := rightAxis{verticalAxis{p.Y2}}
+		glyphs = append(glyphs, y2Axis.GlyphBoxes(p)...)
+	}
 	t := topMost(&c, glyphs)
 
 	miny := c.Min.Y - b.Min.Y
@@ -313,12 +528,23 @@ func bottomMost(c *draw.Canvas, boxes []GlyphBox) GlyphBox {
 // from the x and y data coordinate system to
 // the draw coordinate system of the given
 // draw area.
+//
+// The *Plot passed to the Plot method of a Plotter
+// that is bound to secondary axes has those axes as
+// its X and Y, so the Plotter's Transforms honour
+// the binding.
 func (p *Plot) Transforms(c *draw.Canvas) (x, y func(float64) vg.Length) {
 	x = func(x float64) vg.Length { return c.X(p.X.Norm(x)) }
 	y = func(y float64) vg.Length { return c.Y(p.Y.Norm(y)) }
 	return
 }
 
+// TransformsOn is like Transforms but for the
+// given pair of axes.
+func (p *Plot) TransformsOn(axes AxisPair, c *draw.Canvas) (x, y func(float64) vg.Length) {
+	return p.view(axes).Transforms(c)
+}
+
 // GlyphBoxer wraps the GlyphBoxes method.
 // It should be implemented by things that meet
 // the Plotter interface that draw glyphs so that
@@ -364,12 +590,12 @@ type GlyphBox struct {
 // GlyphBoxes returns the GlyphBoxes for all plot
 // data that meet the GlyphBoxer interface.
 func (p *Plot) GlyphBoxes(*Plot) (boxes []GlyphBox) {
-	for _, d := range p.plotters {
+	for i, d := range p.plotters {
 		gb, ok := d.(GlyphBoxer)
 		if !ok {
 			continue
 		}
-		for _, b := range gb.GlyphBoxes(p) {
+		for _, b := range gb.GlyphBoxes(p.view(p.axes[i])) {
 			if b.Size().X > 0 && (b.X < 0 || b.X > 1) {
 				continue
 			}
@@ -455,7 +681,13 @@ func (p *Plot) WriterTo(w, h vg.Length, format string) (io.WriterTo, error) {
 // Supported extensions are:
 //
 //  .eps, .jpg, .jpeg, .pdf, .png, .svg, .tif and .tiff.
-func (p *Plot) Save(w, h vg.Length, file string) (err error) {
+func (p *Plot) Save(w, h vg.Length, file string) error {
+	return save(w, h, file, p.WriterTo)
+}
+
+// save saves the output of writerTo to an image file.
+// The file format is determined by the extension.
+func save(w, h vg.Length, file string, writerTo func(w, h vg.Length, format string) (io.WriterTo, error)) (err error) {
 	f, err := os.Create(file)
 	if err != nil {
 		return err
@@ -471,7 +703,7 @@ func (p *Plot) Save(w, h vg.Length, file string) (err error) {
 	if len(format) != 0 {
 		format = format[1:]
 	}
-	c, err := p.WriterTo(w, h, format)
+	c, err := writerTo(w, h, format)
 	if err != nil {
 		return err
 	}
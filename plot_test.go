@@ -211,3 +211,202 @@ func formatActions(actions []recorder.Action) string {
 	}
 	return buf.String()
 }
+
+// axesRecorder is a Plotter that records the ranges of the
+// axes it is drawn against.
+type axesRecorder struct {
+	xmin, xmax, ymin, ymax float64
+	axes                   plot.AxisPair
+
+	gotX, gotY [2]float64
+}
+
+func (r *axesRecorder) Plot(_ draw.Canvas, plt *plot.Plot) {
+	r.gotX = [2]float64{plt.X.Min, plt.X.Max}
+	r.gotY = [2]float64{plt.Y.Min, plt.Y.Max}
+}
+
+func (r *axesRecorder) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return r.xmin, r.xmax, r.ymin, r.ymax
+}
+
+func (r *axesRecorder) AxisPair() plot.AxisPair { return r.axes }
+
+func TestSecondaryAxes(t *testing.T) {
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("failed to create plot: %v", err)
+	}
+	primary := &axesRecorder{xmin: 0, xmax: 10, ymin: 0, ymax: 1}
+	secondary := &axesRecorder{xmin: 0, xmax: 10, ymin: 100, ymax: 500, axes: plot.XY2}
+	top := &axesRecorder{xmin: -5, xmax: 5, ymin: 0, ymax: 1}
+	p.Add(primary, secondary)
+	p.AddOn(plot.X2Y, top)
+
+	c := draw.NewCanvas(new(recorder.Canvas), 300, 200)
+	before := p.DataCanvas(c)
+	p.Draw(c)
+
+	for _, test := range []struct {
+		name  string
+		r     *axesRecorder
+		wantX [2]float64
+		wantY [2]float64
+	}{
+		{name: "primary", r: primary, wantX: [2]float64{0, 10}, wantY: [2]float64{0, 1}},
+		{name: "XY2", r: secondary, wantX: [2]float64{0, 10}, wantY: [2]float64{100, 500}},
+		{name: "X2Y", r: top, wantX: [2]float64{-5, 5}, wantY: [2]float64{0, 1}},
+	} {
+		if test.r.gotX != test.wantX {
+			t.Errorf("unexpected X range for %s plotter: got:%v want:%v", test.name, test.r.gotX, test.wantX)
+		}
+		if test.r.gotY != test.wantY {
+			t.Errorf("unexpected Y range for %s plotter: got:%v want:%v", test.name, test.r.gotY, test.wantY)
+		}
+	}
+
+	p2, err := plot.New()
+	if err != nil {
+		t.Fatalf("failed to create plot: %v", err)
+	}
+	p2.Add(&axesRecorder{xmin: 0, xmax: 10, ymin: 0, ymax: 1})
+	single := p2.DataCanvas(c)
+	if before.Max.X >= single.Max.X {
+		t.Errorf("data canvas not narrowed by secondary Y axis: got max x:%v want less than:%v", before.Max.X, single.Max.X)
+	}
+	if before.Max.Y >= single.Max.Y {
+		t.Errorf("data canvas not lowered by secondary X axis: got max y:%v want less than:%v", before.Max.Y, single.Max.Y)
+	}
+}
+
+// thumbRecorder is a Thumbnailer that records the
+// canvas of its legend thumbnail.
+type thumbRecorder struct {
+	c draw.Canvas
+}
+
+func (r *thumbRecorder) Thumbnail(c *draw.Canvas) { r.c = *c }
+
+func TestLegendPlacement(t *testing.T) {
+	for _, test := range []struct {
+		placement plot.LegendPlacement
+		top, left bool
+
+		// check reports whether the thumbnail was
+		// drawn in the expected place.
+		check func(thumb, data draw.Canvas) bool
+		want  string
+	}{
+		{
+			placement: plot.LegendInside, top: true,
+			check: func(thumb, data draw.Canvas) bool { return thumb.Max.X == data.Max.X && thumb.Max.Y > data.Center().Y },
+			want:  "top right corner",
+		},
+		{
+			// The line crosses the top right and bottom left corners.
+			placement: plot.LegendAuto, top: true,
+			check: func(thumb, data draw.Canvas) bool { return thumb.Min.X == data.Min.X && thumb.Max.Y > data.Center().Y },
+			want:  "top left corner",
+		},
+		{
+			placement: plot.LegendAuto, left: true,
+			check: func(thumb, data draw.Canvas) bool { return thumb.Max.X == data.Max.X && thumb.Max.Y < data.Center().Y },
+			want:  "bottom right corner",
+		},
+		{
+			placement: plot.LegendRight,
+			check:     func(thumb, data draw.Canvas) bool { return thumb.Min.X > data.Max.X },
+			want:      "right of data",
+		},
+		{
+			placement: plot.LegendBelow,
+			check:     func(thumb, data draw.Canvas) bool { return thumb.Max.Y < data.Min.Y },
+			want:      "below data",
+		},
+	} {
+		p, err := plot.New()
+		if err != nil {
+			t.Fatalf("failed to create plot: %v", err)
+		}
+		l, err := plotter.NewLine(plotter.XYs{{0, 0}, {1, 1}})
+		if err != nil {
+			t.Fatalf("failed to create line: %v", err)
+		}
+		rec := &canvasRecorder{xmin: 0, xmax: 1, ymin: 0, ymax: 1}
+		p.Add(l, rec)
+		thumb := &thumbRecorder{}
+		p.Legend.Add("line", thumb)
+		p.Legend.Placement = test.placement
+		p.Legend.Top = test.top
+		p.Legend.Left = test.left
+
+		c := draw.NewCanvas(new(recorder.Canvas), 300, 200)
+		data := p.DataCanvas(c)
+		p.Draw(c)
+		if rec.c.Rectangle != data.Rectangle {
+			t.Errorf("unexpected data canvas for placement %v: got:%v want:%v", test.placement, rec.c.Rectangle, data.Rectangle)
+		}
+		if !test.check(thumb.c, data) {
+			t.Errorf("unexpected legend thumbnail position for placement %v: got:%v want %s of %v", test.placement, thumb.c.Rectangle, test.want, data.Rectangle)
+		}
+	}
+}
+
+func TestLegendLayout(t *testing.T) {
+	font, err := vg.MakeFont(plot.DefaultFont, 10.822510822510822) // This font size gives an entry height of 10.
+	if err != nil {
+		t.Fatalf("failed to create font: %v", err)
+	}
+	l := plot.Legend{
+		ThumbnailWidth:  vg.Points(20),
+		TextStyle:       draw.TextStyle{Font: font},
+		BackgroundColor: color.White,
+		Border:          draw.LineStyle{Color: color.Black, Width: 1},
+		Inset:           5,
+		Columns:         2,
+		Order:           plot.LegendReversed,
+		Left:            true,
+		Top:             true,
+	}
+	l.Title.Text = "Title"
+	l.Title.TextStyle = draw.TextStyle{Font: font}
+	thumbs := make(map[string]*thumbRecorder)
+	for _, n := range []string{"A", "B", "C", "D"} {
+		thumbs[n] = &thumbRecorder{}
+		l.Add(n, thumbs[n])
+	}
+
+	var r recorder.Canvas
+	c := draw.NewCanvas(&r, 100, 100)
+	l.Draw(c)
+
+	// The background and border surround the title and
+	// the two rows of entries.
+	fill, ok := r.Actions[1].(*recorder.Fill)
+	if !ok {
+		t.Fatalf("unexpected second action: got:%T want:*recorder.Fill", r.Actions[1])
+	}
+	if min, max := fill.Path[0], fill.Path[2]; min.X != 0 || min.Y != 60 || max.Y != 100 {
+		t.Errorf("unexpected legend background: got:%v", fill.Path)
+	}
+	if _, ok := r.Actions[5].(*recorder.Stroke); !ok {
+		t.Errorf("unexpected sixth action: got:%T want:*recorder.Stroke", r.Actions[5])
+	}
+
+	// Entries are reversed and fill the first column first.
+	for _, test := range []struct {
+		name string
+		want draw.Point
+	}{
+		{name: "D", want: draw.Point{X: 5, Y: 75}},
+		{name: "C", want: draw.Point{X: 5, Y: 65}},
+	} {
+		if got := thumbs[test.name].c.Min; got != test.want {
+			t.Errorf("unexpected thumbnail position for entry %s: got:%v want:%v", test.name, got, test.want)
+		}
+	}
+	b, a := thumbs["B"].c.Min, thumbs["A"].c.Min
+	if b.X <= thumbs["D"].c.Max.X || b.Y != 75 || a.X != b.X || a.Y != 65 {
+		t.Errorf("unexpected second column thumbnail positions: got B:%v A:%v", b, a)
+	}
+}
@@ -0,0 +1,450 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package plothttp provides an http.Handler that serves plots
+// rendered on request.
+//
+// The format of a plot is given by the extension of the
+// requested path, such as /latency.svg, or, for a path
+// without an extension, by the Accept header of the request.
+// The size of the plot may be given by the width and height
+// query parameters, as a number of points or as a number with
+// one of the units pt, in, cm or mm, such as width=6in. The
+// resolution of raster formats may be given by the dpi query
+// parameter. Requests beyond the size limits of the Handler
+// are answered with 400 Bad Request.
+package plothttp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gonum/plot"
+	_ "github.com/gonum/plot/gob"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/vgimg"
+)
+
+// contentTypes are the content types of the
+// supported formats.
+var contentTypes = map[string]string{
+	"eps":  "application/postscript",
+	"jpg":  "image/jpeg",
+	"jpeg": "image/jpeg",
+	"pdf":  "application/pdf",
+	"png":  "image/png",
+	"svg":  "image/svg+xml",
+	"tif":  "image/tiff",
+	"tiff": "image/tiff",
+}
+
+// formats are the formats chosen for content
+// types accepted by a request.
+var formats = map[string]string{
+	"application/postscript": "eps",
+	"image/jpeg":             "jpg",
+	"application/pdf":        "pdf",
+	"image/png":              "png",
+	"image/svg+xml":          "svg",
+	"image/tiff":             "tif",
+}
+
+// Handler is an http.Handler that serves a plot,
+// rendered on request.
+//
+// Responses have an ETag, and a request whose If-None-Match
+// header matches the ETag of its response is answered with
+// 304 Not Modified. The ETag is derived from the Version of
+// the plot and the format, size and resolution requested.
+// A copy of the plot is drawn, so serving a plot does not
+// change it.
+type Handler struct {
+	// Plot returns the plot that is served
+	// for the request.
+	Plot func(*http.Request) (*plot.Plot, error)
+
+	// Version, if not nil, returns a string that changes
+	// whenever the plot served for the request changes.
+	// If Version is nil, the version of a plot is the hash
+	// of its gob encoding, or, if the plot cannot be gob
+	// encoded, of the rendered plot. Plots that depend on
+	// state that is not gob encoded, such as the function
+	// of a plotter.Function, must have a Version, so that
+	// their changes are seen.
+	//
+	// The plot is gob encoded on every request, so Version
+	// should also be set for plots holding much data, for
+	// which the encoding is costly.
+	Version func(*http.Request, *plot.Plot) string
+
+	// Width and Height are the size of plots
+	// for which no size is requested.
+	Width, Height vg.Length
+
+	// MaxSize is the greatest width and height that
+	// may be requested. If MaxSize is zero, there is
+	// no limit.
+	MaxSize vg.Length
+
+	// MaxDPI is the greatest resolution that may be
+	// requested. If MaxDPI is zero, there is no limit.
+	MaxDPI int
+
+	// MaxPixels is the greatest number of pixels of
+	// a plot in a raster format that may be requested.
+	// If MaxPixels is zero, there is no limit.
+	MaxPixels int
+
+	// Format is the format of plots requested by
+	// a path without an extension, when the Accept
+	// header of the request allows any format.
+	Format string
+
+	// CacheSize is the number of rendered plots
+	// that are kept to answer repeated requests.
+	CacheSize int
+
+	mu    sync.Mutex
+	cache []rendered
+}
+
+// rendered is a rendered plot.
+type rendered struct {
+	etag string
+	body []byte
+}
+
+// New returns a new handler serving the plot p in
+// PNG format by default.
+func New(p *plot.Plot) *Handler {
+	return NewFunc(func(*http.Request) (*plot.Plot, error) { return p, nil })
+}
+
+// NewFunc returns a new handler serving the plots
+// returned by f in PNG format by default.
+func NewFunc(f func(*http.Request) (*plot.Plot, error)) *Handler {
+	return &Handler{
+		Plot:      f,
+		Width:     4 * vg.Inch,
+		Height:    4 * vg.Inch,
+		MaxSize:   100 * vg.Inch,
+		MaxDPI:    1200,
+		MaxPixels: 4096 * 4096,
+		Format:    "png",
+		CacheSize: 16,
+	}
+}
+
+// options are the rendering options of a request.
+type options struct {
+	format string
+	width  vg.Length
+	height vg.Length
+	dpi    int
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	opts, status, err := h.options(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	p, err := h.Plot(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var body []byte
+	var etag string
+	version := h.version(r, p)
+	if version != "" {
+		etag = tag(version, opts)
+		if matches(r.Header.Get("If-None-Match"), etag) {
+			h.setHeader(w, opts, etag)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		body = h.cached(etag)
+	}
+	if body == nil {
+		body, err = render(p, opts)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if version == "" {
+			etag = tag(hash(body), opts)
+		} else {
+			h.store(etag, body)
+		}
+	}
+
+	// ServeContent answers a request whose If-None-Match
+	// header matches the ETag with Not Modified.
+	h.setHeader(w, opts, etag)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+}
+
+// setHeader sets the header of a response with
+// the given rendering options and ETag.
+func (h *Handler) setHeader(w http.ResponseWriter, opts options, etag string) {
+	hdr := w.Header()
+	hdr.Set("Content-Type", contentTypes[opts.format])
+	hdr.Set("ETag", etag)
+	hdr.Set("Cache-Control", "no-cache")
+	hdr.Add("Vary", "Accept")
+}
+
+// version returns the version of the plot p served for
+// the request r, or the empty string if it is not known
+// without rendering the plot.
+func (h *Handler) version(r *http.Request, p *plot.Plot) string {
+	if h.Version != nil {
+		return h.Version(r, p)
+	}
+	var buf bytes.Buffer
+	if gob.NewEncoder(&buf).Encode(p) != nil {
+		return ""
+	}
+	return hash(buf.Bytes())
+}
+
+// cached returns the cached rendered plot with
+// the given ETag, or nil if it is not cached.
+func (h *Handler) cached(etag string) []byte {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, c := range h.cache {
+		if c.etag == etag {
+			return c.body
+		}
+	}
+	return nil
+}
+
+// store adds the rendered plot with the given ETag to
+// the cache, removing the oldest plots from a full cache.
+func (h *Handler) store(etag string, body []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.CacheSize <= 0 {
+		h.cache = nil
+		return
+	}
+	if n := len(h.cache) - h.CacheSize + 1; n > 0 {
+		h.cache = append(h.cache[:0], h.cache[n:]...)
+	}
+	h.cache = append(h.cache, rendered{etag: etag, body: body})
+}
+
+// render returns a copy of the plot p rendered
+// with the given options.
+func render(p *plot.Plot, opts options) ([]byte, error) {
+	c, err := canvas(opts)
+	if err != nil {
+		return nil, err
+	}
+	cp := *p
+	cp.Draw(draw.New(c))
+	var buf bytes.Buffer
+	_, err = c.WriteTo(&buf)
+	return buf.Bytes(), err
+}
+
+// canvas returns a new canvas for the rendering options.
+func canvas(opts options) (vg.CanvasWriterTo, error) {
+	if opts.dpi == 0 {
+		return draw.NewFormattedCanvas(opts.width, opts.height, opts.format)
+	}
+	switch opts.format {
+	case "jpg", "jpeg":
+		return vgimg.JpegCanvas{Canvas: vgimg.NewWith(vgimg.UseWH(opts.width, opts.height), vgimg.UseDPI(opts.dpi))}, nil
+	case "png":
+		return vgimg.PngCanvas{Canvas: vgimg.NewWith(vgimg.UseWH(opts.width, opts.height), vgimg.UseDPI(opts.dpi))}, nil
+	case "tif", "tiff":
+		return vgimg.TiffCanvas{Canvas: vgimg.NewWith(vgimg.UseWH(opts.width, opts.height), vgimg.UseDPI(opts.dpi))}, nil
+	}
+	return draw.NewFormattedCanvas(opts.width, opts.height, opts.format)
+}
+
+// options returns the rendering options of the request, or
+// an error and the status of the response reporting it.
+func (h *Handler) options(r *http.Request) (opts options, status int, err error) {
+	opts = options{width: h.Width, height: h.Height}
+	if ext := path.Ext(r.URL.Path); ext != "" {
+		opts.format = strings.ToLower(ext[1:])
+		if _, ok := contentTypes[opts.format]; !ok {
+			return opts, http.StatusNotFound, fmt.Errorf("unsupported format: %q", opts.format)
+		}
+	} else {
+		opts.format = negotiate(r.Header.Get("Accept"), h.Format)
+		if opts.format == "" {
+			return opts, http.StatusNotAcceptable, fmt.Errorf("no acceptable format")
+		}
+	}
+
+	q := r.URL.Query()
+	for _, dim := range []struct {
+		name string
+		len  *vg.Length
+	}{
+		{name: "width", len: &opts.width},
+		{name: "height", len: &opts.height},
+	} {
+		s := q.Get(dim.name)
+		if s == "" {
+			continue
+		}
+		l, err := parseLength(s)
+		if err != nil || l <= 0 || (h.MaxSize > 0 && l > h.MaxSize) {
+			return opts, http.StatusBadRequest, fmt.Errorf("invalid %s: %q", dim.name, s)
+		}
+		*dim.len = l
+	}
+	if s := q.Get("dpi"); s != "" {
+		dpi, err := strconv.Atoi(s)
+		if err != nil || dpi <= 0 || (h.MaxDPI > 0 && dpi > h.MaxDPI) {
+			return opts, http.StatusBadRequest, fmt.Errorf("invalid dpi: %q", s)
+		}
+		opts.dpi = dpi
+	}
+	if h.MaxPixels > 0 && isRaster(opts.format) && pixels(opts) > float64(h.MaxPixels) {
+		return opts, http.StatusBadRequest, fmt.Errorf("plot too large: more than %d pixels", h.MaxPixels)
+	}
+	return opts, http.StatusOK, nil
+}
+
+// pixels returns the number of pixels of a raster
+// plot rendered with the given options.
+func pixels(opts options) float64 {
+	dpi := opts.dpi
+	if dpi == 0 {
+		dpi = vgimg.DefaultDPI
+	}
+	return opts.width.Dots(float64(dpi)) * opts.height.Dots(float64(dpi))
+}
+
+// isRaster returns whether format is a raster image format.
+func isRaster(format string) bool {
+	switch format {
+	case "jpg", "jpeg", "png", "tif", "tiff":
+		return true
+	}
+	return false
+}
+
+// units are the units of lengths in requests.
+var units = map[string]vg.Length{
+	"":   1,
+	"pt": 1,
+	"in": vg.Inch,
+	"cm": vg.Centimeter,
+	"mm": vg.Millimeter,
+}
+
+// parseLength returns the length given by s, a number
+// followed by an optional unit.
+func parseLength(s string) (vg.Length, error) {
+	i := strings.LastIndexAny(s, "0123456789.") + 1
+	u, ok := units[s[i:]]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit: %q", s[i:])
+	}
+	v, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, err
+	}
+	return vg.Length(v) * u, nil
+}
+
+// negotiate returns the format of the most preferred of the
+// content types accepted by the Accept header, or def if the
+// header is empty or any format is accepted. It returns the
+// empty string if no supported format is accepted.
+func negotiate(accept, def string) string {
+	if accept == "" {
+		return def
+	}
+	type choice struct {
+		format string
+		q      float64
+	}
+	var best choice
+	for _, s := range strings.Split(accept, ",") {
+		typ, params, err := mime.ParseMediaType(strings.TrimSpace(s))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		var f string
+		switch typ {
+		case "*/*":
+			f = def
+		case "image/*":
+			f = def
+			if !strings.HasPrefix(contentTypes[def], "image/") {
+				f = "png"
+			}
+		default:
+			f = formats[typ]
+		}
+		if f != "" && q > best.q {
+			best = choice{format: f, q: q}
+		}
+	}
+	return best.format
+}
+
+// matches returns whether the If-None-Match header
+// value inm matches the ETag etag.
+func matches(inm, etag string) bool {
+	for _, t := range strings.Split(inm, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == "*" || t == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// hash returns the hex encoded SHA-256 hash of b.
+func hash(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// tag returns the ETag of a plot with the given
+// version rendered with the given options.
+func tag(version string, opts options) string {
+	key := fmt.Sprintf("%s\x00%s\x00%v\x00%v\x00%d", version, opts.format, opts.width, opts.height, opts.dpi)
+	return `"` + hash([]byte(key))[:32] + `"`
+}
@@ -0,0 +1,180 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plothttp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/plotter"
+	"github.com/gonum/plot/vg"
+)
+
+func newPlot(t *testing.T) *plot.Plot {
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l, err := plotter.NewLine(plotter.XYs{{0, 0}, {1, 2}, {2, 1}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Add(l)
+	return p
+}
+
+func get(t *testing.T, h http.Handler, url string, header map[string]string) *httptest.ResponseRecorder {
+	r, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandlerFormat(t *testing.T) {
+	h := New(newPlot(t))
+	for _, test := range []struct {
+		url    string
+		accept string
+		status int
+		typ    string
+	}{
+		{url: "/plot.svg", status: http.StatusOK, typ: "image/svg+xml"},
+		{url: "/plot.PNG", status: http.StatusOK, typ: "image/png"},
+		{url: "/plot.eps", accept: "image/png", status: http.StatusOK, typ: "application/postscript"},
+		{url: "/plot", status: http.StatusOK, typ: "image/png"},
+		{url: "/plot", accept: "application/pdf", status: http.StatusOK, typ: "application/pdf"},
+		{url: "/plot", accept: "image/png;q=0.5, image/svg+xml", status: http.StatusOK, typ: "image/svg+xml"},
+		{url: "/plot", accept: "text/html, */*;q=0.1", status: http.StatusOK, typ: "image/png"},
+		{url: "/plot", accept: "text/html", status: http.StatusNotAcceptable},
+		{url: "/plot.gif", status: http.StatusNotFound},
+	} {
+		w := get(t, h, test.url, map[string]string{"Accept": test.accept})
+		if w.Code != test.status {
+			t.Errorf("%s accepting %q: unexpected status: got:%d want:%d", test.url, test.accept, w.Code, test.status)
+			continue
+		}
+		if test.status != http.StatusOK {
+			continue
+		}
+		if got := w.Header().Get("Content-Type"); got != test.typ {
+			t.Errorf("%s accepting %q: unexpected content type: got:%q want:%q", test.url, test.accept, got, test.typ)
+		}
+	}
+}
+
+func TestHandlerSize(t *testing.T) {
+	h := New(newPlot(t))
+	w := get(t, h, "/plot.svg?width=2in&height=72", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: got:%d want:%d", w.Code, http.StatusOK)
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte(`width="2in" height="1in"`)) {
+		t.Errorf("unexpected size of plot:\n%s", w.Body.Bytes()[:200])
+	}
+
+	for _, url := range []string{
+		"/plot.svg?width=-1",
+		"/plot.svg?height=2ft",
+		"/plot.svg?width=1000in",
+		"/plot.png?dpi=0",
+		"/plot.png?dpi=x",
+		"/plot.png?width=100in&height=100in&dpi=1200",
+		"/plot.png?width=100in&height=100in",
+	} {
+		if w := get(t, h, url, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: unexpected status: got:%d want:%d", url, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	if w := get(t, h, "/plot.svg?width=100in&height=100in", nil); w.Code != http.StatusOK {
+		t.Errorf("unexpected status for large vector plot: got:%d want:%d", w.Code, http.StatusOK)
+	}
+
+	small := get(t, h, "/plot.png?width=1in&height=1in&dpi=50", nil)
+	large := get(t, h, "/plot.png?width=1in&height=1in&dpi=200", nil)
+	if small.Code != http.StatusOK || large.Code != http.StatusOK {
+		t.Fatalf("unexpected status: got:%d and %d want:%d", small.Code, large.Code, http.StatusOK)
+	}
+	if small.Body.Len() >= large.Body.Len() {
+		t.Errorf("unexpected image sizes: %d bytes at 50 dpi and %d bytes at 200 dpi", small.Body.Len(), large.Body.Len())
+	}
+}
+
+func TestHandlerETag(t *testing.T) {
+	p := newPlot(t)
+	var calls int
+	h := NewFunc(func(*http.Request) (*plot.Plot, error) {
+		calls++
+		return p, nil
+	})
+
+	first := get(t, h, "/plot.svg", nil)
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+	if p.X.Min != 0 || p.X.Max != 2 {
+		t.Errorf("plot changed by serving: X range [%v, %v]", p.X.Min, p.X.Max)
+	}
+
+	w := get(t, h, "/plot.svg", map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("unexpected response to matching request: status:%d body length:%d", w.Code, w.Body.Len())
+	}
+	w = get(t, h, "/plot.svg", nil)
+	if w.Header().Get("ETag") != etag || !bytes.Equal(w.Body.Bytes(), first.Body.Bytes()) {
+		t.Errorf("unexpected response to repeated request")
+	}
+	if w := get(t, h, "/plot.svg?width=3in", map[string]string{"If-None-Match": etag}); w.Code != http.StatusOK {
+		t.Errorf("unexpected status for other size: got:%d want:%d", w.Code, http.StatusOK)
+	}
+
+	p.Title.Text = "changed"
+	w = get(t, h, "/plot.svg", map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("changed plot not served: status:%d", w.Code)
+	}
+	if calls != 5 {
+		t.Errorf("unexpected number of plot requests: got:%d want:5", calls)
+	}
+
+	// A Version replaces the gob encoding of the plot.
+	h.Version = func(*http.Request, *plot.Plot) string { return "v1" }
+	etag = get(t, h, "/plot.svg", nil).Header().Get("ETag")
+	p.Title.Text = "changed again"
+	if w := get(t, h, "/plot.svg", map[string]string{"If-None-Match": etag}); w.Code != http.StatusNotModified {
+		t.Errorf("unexpected status for unchanged version: got:%d want:%d", w.Code, http.StatusNotModified)
+	}
+}
+
+func TestParseLength(t *testing.T) {
+	for _, test := range []struct {
+		s    string
+		want vg.Length
+		ok   bool
+	}{
+		{s: "72", want: vg.Inch, ok: true},
+		{s: "1.5in", want: 1.5 * vg.Inch, ok: true},
+		{s: "10pt", want: 10, ok: true},
+		{s: "2cm", want: 2 * vg.Centimeter, ok: true},
+		{s: "5mm", want: 5 * vg.Millimeter, ok: true},
+		{s: "in", ok: false},
+		{s: "3px", ok: false},
+	} {
+		got, err := parseLength(test.s)
+		if (err == nil) != test.ok || got != test.want {
+			t.Errorf("unexpected length for %q: got:%v err:%v want:%v", test.s, got, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plotspec
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gonum/plot/plotter"
+)
+
+// xys returns the X and Y data of line and scatter plotters.
+func (d *Data) xys(field, dir string) (plotter.XYs, error) {
+	err := d.only(field, "x", "y")
+	if err != nil {
+		return nil, err
+	}
+	var xs, ys []float64
+	if d.File != "" {
+		cols, err := d.read(field, dir, 2)
+		if err != nil {
+			return nil, err
+		}
+		xs, ys = cols[0], cols[1]
+	} else {
+		if len(d.X) == 0 {
+			return nil, errorf(field+".x", "no data")
+		}
+		if len(d.Y) != len(d.X) {
+			return nil, errorf(field+".y", "length %d does not match x length %d", len(d.Y), len(d.X))
+		}
+		xs, ys = d.X, d.Y
+	}
+	xys := make(plotter.XYs, len(xs))
+	for i := range xys {
+		xys[i].X = xs[i]
+		xys[i].Y = ys[i]
+	}
+	return xys, nil
+}
+
+// values returns the data of value plotters.
+func (d *Data) values(field, dir string) (plotter.Values, error) {
+	err := d.only(field, "values")
+	if err != nil {
+		return nil, err
+	}
+	if d.File != "" {
+		cols, err := d.read(field, dir, 1)
+		if err != nil {
+			return nil, err
+		}
+		return cols[0], nil
+	}
+	if len(d.Values) == 0 {
+		return nil, errorf(field+".values", "no data")
+	}
+	return d.Values, nil
+}
+
+// grid returns the data of heat maps.
+func (d *Data) grid(field, dir string) (*grid, error) {
+	err := d.only(field, "grid", "x", "y")
+	if err != nil {
+		return nil, err
+	}
+	var g grid
+	if d.File != "" {
+		cols, err := d.read(field, dir, 0)
+		if err != nil {
+			return nil, err
+		}
+		g.z = make([][]float64, len(cols[0]))
+		for r := range g.z {
+			g.z[r] = make([]float64, len(cols))
+			for c, col := range cols {
+				g.z[r][c] = col[r]
+			}
+		}
+	} else {
+		if len(d.Grid) == 0 {
+			return nil, errorf(field+".grid", "no data")
+		}
+		for r, row := range d.Grid {
+			if len(row) == 0 {
+				return nil, errorf(fmt.Sprintf("%s.grid[%d]", field, r), "empty row")
+			}
+			if len(row) != len(d.Grid[0]) {
+				return nil, errorf(fmt.Sprintf("%s.grid[%d]", field, r), "length %d does not match row 0 length %d", len(row), len(d.Grid[0]))
+			}
+		}
+		g.z = d.Grid
+	}
+	c, r := g.Dims()
+	if d.X != nil && len(d.X) != c {
+		return nil, errorf(field+".x", "length %d does not match %d grid columns", len(d.X), c)
+	}
+	if d.Y != nil && len(d.Y) != r {
+		return nil, errorf(field+".y", "length %d does not match %d grid rows", len(d.Y), r)
+	}
+	g.x, g.y = d.X, d.Y
+	return &g, nil
+}
+
+// only returns an error if any inline data other than the named
+// fields is given, or if inline data is given along with a file.
+func (d *Data) only(field string, names ...string) error {
+	given := []struct {
+		name string
+		ok   bool
+	}{
+		{"x", d.X != nil},
+		{"y", d.Y != nil},
+		{"values", d.Values != nil},
+		{"grid", d.Grid != nil},
+	}
+	for _, g := range given {
+		if !g.ok {
+			continue
+		}
+		allowed := false
+		for _, n := range names {
+			allowed = allowed || n == g.name
+		}
+		if !allowed {
+			return errorf(field+"."+g.name, "unexpected %s data", g.name)
+		}
+		if d.File == "" {
+			continue
+		}
+		// Heat map coordinates may accompany file data.
+		coords := (g.name == "x" || g.name == "y") && names[0] == "grid"
+		if !coords {
+			return errorf(field+"."+g.name, "inline data given with file %q", d.File)
+		}
+	}
+	if d.File == "" && d.Columns != nil {
+		return errorf(field+".columns", "columns given without file")
+	}
+	return nil
+}
+
+// read returns n columns of the data file. If n is zero
+// all of the selected columns are returned.
+func (d *Data) read(field, dir string, n int) ([][]float64, error) {
+	if n != 0 && d.Columns != nil && len(d.Columns) != n {
+		return nil, errorf(field+".columns", "%d columns given, want %d", len(d.Columns), n)
+	}
+	path := d.File
+	if dir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, &Error{Field: field + ".file", Err: err}
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.TrimLeadingSpace = true
+	recs, err := r.ReadAll()
+	if err != nil {
+		return nil, &Error{Field: field + ".file", Err: err}
+	}
+	if len(recs) < 2 {
+		return nil, errorf(field+".file", "%s: no data rows", d.File)
+	}
+	header := recs[0]
+
+	var idx []int
+	for i, name := range d.Columns {
+		j := -1
+		for k, h := range header {
+			if h == name {
+				j = k
+				break
+			}
+		}
+		if j < 0 {
+			return nil, errorf(fmt.Sprintf("%s.columns[%d]", field, i), "no column %q in %s", name, d.File)
+		}
+		idx = append(idx, j)
+	}
+	if len(idx) == 0 {
+		if n == 0 {
+			n = len(header)
+		}
+		for j := 0; j < n; j++ {
+			idx = append(idx, j)
+		}
+	}
+	if idx[len(idx)-1] >= len(header) {
+		return nil, errorf(field+".file", "%s: %d columns, want %d", d.File, len(header), n)
+	}
+
+	cols := make([][]float64, len(idx))
+	for i := range cols {
+		cols[i] = make([]float64, len(recs)-1)
+	}
+	for r, rec := range recs[1:] {
+		for i, j := range idx {
+			v, err := strconv.ParseFloat(rec[j], 64)
+			if err != nil {
+				return nil, errorf(field+".file", "%s:%d: column %q: %v", d.File, r+2, header[j], err.(*strconv.NumError).Err)
+			}
+			cols[i][r] = v
+		}
+	}
+	return cols, nil
+}
+
+// grid is a GridXYZ holding heat map data. The rows of z are
+// the grid rows. Nil x and y coordinates are the column and
+// row indices.
+type grid struct {
+	x, y []float64
+	z    [][]float64
+}
+
+var _ plotter.GridXYZ = (*grid)(nil)
+
+func (g *grid) Dims() (c, r int)   { return len(g.z[0]), len(g.z) }
+func (g *grid) Z(c, r int) float64 { return g.z[r][c] }
+func (g *grid) X(c int) float64 {
+	if g.x == nil {
+		return float64(c)
+	}
+	return g.x[c]
+}
+func (g *grid) Y(r int) float64 {
+	if g.y == nil {
+		return float64(r)
+	}
+	return g.y[r]
+}
@@ -0,0 +1,766 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package plotspec builds plots from declarative specifications.
+//
+// A specification describes the title, axes and legend of a plot
+// and the list of plotters to add to it. Specifications are decoded
+// from JSON or YAML, with the same field names in both. A Spec may
+// also be filled in directly and then built with its Build method.
+//
+// An example JSON specification is:
+//
+//	{
+//		"title": "Response time",
+//		"x": {"label": "Load", "scale": "log"},
+//		"y": {"label": "Latency (ms)", "min": 0},
+//		"legend": {"top": true},
+//		"plotters": [
+//			{"type": "line", "name": "p50", "data": {"file": "latency.csv", "columns": ["load", "p50"]}},
+//			{"type": "scatter", "name": "max", "color": "#c00", "data": {"x": [1, 10, 100], "y": [12, 20, 41]}}
+//		]
+//	}
+//
+// The same specification in YAML is:
+//
+//	title: Response time
+//	x: {label: Load, scale: log}
+//	y: {label: Latency (ms), min: 0}
+//	legend: {top: true}
+//	plotters:
+//	  - {type: line, name: p50, data: {file: latency.csv, columns: [load, p50]}}
+//	  - {type: scatter, name: max, color: "#c00", data: {x: [1, 10, 100], y: [12, 20, 41]}}
+package plotspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/palette"
+	"github.com/gonum/plot/palette/brewer"
+	"github.com/gonum/plot/plotter"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Spec is the specification of a plot.
+type Spec struct {
+	// Title is the title of the plot.
+	Title string `json:"title,omitempty" yaml:"title,omitempty"`
+
+	// X and Y are the specifications of the plot axes.
+	X Axis `json:"x,omitempty" yaml:"x,omitempty"`
+	Y Axis `json:"y,omitempty" yaml:"y,omitempty"`
+
+	// Legend specifies the placement of the legend.
+	Legend Legend `json:"legend,omitempty" yaml:"legend,omitempty"`
+
+	// Plotters is the list of plotters added to the plot,
+	// in drawing order.
+	Plotters []Plotter `json:"plotters" yaml:"plotters"`
+}
+
+// Axis is the specification of a plot axis.
+type Axis struct {
+	// Label is the axis label text.
+	Label string `json:"label,omitempty" yaml:"label,omitempty"`
+
+	// Min and Max, if not nil, override the range
+	// computed from the data.
+	Min *float64 `json:"min,omitempty" yaml:"min,omitempty"`
+	Max *float64 `json:"max,omitempty" yaml:"max,omitempty"`
+
+	// Scale is the axis scale: "linear" (the default),
+	// "log", "symlog", "sqrt", "logit" or "time". Time axis
+	// values are seconds since the Unix epoch.
+	Scale string `json:"scale,omitempty" yaml:"scale,omitempty"`
+
+	// Ticks specifies the axis tick marks. If Ticks is nil
+	// the ticker matching Scale is used.
+	Ticks *Ticks `json:"ticks,omitempty" yaml:"ticks,omitempty"`
+}
+
+// Ticks is the specification of the tick marks of an axis.
+type Ticks struct {
+	// Kind is the kind of ticker: "default", "log",
+	// "symlog", "sqrt", "logit", "time" or "constant".
+	// If Kind is empty it is "constant" when Values
+	// is not empty and is otherwise chosen to match
+	// the axis scale.
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+
+	// Format is the time layout of the labels
+	// of a "time" ticker.
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// Values are the ticks of a "constant" ticker.
+	Values []Tick `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+// Tick is a single constant tick mark. A tick
+// with an empty label is a minor tick.
+type Tick struct {
+	Value float64 `json:"value" yaml:"value"`
+	Label string  `json:"label,omitempty" yaml:"label,omitempty"`
+}
+
+// Legend is the specification of the legend placement.
+type Legend struct {
+	// Top and Left correspond to the plot.Legend fields.
+	Top  bool `json:"top,omitempty" yaml:"top,omitempty"`
+	Left bool `json:"left,omitempty" yaml:"left,omitempty"`
+
+	// XOffs and YOffs are the legend offsets in points.
+	XOffs float64 `json:"xoffs,omitempty" yaml:"xoffs,omitempty"`
+	YOffs float64 `json:"yoffs,omitempty" yaml:"yoffs,omitempty"`
+
+	// Placement is the legend placement: "inside" (the
+	// default), "auto", "right" or "below".
+	Placement string `json:"placement,omitempty" yaml:"placement,omitempty"`
+}
+
+var placements = map[string]plot.LegendPlacement{
+	"":       plot.LegendInside,
+	"inside": plot.LegendInside,
+	"auto":   plot.LegendAuto,
+	"right":  plot.LegendRight,
+	"below":  plot.LegendBelow,
+}
+
+// Plotter is the specification of a single plotter.
+type Plotter struct {
+	// Type is the plotter type: "line", "scatter", "bar",
+	// "histogram", "heatmap" or "boxplot".
+	Type string `json:"type" yaml:"type"`
+
+	// Name, if not empty, is the legend entry text
+	// of the plotter.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Data is the data drawn by the plotter.
+	Data Data `json:"data" yaml:"data"`
+
+	// Color is the drawing color as a "#rgb", "#rgba",
+	// "#rrggbb" or "#rrggbbaa" hexadecimal string. It is
+	// the fill color of bars and histograms.
+	Color string `json:"color,omitempty" yaml:"color,omitempty"`
+
+	// Width is the line width in points of line plotters,
+	// and the bar or box width in points of bar charts
+	// and box plots.
+	Width float64 `json:"width,omitempty" yaml:"width,omitempty"`
+
+	// Shape is the glyph of scatter plotters: "circle",
+	// "ring", "square", "box", "triangle", "pyramid",
+	// "plus" or "cross".
+	Shape string `json:"shape,omitempty" yaml:"shape,omitempty"`
+
+	// Radius is the glyph radius in points of
+	// scatter plotters.
+	Radius float64 `json:"radius,omitempty" yaml:"radius,omitempty"`
+
+	// Bins is the number of histogram bins.
+	// The default is 16.
+	Bins int `json:"bins,omitempty" yaml:"bins,omitempty"`
+
+	// Location is the X location of a box plot.
+	Location float64 `json:"location,omitempty" yaml:"location,omitempty"`
+
+	// Palette is the heat map palette: "heat" (the default)
+	// or the name of a ColorBrewer palette, with Colors
+	// colors. The default number of colors is 9.
+	Palette string `json:"palette,omitempty" yaml:"palette,omitempty"`
+	Colors  int    `json:"colors,omitempty" yaml:"colors,omitempty"`
+}
+
+// Data is the data of a plotter. The data is either given
+// inline or read from a CSV file with a header row.
+//
+// Line and scatter plotters use X and Y, bar charts,
+// histograms and box plots use Values and heat maps
+// use Grid, with optional column and row coordinates
+// given by X and Y.
+type Data struct {
+	X      []float64   `json:"x,omitempty" yaml:"x,omitempty"`
+	Y      []float64   `json:"y,omitempty" yaml:"y,omitempty"`
+	Values []float64   `json:"values,omitempty" yaml:"values,omitempty"`
+	Grid   [][]float64 `json:"grid,omitempty" yaml:"grid,omitempty"`
+
+	// File is the path of a CSV file holding the data.
+	// Relative paths are resolved against the directory
+	// of the specification file, or the working directory
+	// if the specification was not read from a file.
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+
+	// Columns names the columns of File that are used.
+	// Line and scatter plotters use the first two columns,
+	// X and Y, value plotters use the first and heat
+	// maps use each column as a grid column. If Columns
+	// is empty the leading columns of the file are used.
+	Columns []string `json:"columns,omitempty" yaml:"columns,omitempty"`
+}
+
+// Error is an error in a specification. Field is the path
+// of the offending field, for example "plotters[2].data.y".
+type Error struct {
+	Field string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("plotspec: %s: %v", e.Field, e.Err)
+}
+
+func errorf(field, format string, args ...interface{}) error {
+	return &Error{Field: field, Err: fmt.Errorf(format, args...)}
+}
+
+// Decode reads a JSON specification from r and returns
+// the plot that it describes.
+func Decode(r io.Reader) (*plot.Plot, error) {
+	s, err := decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.Build()
+}
+
+// DecodeYAML reads a YAML specification from r and returns
+// the plot that it describes.
+func DecodeYAML(r io.Reader) (*plot.Plot, error) {
+	s, err := decodeYAML(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.Build()
+}
+
+// ReadFile reads the specification in the named file and
+// returns the plot that it describes. The specification is
+// YAML if the file name ends in ".yaml" or ".yml", and JSON
+// otherwise. Data file paths are resolved against the
+// directory of the file.
+func ReadFile(path string) (*plot.Plot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var s *Spec
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		s, err = decodeYAML(f)
+	default:
+		s, err = decode(f)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.build(filepath.Dir(path))
+}
+
+func decode(r io.Reader) (*Spec, error) {
+	var raw json.RawMessage
+	err := json.NewDecoder(r).Decode(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("plotspec: %v", err)
+	}
+	err = checkJSON("", raw, reflect.TypeOf(Spec{}))
+	if err != nil {
+		return nil, err
+	}
+	var s Spec
+	err = json.Unmarshal(raw, &s)
+	if err != nil {
+		return nil, fmt.Errorf("plotspec: %v", err)
+	}
+	return &s, nil
+}
+
+// checkJSON returns an *Error for the first field of the JSON
+// value data that is not a field of the type t, or whose value
+// can not be decoded into its field. The field path of data is
+// given by path, with slice indices written in brackets. Field
+// names are matched without regard to case, as by json.Unmarshal.
+func checkJSON(path string, data json.RawMessage, t reflect.Type) error {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return checkJSON(path, data, t.Elem())
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(data, &fields) != nil {
+			break
+		}
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			name, typ, ok := jsonField(t, k)
+			if !ok {
+				return errorf(joinPath(path, k), "unknown field %q", k)
+			}
+			err := checkJSON(joinPath(path, name), fields[k], typ)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice:
+		var elems []json.RawMessage
+		if json.Unmarshal(data, &elems) != nil {
+			break
+		}
+		for i, e := range elems {
+			err := checkJSON(fmt.Sprintf("%s[%d]", path, i), e, t.Elem())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	err := json.Unmarshal(data, reflect.New(t).Interface())
+	if err == nil {
+		return nil
+	}
+	if typ, ok := err.(*json.UnmarshalTypeError); ok {
+		err = fmt.Errorf("cannot use %s as %v", typ.Value, typ.Type)
+	}
+	if path == "" {
+		return fmt.Errorf("plotspec: %v", err)
+	}
+	return &Error{Field: path, Err: err}
+}
+
+// jsonField returns the JSON name and the type of the field
+// of the struct type t that is decoded from the JSON field key.
+func jsonField(t reflect.Type, key string) (name string, typ reflect.Type, ok bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if strings.EqualFold(name, key) {
+			return name, f.Type, true
+		}
+	}
+	return "", nil, false
+}
+
+// joinPath returns the field path of the field
+// name of the value at the field path path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func decodeYAML(r io.Reader) (*Spec, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var s Spec
+	err = yaml.UnmarshalStrict(b, &s)
+	if err != nil {
+		return nil, fmt.Errorf("plotspec: %v", err)
+	}
+	return &s, nil
+}
+
+// Build returns the plot described by the specification.
+// Relative data file paths are resolved against the working
+// directory. The returned error is an *Error if the
+// specification is invalid.
+func (s *Spec) Build() (*plot.Plot, error) {
+	return s.build("")
+}
+
+func (s *Spec) build(dir string) (*plot.Plot, error) {
+	p, err := plot.New()
+	if err != nil {
+		return nil, err
+	}
+	p.Title.Text = s.Title
+	p.Legend.Top = s.Legend.Top
+	p.Legend.Left = s.Legend.Left
+	p.Legend.XOffs = vg.Points(s.Legend.XOffs)
+	p.Legend.YOffs = vg.Points(s.Legend.YOffs)
+	placement, ok := placements[s.Legend.Placement]
+	if !ok {
+		return nil, errorf("legend.placement", "unknown placement %q", s.Legend.Placement)
+	}
+	p.Legend.Placement = placement
+
+	for i, ps := range s.Plotters {
+		field := fmt.Sprintf("plotters[%d]", i)
+		pl, err := ps.plotter(field, dir)
+		if err != nil {
+			return nil, err
+		}
+		p.Add(pl)
+		if ps.Name == "" {
+			continue
+		}
+		th, ok := pl.(plot.Thumbnailer)
+		if !ok {
+			return nil, errorf(field+".name", "%s plotter has no legend entry", ps.Type)
+		}
+		p.Legend.Add(ps.Name, th)
+	}
+
+	// Axes are set up after the plotters have been
+	// added so that explicit ranges take precedence.
+	err = s.X.apply(&p.X, "x")
+	if err != nil {
+		return nil, err
+	}
+	err = s.Y.apply(&p.Y, "y")
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// apply sets the fields of the plot axis a from the specification.
+func (s *Axis) apply(a *plot.Axis, field string) error {
+	a.Label.Text = s.Label
+	if s.Min != nil {
+		a.Min = *s.Min
+	}
+	if s.Max != nil {
+		a.Max = *s.Max
+	}
+	if s.Min != nil && s.Max != nil && *s.Min > *s.Max {
+		return errorf(field+".max", "max %v less than min %v", *s.Max, *s.Min)
+	}
+
+	kind := "default"
+	switch s.Scale {
+	case "", "linear":
+		a.Scale = plot.LinearScale{}
+	case "log":
+		if s.Min != nil && *s.Min <= 0 {
+			return errorf(field+".min", "non-positive min %v on log scale", *s.Min)
+		}
+		if s.Max != nil && *s.Max <= 0 {
+			return errorf(field+".max", "non-positive max %v on log scale", *s.Max)
+		}
+		// The range of the axis holds the range
+		// of the data of the plotters.
+		if a.Min <= 0 {
+			return errorf(field+".scale", "non-positive data %v on log scale", a.Min)
+		}
+		a.Scale = plot.LogScale{}
+		kind = "log"
+	case "symlog":
+		a.Scale = plot.SymLogScale{}
+		kind = "symlog"
+	case "sqrt":
+		a.Scale = plot.SqrtScale{}
+		kind = "sqrt"
+	case "logit":
+		a.Scale = plot.LogitScale{}
+		kind = "logit"
+	case "time":
+		a.Scale = plot.TimeScale{}
+		kind = "time"
+	default:
+		return errorf(field+".scale", "unknown scale %q", s.Scale)
+	}
+
+	if s.Ticks == nil {
+		a.Tick.Marker = ticker(kind, "")
+		return nil
+	}
+	t := s.Ticks
+	field += ".ticks"
+	switch {
+	case t.Kind != "":
+		kind = t.Kind
+	case len(t.Values) != 0:
+		kind = "constant"
+	}
+	switch kind {
+	case "default", "log", "symlog", "sqrt", "logit", "time":
+		if len(t.Values) != 0 {
+			return errorf(field+".values", "values given for %s ticks", kind)
+		}
+	case "constant":
+		if len(t.Values) == 0 {
+			return errorf(field+".values", "no values for constant ticks")
+		}
+		ticks := make(plot.ConstantTicks, len(t.Values))
+		for i, v := range t.Values {
+			ticks[i] = plot.Tick{Value: v.Value, Label: v.Label}
+		}
+		a.Tick.Marker = ticks
+		return nil
+	default:
+		return errorf(field+".kind", "unknown ticks kind %q", t.Kind)
+	}
+	if t.Format != "" && kind != "time" {
+		return errorf(field+".format", "format given for %s ticks", kind)
+	}
+	a.Tick.Marker = ticker(kind, t.Format)
+	return nil
+}
+
+// ticker returns the named ticker. The name must be
+// "default", "log", "symlog", "sqrt", "logit" or "time".
+func ticker(kind, format string) plot.Ticker {
+	switch kind {
+	case "log":
+		return plot.LogTicks{}
+	case "symlog":
+		return plot.SymLogTicks{}
+	case "sqrt":
+		return plot.SqrtTicks{}
+	case "logit":
+		return plot.LogitTicks{}
+	case "time":
+		return plot.TimeTicks{Format: format}
+	default:
+		return plot.DefaultTicks{}
+	}
+}
+
+// plotter returns the plotter described by the specification.
+func (s *Plotter) plotter(field, dir string) (plot.Plotter, error) {
+	var c color.Color
+	if s.Color != "" {
+		var err error
+		c, err = parseColor(s.Color)
+		if err != nil {
+			return nil, &Error{Field: field + ".color", Err: err}
+		}
+	}
+	if s.Width < 0 {
+		return nil, errorf(field+".width", "negative width %v", s.Width)
+	}
+	if s.Type != "scatter" {
+		if s.Shape != "" {
+			return nil, errorf(field+".shape", "shape given for %s plotter", s.Type)
+		}
+		if s.Radius != 0 {
+			return nil, errorf(field+".radius", "radius given for %s plotter", s.Type)
+		}
+	}
+	if s.Type != "histogram" && s.Bins != 0 {
+		return nil, errorf(field+".bins", "bins given for %s plotter", s.Type)
+	}
+	if s.Type != "heatmap" {
+		if s.Palette != "" {
+			return nil, errorf(field+".palette", "palette given for %s plotter", s.Type)
+		}
+		if s.Colors != 0 {
+			return nil, errorf(field+".colors", "colors given for %s plotter", s.Type)
+		}
+	}
+	data := field + ".data"
+
+	switch s.Type {
+	case "line":
+		xys, err := s.Data.xys(data, dir)
+		if err != nil {
+			return nil, err
+		}
+		l, err := plotter.NewLine(xys)
+		if err != nil {
+			return nil, &Error{Field: data, Err: err}
+		}
+		if c != nil {
+			l.Color = c
+		}
+		if s.Width != 0 {
+			l.Width = vg.Points(s.Width)
+		}
+		return l, nil
+
+	case "scatter":
+		xys, err := s.Data.xys(data, dir)
+		if err != nil {
+			return nil, err
+		}
+		sc, err := plotter.NewScatter(xys)
+		if err != nil {
+			return nil, &Error{Field: data, Err: err}
+		}
+		if c != nil {
+			sc.Color = c
+		}
+		if s.Shape != "" {
+			g, ok := shapes[s.Shape]
+			if !ok {
+				return nil, errorf(field+".shape", "unknown shape %q", s.Shape)
+			}
+			sc.Shape = g
+		}
+		if s.Radius < 0 {
+			return nil, errorf(field+".radius", "negative radius %v", s.Radius)
+		}
+		if s.Radius != 0 {
+			sc.Radius = vg.Points(s.Radius)
+		}
+		return sc, nil
+
+	case "bar":
+		vs, err := s.Data.values(data, dir)
+		if err != nil {
+			return nil, err
+		}
+		b, err := plotter.NewBarChart(vs, s.width())
+		if err != nil {
+			return nil, &Error{Field: data, Err: err}
+		}
+		if c != nil {
+			b.Color = c
+		}
+		return b, nil
+
+	case "histogram":
+		vs, err := s.Data.values(data, dir)
+		if err != nil {
+			return nil, err
+		}
+		if s.Bins < 0 {
+			return nil, errorf(field+".bins", "negative number of bins %d", s.Bins)
+		}
+		n := s.Bins
+		if n == 0 {
+			n = 16
+		}
+		h, err := plotter.NewHist(vs, n)
+		if err != nil {
+			return nil, &Error{Field: data, Err: err}
+		}
+		if c != nil {
+			h.FillColor = c
+		}
+		return h, nil
+
+	case "boxplot":
+		vs, err := s.Data.values(data, dir)
+		if err != nil {
+			return nil, err
+		}
+		b, err := plotter.NewBoxPlot(s.width(), s.Location, vs)
+		if err != nil {
+			return nil, &Error{Field: data, Err: err}
+		}
+		if c != nil {
+			b.BoxStyle.Color = c
+			b.MedianStyle.Color = c
+			b.WhiskerStyle.Color = c
+		}
+		return b, nil
+
+	case "heatmap":
+		if c != nil {
+			return nil, errorf(field+".color", "color given for heatmap plotter")
+		}
+		g, err := s.Data.grid(data, dir)
+		if err != nil {
+			return nil, err
+		}
+		pal, err := s.palette(field)
+		if err != nil {
+			return nil, err
+		}
+		return plotter.NewHeatMap(g, pal), nil
+
+	case "":
+		return nil, errorf(field+".type", "missing plotter type")
+	default:
+		return nil, errorf(field+".type", "unknown plotter type %q", s.Type)
+	}
+}
+
+// width returns the bar or box width of the plotter.
+// The default width is 20 points.
+func (s *Plotter) width() vg.Length {
+	if s.Width == 0 {
+		return vg.Points(20)
+	}
+	return vg.Points(s.Width)
+}
+
+// palette returns the heat map palette of the plotter.
+func (s *Plotter) palette(field string) (palette.Palette, error) {
+	if s.Colors < 0 {
+		return nil, errorf(field+".colors", "negative number of colors %d", s.Colors)
+	}
+	n := s.Colors
+	if n == 0 {
+		n = 9
+	}
+	if s.Palette == "" || s.Palette == "heat" {
+		return palette.Heat(n, 1), nil
+	}
+	p, err := brewer.GetPalette(brewer.TypeAny, s.Palette, n)
+	if err != nil {
+		return nil, &Error{Field: field + ".palette", Err: err}
+	}
+	return p, nil
+}
+
+var shapes = map[string]draw.GlyphDrawer{
+	"circle":   draw.CircleGlyph{},
+	"ring":     draw.RingGlyph{},
+	"square":   draw.SquareGlyph{},
+	"box":      draw.BoxGlyph{},
+	"triangle": draw.TriangleGlyph{},
+	"pyramid":  draw.PyramidGlyph{},
+	"plus":     draw.PlusGlyph{},
+	"cross":    draw.CrossGlyph{},
+}
+
+// parseColor parses a hexadecimal color string.
+func parseColor(s string) (color.Color, error) {
+	if !strings.HasPrefix(s, "#") {
+		return nil, fmt.Errorf("color %q does not start with #", s)
+	}
+	h := s[1:]
+	switch len(h) {
+	case 3, 4:
+		// Expand the short forms.
+		var b bytes.Buffer
+		for _, r := range h {
+			b.WriteRune(r)
+			b.WriteRune(r)
+		}
+		h = b.String()
+	case 6, 8:
+	default:
+		return nil, fmt.Errorf("invalid color %q", s)
+	}
+	if len(h) == 6 {
+		h += "ff"
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color %q", s)
+	}
+	// The components are given unpremultiplied.
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
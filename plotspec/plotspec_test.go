@@ -0,0 +1,264 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plotspec
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/plotter"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/recorder"
+)
+
+func TestReadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plotspec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const data = "load,p50,p99\n1,10,20\n10,12,31\n100,15,47\n"
+	err = ioutil.WriteFile(filepath.Join(dir, "latency.csv"), []byte(data), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const spec = `{
+	"title": "Response time",
+	"x": {"label": "Load", "scale": "log", "min": 0.5},
+	"y": {"label": "Latency", "ticks": {"values": [{"value": 0, "label": "0"}, {"value": 25}, {"value": 50, "label": "50"}]}},
+	"legend": {"top": true, "xoffs": -5, "placement": "auto"},
+	"plotters": [
+		{"type": "line", "name": "p99", "color": "#c00", "width": 2, "data": {"file": "latency.csv", "columns": ["load", "p99"]}},
+		{"type": "scatter", "name": "p50", "shape": "ring", "radius": 4, "data": {"file": "latency.csv"}}
+	]
+}`
+	path := filepath.Join(dir, "spec.json")
+	err = ioutil.WriteFile(path, []byte(spec), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Title.Text != "Response time" {
+		t.Errorf("unexpected title: got:%q want:%q", p.Title.Text, "Response time")
+	}
+	if p.X.Min != 0.5 || p.X.Max != 100 {
+		t.Errorf("unexpected x range: got:[%v, %v] want:[0.5, 100]", p.X.Min, p.X.Max)
+	}
+	if _, ok := p.X.Scale.(plot.LogScale); !ok {
+		t.Errorf("unexpected x scale: got:%T want:plot.LogScale", p.X.Scale)
+	}
+	if _, ok := p.X.Tick.Marker.(plot.LogTicks); !ok {
+		t.Errorf("unexpected x ticker: got:%T want:plot.LogTicks", p.X.Tick.Marker)
+	}
+	if ticks, ok := p.Y.Tick.Marker.(plot.ConstantTicks); !ok || len(ticks) != 3 {
+		t.Errorf("unexpected y ticker: got:%#v want 3 constant ticks", p.Y.Tick.Marker)
+	}
+	if !p.Legend.Top || p.Legend.XOffs != -5 || p.Legend.Placement != plot.LegendAuto {
+		t.Errorf("unexpected legend placement: got top:%t xoffs:%v placement:%v", p.Legend.Top, p.Legend.XOffs, p.Legend.Placement)
+	}
+	if p.Y.Min != 10 || p.Y.Max != 47 {
+		t.Errorf("unexpected y range: got:[%v, %v] want:[10, 47]", p.Y.Min, p.Y.Max)
+	}
+
+	// The plot must be drawable.
+	p.Draw(draw.NewCanvas(new(recorder.Canvas), 300, 200))
+
+	const yamlSpec = `
+title: Response time
+x: {label: Load, scale: log, min: 0.5}
+y: {label: Latency, min: 0}
+plotters:
+  - {type: line, name: p99, color: "#c00", data: {file: latency.csv, columns: [load, p99]}}
+`
+	path = filepath.Join(dir, "spec.yaml")
+	err = ioutil.WriteFile(path, []byte(yamlSpec), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err = ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Title.Text != "Response time" {
+		t.Errorf("unexpected title from YAML: got:%q want:%q", p.Title.Text, "Response time")
+	}
+	if p.X.Min != 0.5 || p.X.Max != 100 || p.Y.Min != 0 || p.Y.Max != 47 {
+		t.Errorf("unexpected ranges from YAML: got:[%v, %v]×[%v, %v] want:[0.5, 100]×[0, 47]", p.X.Min, p.X.Max, p.Y.Min, p.Y.Max)
+	}
+}
+
+func TestBuildPlotters(t *testing.T) {
+	s := Spec{Plotters: []Plotter{
+		{Type: "bar", Color: "#00f8", Data: Data{Values: []float64{1, 2, 3}}},
+		{Type: "histogram", Bins: 4, Data: Data{Values: []float64{1, 2, 2, 3, 3, 3}}},
+		{Type: "boxplot", Location: 5, Data: Data{Values: []float64{1, 2, 3, 4}}},
+		{Type: "heatmap", Palette: "YlGnBu", Colors: 5, Data: Data{Grid: [][]float64{{1, 2}, {3, 4}, {5, 6}}, Y: []float64{10, 20, 30}}},
+	}}
+	p, err := s.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Draw(draw.NewCanvas(new(recorder.Canvas), 300, 200))
+
+	mustPlotter := func(i int) plot.Plotter {
+		pl, err := s.Plotters[i].plotter("", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return pl
+	}
+	b := mustPlotter(0).(*plotter.BarChart)
+	if b.Width != vg.Points(20) {
+		t.Errorf("unexpected bar width: got:%v want:%v", b.Width, vg.Points(20))
+	}
+	if r, g, bl, a := b.Color.RGBA(); r != 0 || g != 0 || bl != 0x8888 || a != 0x8888 {
+		t.Errorf("unexpected bar color: got:%v", b.Color)
+	}
+	if h := mustPlotter(1).(*plotter.Histogram); len(h.Bins) != 4 {
+		t.Errorf("unexpected number of bins: got:%d want:4", len(h.Bins))
+	}
+	h := mustPlotter(3).(*plotter.HeatMap)
+	if c, r := h.GridXYZ.Dims(); c != 2 || r != 3 {
+		t.Errorf("unexpected grid dimensions: got:%dx%d want:2x3", c, r)
+	}
+	if y := h.GridXYZ.Y(2); y != 30 {
+		t.Errorf("unexpected grid row coordinate: got:%v want:30", y)
+	}
+	if n := len(h.Palette.Colors()); n != 5 {
+		t.Errorf("unexpected number of palette colors: got:%d want:5", n)
+	}
+}
+
+var errorTests = []struct {
+	spec  string
+	field string
+}{
+	{
+		spec:  `{"plotters": [{"type": "line", "data": {"x": [1, 2], "y": [1]}}]}`,
+		field: "plotters[0].data.y",
+	},
+	{
+		spec:  `{"plotters": [{"type": "scatter", "data": {"x": [1]}}, {"type": "pie", "data": {}}]}`,
+		field: "plotters[0].data.y",
+	},
+	{
+		spec:  `{"plotters": [{"type": "line", "data": {"x": [1], "y": [1]}}, {"type": "pie", "data": {}}]}`,
+		field: "plotters[1].type",
+	},
+	{
+		spec:  `{"plotters": [{"type": "bar", "data": {"x": [1, 2]}}]}`,
+		field: "plotters[0].data.x",
+	},
+	{
+		spec:  `{"plotters": [{"type": "heatmap", "data": {"grid": [[1, 2], [3]]}}]}`,
+		field: "plotters[0].data.grid[1]",
+	},
+	{
+		spec:  `{"plotters": [{"type": "heatmap", "palette": "Nope", "data": {"grid": [[1]]}}]}`,
+		field: "plotters[0].palette",
+	},
+	{
+		spec:  `{"plotters": [{"type": "boxplot", "name": "b", "data": {"values": [1, 2]}}]}`,
+		field: "plotters[0].name",
+	},
+	{
+		spec:  `{"plotters": [{"type": "scatter", "color": "red", "data": {"x": [1], "y": [1]}}]}`,
+		field: "plotters[0].color",
+	},
+	{
+		spec:  `{"plotters": [{"type": "line", "bins": 3, "data": {"x": [1], "y": [1]}}]}`,
+		field: "plotters[0].bins",
+	},
+	{
+		spec:  `{"plotters": [{"type": "line", "data": {"file": "missing.csv"}}]}`,
+		field: "plotters[0].data.file",
+	},
+	{
+		spec:  `{"x": {"min": 2, "max": 1}, "plotters": []}`,
+		field: "x.max",
+	},
+	{
+		spec:  `{"y": {"scale": "cubic"}, "plotters": []}`,
+		field: "y.scale",
+	},
+	{
+		spec:  `{"x": {"scale": "log", "min": 0}, "plotters": []}`,
+		field: "x.min",
+	},
+	{
+		spec:  `{"y": {"scale": "log", "max": -1}, "plotters": []}`,
+		field: "y.max",
+	},
+	{
+		spec:  `{"x": {"scale": "log"}, "plotters": [{"type": "line", "data": {"x": [0, 1, 10], "y": [1, 2, 3]}}]}`,
+		field: "x.scale",
+	},
+	{
+		spec:  `{"x": {"ticks": {"kind": "log", "format": "2006"}}, "plotters": []}`,
+		field: "x.ticks.format",
+	},
+	{
+		spec:  `{"x": {"ticks": {"kind": "constant"}}, "plotters": []}`,
+		field: "x.ticks.values",
+	},
+	{
+		spec:  `{"legend": {"placement": "outside"}, "plotters": []}`,
+		field: "legend.placement",
+	},
+	{
+		spec:  `{"plotters": [{"type": "histogram", "bins": "many"}]}`,
+		field: "plotters[0].bins",
+	},
+}
+
+func TestErrors(t *testing.T) {
+	for i, test := range errorTests {
+		_, err := Decode(strings.NewReader(test.spec))
+		e, ok := err.(*Error)
+		if !ok {
+			t.Errorf("unexpected error for test %d: got:%v want:*Error", i, err)
+			continue
+		}
+		if e.Field != test.field {
+			t.Errorf("unexpected error field for test %d: got:%q want:%q", i, e.Field, test.field)
+		}
+	}
+
+	_, err := Decode(strings.NewReader(`{"plotters": [], "colour": "#fff"}`))
+	if err == nil || !strings.Contains(err.Error(), `"colour"`) {
+		t.Errorf("unexpected error for unknown field: got:%v", err)
+	}
+	_, err = Decode(strings.NewReader(`{"plotters": [{"type": "line", "data": {"x": [1], "y": [1], "z": [1]}}]}`))
+	if e, ok := err.(*Error); !ok || e.Field != "plotters[0].data.z" {
+		t.Errorf("unexpected error for unknown nested field: got:%v", err)
+	}
+	_, err = Decode(strings.NewReader(`{"Title": "case", "plotters": []}`))
+	if err != nil {
+		t.Errorf("unexpected error for field name in other case: %v", err)
+	}
+	_, err = Decode(strings.NewReader(`[]`))
+	if err == nil {
+		t.Error("expected error for specification that is not an object")
+	}
+
+	_, err = DecodeYAML(strings.NewReader("plotters: []\ncolour: \"#fff\"\n"))
+	if err == nil || !strings.Contains(err.Error(), "colour") {
+		t.Errorf("unexpected error for unknown YAML field: got:%v", err)
+	}
+	_, err = DecodeYAML(strings.NewReader("plotters: [{type: histogram, bins: many, data: {values: [1]}}]\n"))
+	if err == nil || !strings.Contains(err.Error(), "many") {
+		t.Errorf("unexpected error for invalid YAML value: got:%v", err)
+	}
+}
@@ -5,6 +5,7 @@
 package plotter
 
 import (
+	"errors"
 	"image/color"
 	"math"
 	"sort"
@@ -43,6 +44,23 @@ type Contour struct {
 	// Min and Max define the dynamic range of the
 	// heat map.
 	Min, Max float64
+
+	// Filled specifies whether the bands between
+	// consecutive levels are filled with the colors
+	// of the palette, rather than the contour lines
+	// being drawn. The palette is spread across the
+	// values midway between the levels of the bands,
+	// and a band whose midway value is outside the
+	// range from Min to Max is filled with the Underflow
+	// or Overflow color. A filled contour plot must have
+	// a Palette. The contour lines can be drawn over the
+	// bands by a second Contour of the same data.
+	Filled bool
+
+	// Labels, if not nil, describes the labels of
+	// the levels that are drawn along the contour
+	// lines.
+	Labels *ContourLabels
 }
 
 // NewContour creates as new contour plotter for the given data, using
@@ -122,6 +140,10 @@ const naive = false
 
 // Plot implements the Plot method of the plot.Plotter interface.
 func (h *Contour) Plot(c draw.Canvas, plt *plot.Plot) {
+	if h.Filled {
+		h.fill(c, plt)
+		return
+	}
 	if naive {
 		h.naivePlot(c, plt)
 		return
@@ -150,15 +172,16 @@ func (h *Contour) Plot(c draw.Canvas, plt *plot.Plot) {
 		ps = 0
 	}
 
+	var labels []contourLabel
 	for i, z := range h.Levels {
 		if math.IsNaN(z) {
 			continue
 		}
+		var txt string
+		if h.Labels != nil {
+			txt = h.Labels.format(z)
+		}
 		for _, pa := range cp[z] {
-			if isLoop(pa) {
-				pa.Close()
-			}
-
 			style := h.LineStyles[i%len(h.LineStyles)]
 			var col color.Color
 			switch {
@@ -171,13 +194,87 @@ func (h *Contour) Plot(c draw.Canvas, plt *plot.Plot) {
 			default:
 				col = pal[int((z-h.Levels[0])*ps+0.5)] // Apply palette scaling.
 			}
-			if col != nil && style.Width != 0 {
-				c.SetLineStyle(style)
-				c.SetColor(col)
-				c.Stroke(pa)
+			if col == nil || style.Width == 0 {
+				continue
+			}
+
+			var parts []vg.Path
+			if h.Labels != nil {
+				parts, labels = h.Labels.place(c, pa, txt, col, labels)
+			}
+			if parts == nil {
+				if isLoop(pa) {
+					pa.Close()
+				}
+				parts = []vg.Path{pa}
+			}
+			c.SetLineStyle(style)
+			c.SetColor(col)
+			for _, part := range parts {
+				c.Stroke(part)
 			}
 		}
 	}
+	for _, lb := range labels {
+		lb.draw(c)
+	}
+}
+
+// ColorBar returns a color bar showing the colors of
+// the levels of the contour plot. The contour plot must
+// have a Palette.
+func (h *Contour) ColorBar() (*plot.ColorBar, error) {
+	if h.Palette == nil || len(h.Palette.Colors()) == 0 {
+		return nil, errors.New("Contour has no palette")
+	}
+	b, err := plot.NewColorBar(h.Palette, h.Min, h.Max)
+	if err != nil {
+		return nil, err
+	}
+	b.Underflow = h.Underflow
+	b.Overflow = h.Overflow
+	b.Levels = append([]float64(nil), h.Levels...)
+	b.Filled = h.Filled
+	return b, nil
+}
+
+// fill fills the bands between the levels of the contour plot.
+func (h *Contour) fill(c draw.Canvas, plt *plot.Plot) {
+	if h.Palette == nil {
+		return
+	}
+	pal := h.Palette.Colors()
+	if len(pal) == 0 {
+		return
+	}
+	var levels []float64
+	for _, z := range h.Levels {
+		if !math.IsNaN(z) {
+			levels = append(levels, z)
+		}
+	}
+	sort.Float64s(levels)
+	if len(levels) < 2 {
+		return
+	}
+
+	bar := plot.ColorBar{
+		Palette:   h.Palette,
+		Min:       h.Min,
+		Max:       h.Max,
+		Underflow: h.Underflow,
+		Overflow:  h.Overflow,
+		Levels:    levels,
+	}
+	trX, trY := plt.Transforms(&c)
+	for k, pa := range contourBands(h.GridXYZ, levels, trX, trY) {
+		col := bar.BandColor(k)
+		if col == nil || len(pa) == 0 {
+			continue
+		}
+		c.SetColor(col)
+		c.Fill(pa)
+	}
 }
 
 // naivePlot implements the a naive rendering approach for contours.
@@ -656,3 +753,206 @@ func (c *contour) exciseQuick(conts contourSet) {
 	c.backward[0] = wp[0]
 	c.forward = wp[1:]
 }
+
+// vertex is a point of the data grid with its height.
+type vertex struct {
+	X, Y, Z float64
+}
+
+// contourBands returns a vg.Path for each band between consecutive
+// values of the sorted levels, describing the region of the data in
+// m that lies within the band. The trX and trY functions are
+// coordinate transforms.
+//
+// Each grid box is divided into the four triangles used by conrec,
+// and each triangle is clipped to the band on the assumption that
+// the data vary linearly within it, so the band edges match the
+// contour lines. The clipped triangles of a band are all wound in
+// the same direction, so the edges that two of them share cancel,
+// and the remaining edges are joined into the closed subpaths of
+// the path of the band: its outlines wound in that direction and
+// the outlines of its holes wound in the other.
+func contourBands(m GridXYZ, levels []float64, trX, trY func(float64) vg.Length) []vg.Path {
+	bands := make([]boundary, len(levels)-1)
+	paths := make([]vg.Path, len(bands))
+	if len(bands) == 0 {
+		return paths
+	}
+
+	im := [4]int{0, 1, 1, 0}
+	jm := [4]int{0, 0, 1, 1}
+
+	var corners [4]vertex
+	var buf [2][]vertex
+	c, r := m.Dims()
+	for i := 0; i < c-1; i++ {
+		for j := 0; j < r-1; j++ {
+			dmin, dmax := math.Inf(1), math.Inf(-1)
+			var centre vertex
+			for k := range corners {
+				ci, cj := i+im[k], j+jm[k]
+				v := vertex{X: m.X(ci), Y: m.Y(cj), Z: m.Z(ci, cj)}
+				corners[k] = v
+				centre.Z += 0.25 * v.Z
+				dmin = math.Min(dmin, v.Z)
+				dmax = math.Max(dmax, v.Z)
+			}
+			if math.IsNaN(centre.Z) || dmax < levels[0] || levels[len(levels)-1] < dmin {
+				continue
+			}
+			centre.X = 0.5 * (m.X(i) + m.X(i+1))
+			centre.Y = 0.5 * (m.Y(j) + m.Y(j+1))
+
+			for t := range corners {
+				tri := []vertex{centre, corners[t], corners[(t+1)%4]}
+				tmin := math.Min(tri[0].Z, math.Min(tri[1].Z, tri[2].Z))
+				tmax := math.Max(tri[0].Z, math.Max(tri[1].Z, tri[2].Z))
+				for k := range bands {
+					lo, hi := levels[k], levels[k+1]
+					if tmax < lo || hi < tmin {
+						continue
+					}
+					bands[k].add(clipBand(tri, lo, hi, &buf))
+				}
+			}
+		}
+	}
+	for k := range bands {
+		paths[k] = bands[k].path(trX, trY)
+	}
+	return paths
+}
+
+// edge is a directed edge of a polygon.
+type edge struct {
+	from, to point
+}
+
+// boundary holds the edges of polygons wound in the same
+// direction that are not shared by two of the polygons,
+// so that the edges trace the outline of the union of
+// the polygons.
+type boundary struct {
+	// edges holds the edges in the order they were
+	// added, with the cancelled edges marked dead.
+	edges []edge
+	dead  []bool
+
+	// live holds the indices in edges of the edges
+	// that have not been cancelled.
+	live map[edge][]int
+}
+
+// add adds the edges of the polygon poly to the boundary,
+// cancelling any edges added in the opposite direction.
+func (b *boundary) add(poly []vertex) {
+	if b.live == nil {
+		b.live = make(map[edge][]int)
+	}
+	for i, v := range poly {
+		e := edge{
+			from: point{X: v.X, Y: v.Y},
+			to:   point{X: poly[(i+1)%len(poly)].X, Y: poly[(i+1)%len(poly)].Y},
+		}
+		if e.from == e.to {
+			continue
+		}
+		rev := edge{from: e.to, to: e.from}
+		if idx := b.live[rev]; len(idx) != 0 {
+			b.dead[idx[len(idx)-1]] = true
+			b.live[rev] = idx[:len(idx)-1]
+			continue
+		}
+		b.live[e] = append(b.live[e], len(b.edges))
+		b.edges = append(b.edges, e)
+		b.dead = append(b.dead, false)
+	}
+}
+
+// path returns the path of the closed loops formed by the
+// edges of the boundary. The trX and trY functions are
+// coordinate transforms.
+func (b *boundary) path(trX, trY func(float64) vg.Length) vg.Path {
+	out := make(map[point][]int)
+	for i, e := range b.edges {
+		if !b.dead[i] {
+			out[e.from] = append(out[e.from], i)
+		}
+	}
+	var pa vg.Path
+	used := make([]bool, len(b.edges))
+	for i, e := range b.edges {
+		if b.dead[i] || used[i] {
+			continue
+		}
+		start := e.from
+		pa.Move(trX(start.X), trY(start.Y))
+		for {
+			used[i] = true
+			to := b.edges[i].to
+			if to == start {
+				break
+			}
+			pa.Line(trX(to.X), trY(to.Y))
+			next := -1
+			for _, j := range out[to] {
+				if !used[j] {
+					next = j
+					break
+				}
+			}
+			if next < 0 {
+				break
+			}
+			i = next
+		}
+		pa.Close()
+	}
+	return pa
+}
+
+// clipBand returns the part of the polygon poly with heights from
+// lo to hi, interpolating linearly along its edges. The returned
+// polygon is held in one of the buffers in buf.
+func clipBand(poly []vertex, lo, hi float64, buf *[2][]vertex) []vertex {
+	poly = clipLevel(buf[0][:0], poly, lo, true)
+	buf[0] = poly
+	poly = clipLevel(buf[1][:0], poly, hi, false)
+	buf[1] = poly
+	return poly
+}
+
+// clipLevel appends to dst the part of the polygon poly that is
+// above the level z if above is true, or below it otherwise, and
+// returns the extended slice.
+func clipLevel(dst, poly []vertex, z float64, above bool) []vertex {
+	inside := func(v vertex) bool {
+		if above {
+			return v.Z >= z
+		}
+		return v.Z <= z
+	}
+	for i, a := range poly {
+		b := poly[(i+1)%len(poly)]
+		ain, bin := inside(a), inside(b)
+		if ain {
+			dst = append(dst, a)
+		}
+		if ain != bin {
+			// The crossing is found from the same end of the
+			// edge whichever way round it is, so that the
+			// polygons sharing the edge share the crossing.
+			p, q := a, b
+			if q.X < p.X || (q.X == p.X && q.Y < p.Y) {
+				p, q = q, p
+			}
+			t := (z - p.Z) / (q.Z - p.Z)
+			dst = append(dst, vertex{
+				X: p.X + t*(q.X-p.X),
+				Y: p.Y + t*(q.Y-p.Y),
+				Z: z,
+			})
+		}
+	}
+	return dst
+}
@@ -7,6 +7,7 @@ package plotter
 import (
 	"flag"
 	"fmt"
+	"image/color"
 	"math"
 	"math/rand"
 	"reflect"
@@ -17,6 +18,8 @@ import (
 	"github.com/gonum/plot"
 	"github.com/gonum/plot/palette"
 	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/recorder"
 )
 
 var visualDebug = flag.Bool("visual", false, "output images for benchmarks and test data")
@@ -469,3 +472,195 @@ type testContour []*contour
 func (c testContour) Len() int           { return len(c) }
 func (c testContour) Less(i, j int) bool { return len(c[i].forward) < len(c[j].forward) }
 func (c testContour) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+
+func TestContourBands(t *testing.T) {
+	// The data vary linearly with x+y, so the bands are
+	// the diagonal strips of the square of the grid.
+	m := unitGrid{mat64.NewDense(3, 3, []float64{
+		0, 1, 2,
+		1, 2, 3,
+		2, 3, 4,
+	})}
+	levels := []float64{0, 1, 2, 3, 4}
+	wantArea := []float64{0.5, 1.5, 1.5, 0.5}
+
+	bands := contourBands(m, levels, unity, unity)
+	if len(bands) != len(wantArea) {
+		t.Fatalf("unexpected number of bands: got:%d want:%d", len(bands), len(wantArea))
+	}
+	for k, pa := range bands {
+		var area float64
+		var sub []vg.PathComp
+		var n int
+		for _, c := range pa {
+			switch c.Type {
+			case vg.MoveComp:
+				n++
+				sub = append(sub[:0], c)
+			case vg.LineComp:
+				sub = append(sub, c)
+			case vg.CloseComp:
+				var a float64
+				for i, p := range sub {
+					q := sub[(i+1)%len(sub)]
+					a += float64(p.X*q.Y-q.X*p.Y) / 2
+				}
+				if a < -1e-12 {
+					t.Errorf("band %d: unexpected clockwise subpath: %v", k, sub)
+				}
+				area += a
+			}
+		}
+		if math.Abs(area-wantArea[k]) > 1e-12 {
+			t.Errorf("band %d: unexpected area: got:%v want:%v", k, area, wantArea[k])
+		}
+		// The clipped triangles of each strip are merged.
+		if n != 1 {
+			t.Errorf("band %d: unexpected number of subpaths: got:%d want:1", k, n)
+		}
+	}
+
+	// The band around a peak has a hole.
+	m = unitGrid{mat64.NewDense(5, 5, []float64{
+		0, 0, 0, 0, 0,
+		0, 2, 2, 2, 0,
+		0, 2, 4, 2, 0,
+		0, 2, 2, 2, 0,
+		0, 0, 0, 0, 0,
+	})}
+	bands = contourBands(m, []float64{1, 3}, unity, unity)
+	var areas []float64
+	var sub []vg.PathComp
+	for _, c := range bands[0] {
+		switch c.Type {
+		case vg.MoveComp:
+			sub = append(sub[:0], c)
+		case vg.LineComp:
+			sub = append(sub, c)
+		case vg.CloseComp:
+			var a float64
+			for i, p := range sub {
+				q := sub[(i+1)%len(sub)]
+				a += float64(p.X*q.Y-q.X*p.Y) / 2
+			}
+			areas = append(areas, a)
+		}
+	}
+	if len(areas) != 2 || areas[0]*areas[1] >= 0 {
+		t.Errorf("unexpected areas of outline and hole of ring band: %v", areas)
+	}
+}
+
+func TestContourBandColor(t *testing.T) {
+	pal := palette.Heat(4, 1)
+	h := &Contour{Palette: pal, Levels: []float64{8, 0, 2, 4, 6}, Min: 0, Max: 6, Overflow: color.Black, Filled: true}
+	b, err := h.ColorBar()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cols := pal.Colors()
+	want := []color.Color{cols[0], cols[1], cols[2], color.Black}
+	for k := range want {
+		if got := b.BandColor(k); got != want[k] {
+			t.Errorf("unexpected color of band %d: got:%v want:%v", k, got, want[k])
+		}
+	}
+}
+
+func TestContourLabelPlacement(t *testing.T) {
+	l, err := NewContourLabels()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l.Spacing = 0
+	c := draw.Canvas{Canvas: &recorder.Canvas{}, Rectangle: draw.Rectangle{Max: draw.Point{X: 100, Y: 100}}}
+	vertical := func(x vg.Length) vg.Path {
+		var pa vg.Path
+		pa.Move(x, 0)
+		for y := vg.Length(10); y <= 100; y += 10 {
+			pa.Line(x, y)
+		}
+		return pa
+	}
+
+	parts, placed := l.place(c, vertical(25), "0.5", color.Black, nil)
+	if len(placed) != 1 || len(parts) != 2 {
+		t.Fatalf("unexpected placement: got %d labels and %d parts, want 1 and 2", len(placed), len(parts))
+	}
+	lb := placed[0]
+	if lb.at != (draw.Point{X: 25, Y: 50}) || lb.style.Rotation != math.Pi/2 || lb.style.Color != color.Black {
+		t.Errorf("unexpected label: at:%v rotation:%v color:%v", lb.at, lb.style.Rotation, lb.style.Color)
+	}
+	gap := parts[1][0].Y - parts[0][len(parts[0])-1].Y
+	if want := l.Width("0.5") + 2*l.Padding; math.Abs(float64(gap-want)) > 1e-9 {
+		t.Errorf("unexpected gap in line: got:%v want:%v", gap, want)
+	}
+
+	// A label on a nearby line must avoid the first label.
+	_, placed = l.place(c, vertical(26), "0.51", color.Black, placed)
+	if len(placed) != 2 {
+		t.Fatalf("unexpected number of labels: got:%d want:2", len(placed))
+	}
+	if overlap(placed[0].bounds, placed[1].bounds) {
+		t.Errorf("labels overlap: %v and %v", placed[0].bounds, placed[1].bounds)
+	}
+
+	// A line that is nowhere straight has no labels.
+	var zigzag vg.Path
+	zigzag.Move(0, 50)
+	for x := vg.Length(5); x <= 100; x += 5 {
+		zigzag.Line(x, 50+5*vg.Length(int(x/5)%2))
+	}
+	parts, placed = l.place(c, zigzag, "1", color.Black, nil)
+	if parts != nil || len(placed) != 0 {
+		t.Errorf("unexpected labels on bent line: %v", placed)
+	}
+
+	l.Spacing = 20
+	_, placed = l.place(c, vertical(50), "1", color.Black, nil)
+	if len(placed) < 3 {
+		t.Errorf("unexpected number of spaced labels: got:%d want at least 3", len(placed))
+	}
+}
+
+func TestContourLabels(t *testing.T) {
+	m := unitGrid{mat64.NewDense(3, 3, []float64{
+		0, 1, 2,
+		0, 1, 2,
+		0, 1, 2,
+	})}
+	h := NewContour(m, []float64{0.5, 1.5}, palette.Heat(2, 1))
+	var err error
+	h.Labels, err = NewContourLabels()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h.Labels.Spacing = 0
+	h.Labels.Formatter = plot.FixedFormat{Decimals: 2}
+
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("failed to create plot: %v", err)
+	}
+	p.X.Min, p.X.Max, p.Y.Min, p.Y.Max = h.DataRange()
+	var rec recorder.Canvas
+	c := draw.Canvas{Canvas: &rec, Rectangle: draw.Rectangle{Max: draw.Point{X: 100, Y: 100}}}
+	h.Plot(c, p)
+
+	var strokes int
+	var text []string
+	for _, a := range rec.Actions {
+		switch a := a.(type) {
+		case *recorder.Stroke:
+			strokes++
+		case *recorder.FillString:
+			text = append(text, a.String)
+		}
+	}
+	if strokes != 4 {
+		t.Errorf("unexpected number of line parts: got:%d want:4", strokes)
+	}
+	if want := []string{"0.50", "1.50"}; !reflect.DeepEqual(text, want) {
+		t.Errorf("unexpected labels: got:%q want:%q", text, want)
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plotter
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// ContourLabels describes the labels of the levels of
+// contour lines, which are drawn along the lines in gaps
+// cut into them.
+type ContourLabels struct {
+	// TextStyle is the style of the label text. The
+	// labels are rotated to follow their lines, so the
+	// Rotation of the style is not used. If the Color of
+	// the style is nil, a label has the color of its line.
+	draw.TextStyle
+
+	// Formatter formats the levels as the label text.
+	// If Formatter is nil, the levels are formatted with
+	// up to four significant digits.
+	Formatter plot.TickFormatter
+
+	// Spacing is the distance along a line from the
+	// end of one label to the start of the next. If
+	// Spacing is not positive, each line has at most
+	// one label.
+	Spacing vg.Length
+
+	// Padding is the length of the gap in the line
+	// on each side of a label.
+	Padding vg.Length
+
+	// MaxBend is the greatest angle in radians by which
+	// the line under a label may turn away from the
+	// direction of the label. Labels are only placed on
+	// sufficiently straight parts of the lines.
+	MaxBend float64
+}
+
+// NewContourLabels returns new contour labels using the
+// DefaultFont and the DefaultFontSize.
+func NewContourLabels() (*ContourLabels, error) {
+	fnt, err := vg.MakeFont(DefaultFont, DefaultFontSize)
+	if err != nil {
+		return nil, err
+	}
+	return &ContourLabels{
+		TextStyle: draw.TextStyle{Font: fnt},
+		Spacing:   vg.Inch * 2,
+		Padding:   vg.Points(2),
+		MaxBend:   math.Pi / 12,
+	}, nil
+}
+
+// format returns the label text of the level z.
+func (l *ContourLabels) format(z float64) string {
+	if l.Formatter != nil {
+		return l.Formatter.Format(z)
+	}
+	return strconv.FormatFloat(z, 'g', 4, 64)
+}
+
+// contourLabel is a label placed on a contour line.
+type contourLabel struct {
+	text  string
+	at    draw.Point
+	style draw.TextStyle
+
+	// bounds is the bounding rectangle
+	// of the label on the canvas.
+	bounds draw.Rectangle
+}
+
+// draw draws the label to c.
+func (lb contourLabel) draw(c draw.Canvas) {
+	c.FillText(lb.style, lb.at.X, lb.at.Y, -0.5, -0.5, lb.text)
+}
+
+// place places labels with the text txt along the line pa on
+// the canvas c, avoiding the labels already placed, and returns
+// the parts of the line between the labels and the extended
+// slice of placed labels. If no label is placed, the returned
+// parts are nil.
+func (l *ContourLabels) place(c draw.Canvas, pa vg.Path, txt string, col color.Color, placed []contourLabel) ([]vg.Path, []contourLabel) {
+	var line polyline
+	for _, comp := range pa {
+		if comp.Type == vg.MoveComp || comp.Type == vg.LineComp {
+			line.add(draw.Point{X: comp.X, Y: comp.Y})
+		}
+	}
+	sty := l.TextStyle
+	sty.Rotation = 0
+	if sty.Color == nil {
+		sty.Color = col
+	}
+	need := sty.Width(txt) + 2*l.Padding
+	total := line.length()
+	if need <= 0 || total < need {
+		return nil, placed
+	}
+
+	// Labels are placed at positions spaced along the line,
+	// starting so that a single label is centred on it. When
+	// a position is unsuitable, the following positions are
+	// tried in small steps.
+	start := (total - need) / 2
+	if l.Spacing > 0 {
+		start = vg.Length(math.Mod(float64(start), float64(need+l.Spacing)))
+	}
+	step := need / 4
+	var gaps [][2]vg.Length
+	for a := start; a+need <= total; {
+		lb, ok := l.try(c, line, a, need, txt, sty, placed)
+		if !ok {
+			a += step
+			continue
+		}
+		placed = append(placed, lb)
+		gaps = append(gaps, [2]vg.Length{a, a + need})
+		if l.Spacing <= 0 {
+			break
+		}
+		a += need + l.Spacing
+	}
+	if gaps == nil {
+		return nil, placed
+	}
+	return line.cut(gaps), placed
+}
+
+// try returns the label placed on the part of the line from a
+// to a+need along it, and whether the label can be placed there.
+func (l *ContourLabels) try(c draw.Canvas, line polyline, a, need vg.Length, txt string, sty draw.TextStyle, placed []contourLabel) (contourLabel, bool) {
+	p0, p1 := line.at(a), line.at(a+need)
+	dir := math.Atan2(float64(p1.Y-p0.Y), float64(p1.X-p0.X))
+	for _, seg := range line.within(a, a+need) {
+		d := math.Atan2(float64(seg[1].Y-seg[0].Y), float64(seg[1].X-seg[0].X))
+		if bend := math.Abs(math.Remainder(d-dir, 2*math.Pi)); bend > l.MaxBend {
+			return contourLabel{}, false
+		}
+	}
+
+	// Keep the text upright.
+	if dir > math.Pi/2 {
+		dir -= math.Pi
+	} else if dir < -math.Pi/2 {
+		dir += math.Pi
+	}
+	sty.Rotation = dir
+	at := line.at(a + need/2)
+	r := sty.AlignedRectangle(txt, -0.5, -0.5)
+	r.Min.X += at.X
+	r.Min.Y += at.Y
+	r.Max.X += at.X
+	r.Max.Y += at.Y
+	if r.Min.X < c.Min.X || r.Min.Y < c.Min.Y || r.Max.X > c.Max.X || r.Max.Y > c.Max.Y {
+		return contourLabel{}, false
+	}
+	for _, lb := range placed {
+		if overlap(r, lb.bounds) {
+			return contourLabel{}, false
+		}
+	}
+	return contourLabel{text: txt, at: at, style: sty, bounds: r}, true
+}
+
+// overlap returns whether the rectangles a and b overlap.
+func overlap(a, b draw.Rectangle) bool {
+	return a.Min.X < b.Max.X && b.Min.X < a.Max.X && a.Min.Y < b.Max.Y && b.Min.Y < a.Max.Y
+}
+
+// polyline is a line through a sequence of points, with the
+// distance along the line of each point.
+type polyline struct {
+	pts  []draw.Point
+	dist []vg.Length
+}
+
+// add adds the point p to the end of the line.
+func (l *polyline) add(p draw.Point) {
+	var d vg.Length
+	if n := len(l.pts); n > 0 {
+		q := l.pts[n-1]
+		d = l.dist[n-1] + vg.Length(math.Hypot(float64(p.X-q.X), float64(p.Y-q.Y)))
+	}
+	l.pts = append(l.pts, p)
+	l.dist = append(l.dist, d)
+}
+
+// length returns the length of the line.
+func (l polyline) length() vg.Length {
+	if len(l.dist) == 0 {
+		return 0
+	}
+	return l.dist[len(l.dist)-1]
+}
+
+// index returns the index of the segment of
+// the line at the distance s along it.
+func (l polyline) index(s vg.Length) int {
+	i := 0
+	for i < len(l.pts)-2 && l.dist[i+1] < s {
+		i++
+	}
+	return i
+}
+
+// at returns the point at the distance s along the line.
+func (l polyline) at(s vg.Length) draw.Point {
+	if len(l.pts) == 1 {
+		return l.pts[0]
+	}
+	i := l.index(s)
+	p, q := l.pts[i], l.pts[i+1]
+	d := l.dist[i+1] - l.dist[i]
+	if d == 0 {
+		return p
+	}
+	t := (s - l.dist[i]) / d
+	return draw.Point{X: p.X + t*(q.X-p.X), Y: p.Y + t*(q.Y-p.Y)}
+}
+
+// within returns the segments of the line from the
+// distance s to e along it, cut at s and e.
+func (l polyline) within(s, e vg.Length) [][2]draw.Point {
+	var segs [][2]draw.Point
+	p := l.at(s)
+	for i := l.index(s) + 1; i < len(l.pts) && l.dist[i] < e; i++ {
+		if l.pts[i] != p {
+			segs = append(segs, [2]draw.Point{p, l.pts[i]})
+		}
+		p = l.pts[i]
+	}
+	if q := l.at(e); q != p {
+		segs = append(segs, [2]draw.Point{p, q})
+	}
+	return segs
+}
+
+// cut returns the parts of the line outside the gaps, which
+// are the increasing and non-overlapping ranges of distance
+// along the line from the first to the second of each pair.
+func (l polyline) cut(gaps [][2]vg.Length) []vg.Path {
+	var parts []vg.Path
+	var s vg.Length
+	for _, g := range append(gaps, [2]vg.Length{l.length(), l.length()}) {
+		if g[0] > s {
+			var pa vg.Path
+			p := l.at(s)
+			pa.Move(p.X, p.Y)
+			for _, seg := range l.within(s, g[0]) {
+				pa.Line(seg[1].X, seg[1].Y)
+			}
+			parts = append(parts, pa)
+		}
+		s = g[1]
+	}
+	return parts
+}
@@ -0,0 +1,198 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plotter
+
+import (
+	"math"
+
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Downsampling specifies how the points of a Line or a
+// Scatter are reduced, once transformed to the canvas, to
+// about as many as the canvas can show, so that the size
+// of the output depends on the size of the canvas rather
+// than on the number of points.
+type Downsampling int
+
+const (
+	// NoDownsampling draws every point.
+	NoDownsampling Downsampling = iota
+
+	// MinMaxDownsampling keeps, of each run of consecutive
+	// points in the same pixel column, the first and last
+	// points and those with the least and greatest y, so
+	// that a line through the kept points covers the same
+	// pixels as a line through all of them. A Scatter keeps
+	// only the first of the points in each pixel.
+	MinMaxDownsampling
+
+	// LTTBDownsampling keeps one point for each pixel column
+	// spanned by the points, chosen by the largest-triangle-
+	// three-buckets algorithm to preserve the visual shape
+	// of the points.
+	LTTBDownsampling
+)
+
+// resolution returns res if it is positive, otherwise the
+// number of pixels per point of c if its vg.Canvas reports
+// its resolution in dots per inch, otherwise 2.
+func resolution(c draw.Canvas, res float64) float64 {
+	if res > 0 {
+		return res
+	}
+	if d, ok := c.Canvas.(interface {
+		DPI() float64
+	}); ok && d.DPI() > 0 {
+		return d.DPI() / 72
+	}
+	return 2
+}
+
+// downsample returns the indices, in increasing order, of the
+// points of ps kept by the downsampling d at the resolution res
+// in pixels per point. Scatters are downsampled if scatter
+// is true.
+func downsample(ps []draw.Point, d Downsampling, res float64, scatter bool) []int {
+	switch d {
+	case MinMaxDownsampling:
+		if scatter {
+			return pixelDecimate(ps, res)
+		}
+		return minMaxDecimate(ps, res)
+	case LTTBDownsampling:
+		if len(ps) == 0 {
+			return nil
+		}
+		xmin, xmax := ps[0].X, ps[0].X
+		for _, p := range ps[1:] {
+			if p.X < xmin {
+				xmin = p.X
+			}
+			if p.X > xmax {
+				xmax = p.X
+			}
+		}
+		return lttb(ps, int(math.Ceil((xmax-xmin).Points()*res))+1)
+	}
+	idx := make([]int, len(ps))
+	for i := range idx {
+		idx[i] = i
+	}
+	return idx
+}
+
+// pixel returns the index of the pixel
+// at v at the resolution res.
+func pixel(v float64, res float64) int64 {
+	return int64(math.Floor(v * res))
+}
+
+// minMaxDecimate returns the indices of the points of ps
+// kept by MinMaxDownsampling of a line.
+func minMaxDecimate(ps []draw.Point, res float64) []int {
+	var idx []int
+	for i := 0; i < len(ps); {
+		col := pixel(ps[i].X.Points(), res)
+		lo, hi := i, i
+		j := i + 1
+		for ; j < len(ps) && pixel(ps[j].X.Points(), res) == col; j++ {
+			if ps[j].Y < ps[lo].Y {
+				lo = j
+			}
+			if ps[j].Y > ps[hi].Y {
+				hi = j
+			}
+		}
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		for _, k := range [...]int{i, lo, hi, j - 1} {
+			if len(idx) == 0 || idx[len(idx)-1] < k {
+				idx = append(idx, k)
+			}
+		}
+		i = j
+	}
+	return idx
+}
+
+// pixelDecimate returns the indices of the points of ps
+// kept by MinMaxDownsampling of a scatter.
+func pixelDecimate(ps []draw.Point, res float64) []int {
+	var idx []int
+	seen := make(map[[2]int64]bool)
+	for i, p := range ps {
+		px := [2]int64{pixel(p.X.Points(), res), pixel(p.Y.Points(), res)}
+		if !seen[px] {
+			seen[px] = true
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// lttb returns the indices of n of the points of ps chosen
+// by the largest-triangle-three-buckets algorithm. The first
+// and last points are always kept, and the others are divided
+// into n-2 buckets, from each of which the point forming the
+// largest triangle with the point kept from the previous bucket
+// and the mean of the points of the next bucket is kept.
+func lttb(ps []draw.Point, n int) []int {
+	if n >= len(ps) {
+		n = len(ps)
+	}
+	if n < 2 {
+		n = 2
+	}
+	idx := make([]int, 0, n)
+	if n >= len(ps) || len(ps) < 3 {
+		for i := range ps {
+			idx = append(idx, i)
+		}
+		return idx
+	}
+
+	size := float64(len(ps)-2) / float64(n-2)
+	bucket := func(i int) (lo, hi int) {
+		lo = int(float64(i)*size) + 1
+		hi = int(float64(i+1)*size) + 1
+		if i == n-3 {
+			hi = len(ps) - 1
+		}
+		return lo, hi
+	}
+	idx = append(idx, 0)
+	a := 0
+	for i := 0; i < n-2; i++ {
+		// The mean of the next bucket, which for
+		// the last bucket is the last point.
+		var mx, my float64
+		lo, hi := bucket(i + 1)
+		if i == n-3 {
+			lo, hi = len(ps)-1, len(ps)
+		}
+		for _, p := range ps[lo:hi] {
+			mx += p.X.Points()
+			my += p.Y.Points()
+		}
+		mx /= float64(hi - lo)
+		my /= float64(hi - lo)
+
+		ax, ay := ps[a].X.Points(), ps[a].Y.Points()
+		lo, hi = bucket(i)
+		best, area := lo, -1.0
+		for j := lo; j < hi; j++ {
+			bx, by := ps[j].X.Points(), ps[j].Y.Points()
+			s := math.Abs((ax-mx)*(by-ay) - (ax-bx)*(my-ay))
+			if s > area {
+				best, area = j, s
+			}
+		}
+		idx = append(idx, best)
+		a = best
+	}
+	return append(idx, len(ps)-1)
+}
@@ -0,0 +1,101 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plotter
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/recorder"
+)
+
+func points(xys ...float64) []draw.Point {
+	ps := make([]draw.Point, len(xys)/2)
+	for i := range ps {
+		ps[i] = draw.Point{X: vg.Length(xys[2*i]), Y: vg.Length(xys[2*i+1])}
+	}
+	return ps
+}
+
+func TestMinMaxDecimate(t *testing.T) {
+	ps := points(
+		0, 5, 0.2, 9, 0.4, 1, 0.6, 4, 0.8, 3,
+		1.1, 2,
+		2, 0, 2.5, 7,
+	)
+	want := []int{0, 1, 2, 4, 5, 6, 7}
+	if got := minMaxDecimate(ps, 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected indices: got:%v want:%v", got, want)
+	}
+
+	want = []int{0, 1, 3, 4, 6, 7}
+	if got := pixelDecimate(points(0, 0, 1, 1, 0.5, 0.5, 1.5, 0.5, 3, 3, 1.2, 1.9, 5, 5, 5, 6), 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected scatter indices: got:%v want:%v", got, want)
+	}
+}
+
+func TestLTTB(t *testing.T) {
+	ps := points(0, 0, 1, 0, 2, 10, 3, 0, 4, 0, 5, -10, 6, 0, 7, 0)
+	want := []int{0, 2, 5, 7}
+	if got := lttb(ps, 4); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected indices: got:%v want:%v", got, want)
+	}
+	for _, n := range []int{0, 2, 8, 20} {
+		got := lttb(ps, n)
+		wantLen := n
+		if n < 2 {
+			wantLen = 2
+		} else if n > len(ps) {
+			wantLen = len(ps)
+		}
+		if len(got) != wantLen || got[0] != 0 || got[len(got)-1] != len(ps)-1 {
+			t.Errorf("unexpected indices for n=%d: %v", n, got)
+		}
+	}
+}
+
+func TestLineDownsampling(t *testing.T) {
+	xys := make(XYs, 100000)
+	for i := range xys {
+		xys[i].X = float64(i)
+		xys[i].Y = math.Sin(float64(i) / 100)
+	}
+	for _, test := range []struct {
+		d   Downsampling
+		max int
+	}{
+		{d: NoDownsampling, max: len(xys)},
+		{d: MinMaxDownsampling, max: 4 * 201},
+		{d: LTTBDownsampling, max: 201},
+	} {
+		p, err := plot.New()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		l, err := NewLine(xys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		l.Downsampling = test.d
+		l.Resolution = 1
+		p.Add(l)
+
+		var r recorder.Canvas
+		p.Draw(draw.NewCanvas(&r, 200, 200))
+		var n int
+		for _, a := range r.Actions {
+			if s, ok := a.(*recorder.Stroke); ok && len(s.Path) > n {
+				n = len(s.Path)
+			}
+		}
+		if n > test.max || n < test.max/8 {
+			t.Errorf("unexpected number of vertices for downsampling %d: got:%d want at most:%d", test.d, n, test.max)
+		}
+	}
+}
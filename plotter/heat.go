@@ -5,8 +5,10 @@
 package plotter
 
 import (
+	"image"
 	"image/color"
 	"math"
+	"sort"
 
 	"github.com/gonum/plot"
 	"github.com/gonum/plot/palette"
@@ -52,8 +54,55 @@ type HeatMap struct {
 	// Min and Max define the dynamic range of the
 	// heat map.
 	Min, Max float64
+
+	// Rasterization specifies whether the heat map
+	// is drawn as a single image rather than as a
+	// filled rectangle for each grid cell.
+	Rasterization Rasterization
+
+	// Interpolation specifies how the colors of the
+	// image of a rasterized heat map are chosen.
+	Interpolation Interpolation
+
+	// Resolution is the number of image pixels per
+	// point of a rasterized heat map. If Resolution
+	// is not positive, 2 pixels per point are used.
+	Resolution float64
 }
 
+// Rasterization specifies when a HeatMap is drawn as an image.
+type Rasterization int
+
+const (
+	// RasterAuto draws the heat map as an image when
+	// its grid has more cells than the image has pixels,
+	// or when the heat map is interpolated bilinearly.
+	RasterAuto Rasterization = iota
+
+	// RasterAlways always draws the heat map as an image.
+	RasterAlways
+
+	// RasterNever always draws the heat map as a filled
+	// rectangle for each cell. The heat map is not
+	// interpolated.
+	RasterNever
+)
+
+// Interpolation specifies how the image of a HeatMap shows
+// the values between the centers of the grid cells.
+type Interpolation int
+
+const (
+	// NearestInterpolation fills each grid cell
+	// with the color of its value.
+	NearestInterpolation Interpolation = iota
+
+	// BilinearInterpolation colors each pixel by the
+	// value interpolated bilinearly between the centers
+	// of the grid cells around it.
+	BilinearInterpolation
+)
+
 // NewHeatMap creates as new heat map plotter for the given data,
 // using the provided palette. If g has Min and Max methods that return
 // a float, those returned values are used to set the respective HeatMap
@@ -96,13 +145,20 @@ func (h *HeatMap) Plot(c draw.Canvas, plt *plot.Plot) {
 	if len(pal) == 0 {
 		panic("heatmap: empty palette")
 	}
-	// ps scales the palette uniformly across the data range.
-	ps := float64(len(pal)-1) / (h.Max - h.Min)
 
 	trX, trY := plt.Transforms(&c)
 
-	var pa vg.Path
 	cols, rows := h.GridXYZ.Dims()
+	if h.Rasterization != RasterNever {
+		img, rect, ok := h.raster(c, trX, trY, pal)
+		if ok && (h.Rasterization == RasterAlways || h.Interpolation == BilinearInterpolation ||
+			cols*rows > img.Bounds().Dx()*img.Bounds().Dy()) {
+			c.DrawImage(rect.Min.X, rect.Min.Y, rect.Size().X, rect.Size().Y, img)
+			return
+		}
+	}
+
+	var pa vg.Path
 	for i := 0; i < cols; i++ {
 
 		var right, left float64
@@ -152,16 +208,7 @@ func (h *HeatMap) Plot(c draw.Canvas, plt *plot.Plot) {
 			pa.Line(x, dy)
 			pa.Close()
 
-			var col color.Color
-			switch {
-			case v < h.Min:
-				col = h.Underflow
-			case v > h.Max:
-				col = h.Overflow
-			default:
-				col = pal[int((v-h.Min)*ps+0.5)] // Apply palette scaling.
-			}
-			if col != nil {
+			if col := h.color(v, pal); col != nil {
 				c.SetColor(col)
 				c.Fill(pa)
 			}
@@ -169,6 +216,179 @@ func (h *HeatMap) Plot(c draw.Canvas, plt *plot.Plot) {
 	}
 }
 
+// color returns the color of the value v drawn using the
+// palette colors pal, or nil if the value is not drawn.
+func (h *HeatMap) color(v float64, pal []color.Color) color.Color {
+	switch {
+	case math.IsNaN(v) || math.IsInf(v, 0):
+		return nil
+	case v < h.Min:
+		return h.Underflow
+	case v > h.Max:
+		return h.Overflow
+	}
+	// ps scales the palette uniformly across the data range.
+	ps := float64(len(pal)-1) / (h.Max - h.Min)
+	return pal[int((v-h.Min)*ps+0.5)]
+}
+
+// raster returns the image of the heat map drawn on c using
+// the transforms trX and trY, and the rectangle of c that it
+// fills. It returns false if no part of the heat map is on c.
+func (h *HeatMap) raster(c draw.Canvas, trX, trY func(float64) vg.Length, pal []color.Color) (*image.NRGBA, draw.Rectangle, bool) {
+	cols, rows := h.GridXYZ.Dims()
+	xs := newRasterAxis(cols, h.GridXYZ.X, trX)
+	ys := newRasterAxis(rows, h.GridXYZ.Y, trY)
+
+	var rect draw.Rectangle
+	rect.Min.X, rect.Max.X = xs.extent(c.Min.X, c.Max.X)
+	rect.Min.Y, rect.Max.Y = ys.extent(c.Min.Y, c.Max.Y)
+	if rect.Min.X >= rect.Max.X || rect.Min.Y >= rect.Max.Y {
+		return nil, rect, false
+	}
+
+	res := h.Resolution
+	if res <= 0 {
+		res = 2
+	}
+	size := rect.Size()
+	w := int(math.Ceil(size.X.Points() * res))
+	ht := int(math.Ceil(size.Y.Points() * res))
+	img := image.NewNRGBA(image.Rect(0, 0, w, ht))
+
+	px := make([]rasterPixel, w)
+	for i := range px {
+		px[i] = xs.pixel(rect.Min.X + (vg.Length(i)+0.5)*size.X/vg.Length(w))
+	}
+	for j := 0; j < ht; j++ {
+		py := ys.pixel(rect.Max.Y - (vg.Length(j)+0.5)*size.Y/vg.Length(ht))
+		if py.cell < 0 {
+			continue
+		}
+		for i, px := range px {
+			if px.cell < 0 {
+				continue
+			}
+			v := h.GridXYZ.Z(px.cell, py.cell)
+			if h.Interpolation == BilinearInterpolation {
+				v = h.bilinear(px, py, v)
+			}
+			if col := h.color(v, pal); col != nil {
+				img.Set(i, j, col)
+			}
+		}
+	}
+	return img, rect, true
+}
+
+// bilinear returns the value of the heat map interpolated
+// bilinearly at the pixel at x and y. If any of the values
+// around the pixel are not finite, the value of the cell
+// v is returned.
+func (h *HeatMap) bilinear(x, y rasterPixel, v float64) float64 {
+	z00 := h.GridXYZ.Z(x.i0, y.i0)
+	z10 := h.GridXYZ.Z(x.i1, y.i0)
+	z01 := h.GridXYZ.Z(x.i0, y.i1)
+	z11 := h.GridXYZ.Z(x.i1, y.i1)
+	z := (z00*(1-x.t)+z10*x.t)*(1-y.t) + (z01*(1-x.t)+z11*x.t)*y.t
+	if math.IsNaN(z) || math.IsInf(z, 0) {
+		return v
+	}
+	return z
+}
+
+// rasterAxis holds the positions on the canvas of the cell
+// edges and centers along an axis of a grid. The positions
+// are negated if they decrease with the cell index, so that
+// they always increase.
+type rasterAxis struct {
+	sign    vg.Length
+	edges   []vg.Length
+	centers []vg.Length
+}
+
+// rasterPixel describes the cells of a grid along an axis
+// under the center of a pixel: cell is the cell under the
+// center, or -1 if there is none, and the value at the
+// center is interpolated with weight t between the values
+// at the centers of the cells i0 and i1.
+type rasterPixel struct {
+	cell   int
+	i0, i1 int
+	t      float64
+}
+
+// newRasterAxis returns the rasterAxis of n cells centered at
+// the coordinates given by coord, transformed to the canvas
+// by tr.
+func newRasterAxis(n int, coord func(int) float64, tr func(float64) vg.Length) rasterAxis {
+	a := rasterAxis{
+		sign:    1,
+		edges:   make([]vg.Length, n+1),
+		centers: make([]vg.Length, n),
+	}
+	for i := range a.centers {
+		a.centers[i] = tr(coord(i))
+	}
+	if n == 1 {
+		a.edges[0], a.edges[1] = tr(coord(0)-0.5), tr(coord(0)+0.5)
+	} else {
+		a.edges[0] = tr((3*coord(0) - coord(1)) / 2)
+		for i := 1; i < n; i++ {
+			a.edges[i] = tr((coord(i-1) + coord(i)) / 2)
+		}
+		a.edges[n] = tr((3*coord(n-1) - coord(n-2)) / 2)
+	}
+	if a.edges[n] < a.edges[0] {
+		a.sign = -1
+		for i := range a.edges {
+			a.edges[i] = -a.edges[i]
+		}
+		for i := range a.centers {
+			a.centers[i] = -a.centers[i]
+		}
+	}
+	return a
+}
+
+// extent returns the extent of the grid on the
+// canvas, clipped to the range from min to max.
+func (a rasterAxis) extent(min, max vg.Length) (lo, hi vg.Length) {
+	lo, hi = a.sign*a.edges[0], a.sign*a.edges[len(a.edges)-1]
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	if lo < min {
+		lo = min
+	}
+	if hi > max {
+		hi = max
+	}
+	return lo, hi
+}
+
+// pixel returns the rasterPixel of a pixel
+// centered at p on the canvas.
+func (a rasterAxis) pixel(p vg.Length) rasterPixel {
+	p *= a.sign
+	n := len(a.centers)
+	px := rasterPixel{cell: sort.Search(n+1, func(i int) bool { return a.edges[i] > p }) - 1}
+	if px.cell >= n {
+		px.cell = -1
+	}
+	k := sort.Search(n, func(i int) bool { return a.centers[i] > p }) - 1
+	switch {
+	case k < 0:
+		px.i0, px.i1 = 0, 0
+	case k >= n-1:
+		px.i0, px.i1 = n-1, n-1
+	default:
+		px.i0, px.i1 = k, k+1
+		px.t = float64((p - a.centers[k]) / (a.centers[k+1] - a.centers[k]))
+	}
+	return px
+}
+
 // DataRange implements the DataRange method
 // of the plot.DataRanger interface.
 func (h *HeatMap) DataRange() (xmin, xmax, ymin, ymax float64) {
@@ -211,3 +431,15 @@ func (h *HeatMap) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
 	}
 	return b
 }
+
+// ColorBar returns a color bar showing the palette
+// of the heat map across its range from Min to Max.
+func (h *HeatMap) ColorBar() (*plot.ColorBar, error) {
+	b, err := plot.NewColorBar(h.Palette, h.Min, h.Max)
+	if err != nil {
+		return nil, err
+	}
+	b.Underflow = h.Underflow
+	b.Overflow = h.Overflow
+	return b, nil
+}
@@ -0,0 +1,116 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plotter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/palette"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/recorder"
+)
+
+// offsetGrid is a grid of c columns and r rows at unit spacing
+// with the value c+cols*r in the cell at column c and row r.
+type offsetGrid struct{ cols, rows int }
+
+func (g offsetGrid) Dims() (c, r int)   { return g.cols, g.rows }
+func (g offsetGrid) Z(c, r int) float64 { return float64(c + g.cols*r) }
+func (g offsetGrid) X(c int) float64    { return float64(c) }
+func (g offsetGrid) Y(r int) float64    { return float64(r) }
+
+// drawHeatMap draws h on a 100×100 point canvas and returns
+// the recorded actions.
+func drawHeatMap(t *testing.T, h *HeatMap) []recorder.Action {
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("failed to create plot: %v", err)
+	}
+	p.X.Min, p.X.Max, p.Y.Min, p.Y.Max = h.DataRange()
+	var rec recorder.Canvas
+	c := draw.Canvas{Canvas: &rec, Rectangle: draw.Rectangle{Max: draw.Point{X: 100, Y: 100}}}
+	h.Plot(c, p)
+	return rec.Actions
+}
+
+func TestHeatMapRaster(t *testing.T) {
+	g := offsetGrid{cols: 3, rows: 2}
+	pal := palette.Heat(6, 1)
+	h := NewHeatMap(g, pal)
+	h.Rasterization = RasterAlways
+	h.Resolution = 0.06
+
+	actions := drawHeatMap(t, h)
+	if len(actions) != 1 {
+		t.Fatalf("unexpected number of actions: got:%d want:1", len(actions))
+	}
+	a, ok := actions[0].(*recorder.DrawImage)
+	if !ok {
+		t.Fatalf("unexpected action: got:%s want:DrawImage", actions[0].Call())
+	}
+	if a.X != 0 || a.Y != 0 || a.W != 100 || a.H != 100 {
+		t.Errorf("unexpected image rectangle: got:%v %v %v %v", a.X, a.Y, a.W, a.H)
+	}
+	if b := a.Image.Bounds(); b != image.Rect(0, 0, 6, 6) {
+		t.Fatalf("unexpected image bounds: got:%v", b)
+	}
+	for j := 0; j < 6; j++ {
+		for i := 0; i < 6; i++ {
+			c, r := i/2, 1-j/3
+			want := color.NRGBAModel.Convert(pal.Colors()[int(g.Z(c, r))])
+			if got := a.Image.At(i, j); got != want {
+				t.Errorf("unexpected color of pixel (%d, %d): got:%v want:%v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestHeatMapRasterAuto(t *testing.T) {
+	h := NewHeatMap(offsetGrid{cols: 3, rows: 2}, palette.Heat(6, 1))
+	actions := drawHeatMap(t, h)
+	fills := 0
+	for _, a := range actions {
+		switch a.(type) {
+		case *recorder.Fill:
+			fills++
+		case *recorder.DrawImage:
+			t.Errorf("unexpected image drawn for large cells")
+		}
+	}
+	if fills != 6 {
+		t.Errorf("unexpected number of cells filled: got:%d want:6", fills)
+	}
+
+	h = NewHeatMap(offsetGrid{cols: 300, rows: 300}, palette.Heat(6, 1))
+	actions = drawHeatMap(t, h)
+	if len(actions) != 1 {
+		t.Fatalf("unexpected number of actions for small cells: got:%d want:1", len(actions))
+	}
+	if _, ok := actions[0].(*recorder.DrawImage); !ok {
+		t.Errorf("unexpected action for small cells: got:%s want:DrawImage", actions[0].Call())
+	}
+}
+
+func TestHeatMapBilinear(t *testing.T) {
+	h := NewHeatMap(offsetGrid{cols: 3, rows: 2}, palette.Heat(51, 1))
+	h.Interpolation = BilinearInterpolation
+	h.Resolution = 0.2
+	actions := drawHeatMap(t, h)
+	if len(actions) != 1 {
+		t.Fatalf("unexpected number of actions: got:%d want:1", len(actions))
+	}
+	img := actions[0].(*recorder.DrawImage).Image
+	b := img.Bounds()
+	seen := make(map[color.Color]bool)
+	for i := b.Min.X; i < b.Max.X; i++ {
+		seen[img.At(i, b.Max.Y-1)] = true
+	}
+	if len(seen) <= 3 {
+		t.Errorf("colors not interpolated: got %d colors in row of 3 cells", len(seen))
+	}
+}
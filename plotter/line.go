@@ -6,6 +6,7 @@ package plotter
 
 import (
 	"image/color"
+	"math"
 
 	"github.com/gonum/plot"
 	"github.com/gonum/plot/vg"
@@ -23,8 +24,66 @@ type Line struct {
 
 	// ShadeColor is the color of the shaded area.
 	ShadeColor *color.Color
+
+	// FillTo, if not nil, is a copy of the points of
+	// the lower boundary of the shaded area, which is
+	// then shaded between the line and the line through
+	// FillTo, joined in the same way as the line.
+	FillTo XYs
+
+	// Baseline, if not nil and FillTo is nil, is the y
+	// value down to which the area under the line is
+	// shaded. If Baseline is nil, the area is shaded
+	// down to the minimum of the Y axis.
+	Baseline *float64
+
+	// Interpolation specifies how the points
+	// of the line are joined.
+	Interpolation LineInterpolation
+
+	// Downsampling specifies how the points of the
+	// line are reduced before it is drawn.
+	Downsampling Downsampling
+
+	// Resolution is the number of pixels per point
+	// used to downsample the line. If Resolution is
+	// not positive, the resolution of the canvas is
+	// used if it has one, otherwise 2 pixels per point.
+	Resolution float64
 }
 
+// LineInterpolation specifies how the points of a Line are joined.
+type LineInterpolation int
+
+const (
+	// StraightLine joins the points with straight segments.
+	StraightLine LineInterpolation = iota
+
+	// PreStep joins the points with steps, each
+	// rising or falling at the x of the earlier point.
+	PreStep
+
+	// MidStep joins the points with steps, each rising
+	// or falling half way between the points.
+	MidStep
+
+	// PostStep joins the points with steps, each
+	// rising or falling at the x of the later point.
+	PostStep
+
+	// MonotoneCubic joins the points with a monotone
+	// cubic spline, which does not overshoot the points
+	// so that the curve is monotone wherever the points
+	// are. If the x values of the points are not strictly
+	// increasing, the points are joined with straight
+	// segments.
+	MonotoneCubic
+
+	// CatmullRom joins the points with
+	// a uniform Catmull-Rom spline.
+	CatmullRom
+)
+
 // NewLine returns a Line that uses the default line style and
 // does not draw glyphs.
 func NewLine(xys XYer) (*Line, error) {
@@ -38,26 +97,52 @@ func NewLine(xys XYer) (*Line, error) {
 	}, nil
 }
 
+// NewFillBetween returns a Line through the points of
+// a that uses the default line style, with the area
+// between it and the line through the points of b
+// shaded with the given color.
+func NewFillBetween(a, b XYer, shade color.Color) (*Line, error) {
+	l, err := NewLine(a)
+	if err != nil {
+		return nil, err
+	}
+	l.FillTo, err = CopyXYs(b)
+	if err != nil {
+		return nil, err
+	}
+	l.ShadeColor = &shade
+	return l, nil
+}
+
 // Plot draws the Line, implementing the plot.Plotter
 // interface.
 func (pts *Line) Plot(c draw.Canvas, plt *plot.Plot) {
 	trX, trY := plt.Transforms(&c)
-	ps := make([]draw.Point, len(pts.XYs))
-
-	for i, p := range pts.XYs {
-		ps[i].X = trX(p.X)
-		ps[i].Y = trY(p.Y)
-	}
+	ps := pts.points(c, pts.XYs, trX, trY)
 
 	if pts.ShadeColor != nil && len(ps) > 0 {
 		c.SetColor(*pts.ShadeColor)
-		minY := trY(plt.Y.Min)
 		var pa vg.Path
-		pa.Move(ps[0].X, minY)
-		for i := range pts.XYs {
-			pa.Line(ps[i].X, ps[i].Y)
+		if pts.FillTo != nil {
+			pa.Move(ps[0].X, ps[0].Y)
+			for _, p := range ps[1:] {
+				pa.Line(p.X, p.Y)
+			}
+			lower := pts.points(c, pts.FillTo, trX, trY)
+			for i := len(lower) - 1; i >= 0; i-- {
+				pa.Line(lower[i].X, lower[i].Y)
+			}
+		} else {
+			minY := trY(plt.Y.Min)
+			if pts.Baseline != nil {
+				minY = trY(*pts.Baseline)
+			}
+			pa.Move(ps[0].X, minY)
+			for _, p := range ps {
+				pa.Line(p.X, p.Y)
+			}
+			pa.Line(ps[len(ps)-1].X, minY)
 		}
-		pa.Line(ps[len(pts.XYs)-1].X, minY)
 		pa.Close()
 		c.Fill(pa)
 	}
@@ -65,31 +150,217 @@ func (pts *Line) Plot(c draw.Canvas, plt *plot.Plot) {
 	c.StrokeLines(pts.LineStyle, c.ClipLinesXY(ps)...)
 }
 
+// points returns the points of the line through xys on the
+// canvas c, downsampled and joined as specified by the Line.
+func (pts *Line) points(c draw.Canvas, xys XYs, trX, trY func(float64) vg.Length) []draw.Point {
+	ps := make([]draw.Point, len(xys))
+	for i, p := range xys {
+		ps[i].X = trX(p.X)
+		ps[i].Y = trY(p.Y)
+	}
+	if pts.Downsampling != NoDownsampling {
+		idx := downsample(ps, pts.Downsampling, resolution(c, pts.Resolution), false)
+		for i, j := range idx {
+			ps[i] = ps[j]
+		}
+		ps = ps[:len(idx)]
+	}
+	return interpolate(ps, pts.Interpolation)
+}
+
 // DataRange returns the minimum and maximum
 // x and y values, implementing the plot.DataRanger
 // interface.
 func (pts *Line) DataRange() (xmin, xmax, ymin, ymax float64) {
-	return XYRange(pts)
+	xmin, xmax, ymin, ymax = XYRange(pts)
+	if pts.ShadeColor == nil {
+		return xmin, xmax, ymin, ymax
+	}
+	if pts.FillTo != nil {
+		fxmin, fxmax, fymin, fymax := XYRange(pts.FillTo)
+		xmin = math.Min(xmin, fxmin)
+		xmax = math.Max(xmax, fxmax)
+		ymin = math.Min(ymin, fymin)
+		ymax = math.Max(ymax, fymax)
+	} else if pts.Baseline != nil {
+		ymin = math.Min(ymin, *pts.Baseline)
+		ymax = math.Max(ymax, *pts.Baseline)
+	}
+	return xmin, xmax, ymin, ymax
 }
 
 // Thumbnail the thumbnail for the Line,
 // implementing the plot.Thumbnailer interface.
 func (pts *Line) Thumbnail(c *draw.Canvas) {
+	if pts.Interpolation == StraightLine && pts.FillTo == nil {
+		if pts.ShadeColor != nil {
+			points := []draw.Point{
+				{c.Min.X, c.Min.Y},
+				{c.Min.X, c.Max.Y},
+				{c.Max.X, c.Max.Y},
+				{c.Max.X, c.Min.Y},
+			}
+			poly := c.ClipPolygonY(points)
+			c.FillPolygon(*pts.ShadeColor, poly)
+
+			points = append(points, draw.Point{c.Min.X, c.Min.Y})
+		} else {
+			y := c.Center().Y
+			c.StrokeLine2(pts.LineStyle, c.Min.X, y, c.Max.X, y)
+		}
+		return
+	}
+
+	// The thumbnail shows the line joining a rise
+	// and a fall across the canvas.
+	h := c.Size().Y / 4
+	y := c.Center().Y
+	shape := func(dy vg.Length) []draw.Point {
+		return interpolate([]draw.Point{
+			{X: c.Min.X, Y: y - h + dy},
+			{X: c.Center().X, Y: y + h + dy},
+			{X: c.Max.X, Y: y - h + dy},
+		}, pts.Interpolation)
+	}
+	ps := shape(0)
 	if pts.ShadeColor != nil {
-		points := []draw.Point{
-			{c.Min.X, c.Min.Y},
-			{c.Min.X, c.Max.Y},
-			{c.Max.X, c.Max.Y},
-			{c.Max.X, c.Min.Y},
+		poly := append([]draw.Point(nil), ps...)
+		if pts.FillTo != nil {
+			lower := shape(-h)
+			for i := len(lower) - 1; i >= 0; i-- {
+				poly = append(poly, lower[i])
+			}
+		} else {
+			poly = append(poly, draw.Point{X: c.Max.X, Y: c.Min.Y}, draw.Point{X: c.Min.X, Y: c.Min.Y})
+		}
+		c.FillPolygon(*pts.ShadeColor, c.ClipPolygonY(poly))
+	}
+	c.StrokeLines(pts.LineStyle, c.ClipLinesY(ps)...)
+}
+
+// interpolate returns the points of the
+// line through ps joined as specified by li.
+func interpolate(ps []draw.Point, li LineInterpolation) []draw.Point {
+	if len(ps) < 2 {
+		return ps
+	}
+	switch li {
+	case PreStep, MidStep, PostStep:
+		steps := []draw.Point{ps[0]}
+		for i, p := range ps[1:] {
+			q := ps[i]
+			switch li {
+			case PreStep:
+				steps = append(steps, draw.Point{X: q.X, Y: p.Y})
+			case MidStep:
+				x := (q.X + p.X) / 2
+				steps = append(steps, draw.Point{X: x, Y: q.Y}, draw.Point{X: x, Y: p.Y})
+			case PostStep:
+				steps = append(steps, draw.Point{X: p.X, Y: q.Y})
+			}
+			steps = append(steps, p)
+		}
+		return steps
+	case MonotoneCubic:
+		return monotoneCubic(ps)
+	case CatmullRom:
+		return catmullRom(ps)
+	}
+	return ps
+}
+
+// curveSteps returns the number of straight segments
+// used to draw a curve from a to b.
+func curveSteps(a, b draw.Point) int {
+	n := int(math.Ceil(math.Hypot(float64(b.X-a.X), float64(b.Y-a.Y)) / 2))
+	if n < 1 {
+		return 1
+	}
+	if n > 64 {
+		return 64
+	}
+	return n
+}
+
+// monotoneCubic returns the points of the monotone cubic
+// spline through ps, found by the Fritsch-Carlson method.
+func monotoneCubic(ps []draw.Point) []draw.Point {
+	n := len(ps)
+	d := make([]float64, n-1)
+	for i := range d {
+		dx := ps[i+1].X - ps[i].X
+		if dx <= 0 {
+			return ps
 		}
-		poly := c.ClipPolygonY(points)
-		c.FillPolygon(*pts.ShadeColor, poly)
+		d[i] = float64((ps[i+1].Y - ps[i].Y) / dx)
+	}
 
-		points = append(points, draw.Point{c.Min.X, c.Min.Y})
-	} else {
-		y := c.Center().Y
-		c.StrokeLine2(pts.LineStyle, c.Min.X, y, c.Max.X, y)
+	m := make([]float64, n)
+	m[0], m[n-1] = d[0], d[n-2]
+	for i := 1; i < n-1; i++ {
+		if d[i-1]*d[i] > 0 {
+			m[i] = (d[i-1] + d[i]) / 2
+		}
+	}
+	for i, dk := range d {
+		if dk == 0 {
+			m[i], m[i+1] = 0, 0
+			continue
+		}
+		a, b := m[i]/dk, m[i+1]/dk
+		if s := a*a + b*b; s > 9 {
+			t := 3 / math.Sqrt(s)
+			m[i], m[i+1] = t*a*dk, t*b*dk
+		}
+	}
+
+	curve := []draw.Point{ps[0]}
+	for i := range d {
+		p, q := ps[i], ps[i+1]
+		h := float64(q.X - p.X)
+		steps := curveSteps(p, q)
+		for j := 1; j <= steps; j++ {
+			t := float64(j) / float64(steps)
+			t2, t3 := t*t, t*t*t
+			y := (2*t3-3*t2+1)*float64(p.Y) + (t3-2*t2+t)*h*m[i] +
+				(-2*t3+3*t2)*float64(q.Y) + (t3-t2)*h*m[i+1]
+			curve = append(curve, draw.Point{X: p.X + vg.Length(t*h), Y: vg.Length(y)})
+		}
+	}
+	return curve
+}
+
+// catmullRom returns the points of the uniform Catmull-Rom
+// spline through ps, the end points being repeated to give
+// the tangents at the ends.
+func catmullRom(ps []draw.Point) []draw.Point {
+	at := func(i int) draw.Point {
+		if i < 0 {
+			i = 0
+		}
+		if i >= len(ps) {
+			i = len(ps) - 1
+		}
+		return ps[i]
+	}
+	curve := []draw.Point{ps[0]}
+	for i := 0; i < len(ps)-1; i++ {
+		p0, p1, p2, p3 := at(i-1), at(i), at(i+1), at(i+2)
+
+		// The control points of the equivalent Bézier curve.
+		c1 := draw.Point{X: p1.X + (p2.X-p0.X)/6, Y: p1.Y + (p2.Y-p0.Y)/6}
+		c2 := draw.Point{X: p2.X - (p3.X-p1.X)/6, Y: p2.Y - (p3.Y-p1.Y)/6}
+		steps := curveSteps(p1, p2)
+		for j := 1; j <= steps; j++ {
+			t := vg.Length(j) / vg.Length(steps)
+			u := 1 - t
+			curve = append(curve, draw.Point{
+				X: u*u*u*p1.X + 3*u*u*t*c1.X + 3*u*t*t*c2.X + t*t*t*p2.X,
+				Y: u*u*u*p1.Y + 3*u*u*t*c1.Y + 3*u*t*t*c2.Y + t*t*t*p2.Y,
+			})
+		}
 	}
+	return curve
 }
 
 // NewLinePoints returns both a Line and a
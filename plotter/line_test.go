@@ -0,0 +1,143 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plotter
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/recorder"
+)
+
+func TestInterpolateSteps(t *testing.T) {
+	ps := points(0, 0, 2, 1, 4, 3)
+	for _, test := range []struct {
+		li   LineInterpolation
+		want []draw.Point
+	}{
+		{li: StraightLine, want: ps},
+		{li: PreStep, want: points(0, 0, 0, 1, 2, 1, 2, 3, 4, 3)},
+		{li: MidStep, want: points(0, 0, 1, 0, 1, 1, 2, 1, 3, 1, 3, 3, 4, 3)},
+		{li: PostStep, want: points(0, 0, 2, 0, 2, 1, 4, 1, 4, 3)},
+	} {
+		if got := interpolate(ps, test.li); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected points for interpolation %d: got:%v want:%v", test.li, got, test.want)
+		}
+	}
+}
+
+func TestInterpolateSplines(t *testing.T) {
+	ps := points(0, 0, 10, 10, 20, 10, 30, 40, 40, 41)
+	for _, li := range []LineInterpolation{MonotoneCubic, CatmullRom} {
+		curve := interpolate(ps, li)
+		if len(curve) <= len(ps) {
+			t.Errorf("too few points for interpolation %d: %d", li, len(curve))
+		}
+		// The curve passes through the points.
+		var next int
+		for _, p := range curve {
+			if next < len(ps) && p == ps[next] {
+				next++
+			}
+		}
+		if next != len(ps) {
+			t.Errorf("curve for interpolation %d misses point %v", li, ps[next])
+		}
+	}
+
+	// A monotone cubic does not overshoot the points.
+	const tol = 1e-9
+	curve := monotoneCubic(ps)
+	for i, p := range curve[1:] {
+		if p.Y < curve[i].Y-tol || p.Y > 41+tol {
+			t.Errorf("monotone cubic not monotone at %v after %v", p, curve[i])
+		}
+	}
+
+	// Points that are not ordered by x are joined with straight segments.
+	ps = points(0, 0, 2, 1, 1, 3)
+	if got := monotoneCubic(ps); !reflect.DeepEqual(got, ps) {
+		t.Errorf("unexpected points for unordered x: got:%v want:%v", got, ps)
+	}
+}
+
+func TestFillBetween(t *testing.T) {
+	upper := XYs{{0, 2}, {1, 3}, {2, 2}}
+	lower := XYs{{0, 0}, {2, -1}}
+	l, err := NewFillBetween(upper, lower, color.Gray{128})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l.Interpolation = PostStep
+	if xmin, xmax, ymin, ymax := l.DataRange(); xmin != 0 || xmax != 2 || ymin != -1 || ymax != 3 {
+		t.Errorf("unexpected data range: got:[%v, %v]×[%v, %v] want:[0, 2]×[-1, 3]", xmin, xmax, ymin, ymax)
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Add(l)
+	var r recorder.Canvas
+	p.Draw(draw.NewCanvas(&r, 100, 100))
+	var fills []vg.Path
+	for _, a := range r.Actions {
+		if f, ok := a.(*recorder.Fill); ok {
+			fills = append(fills, f.Path)
+		}
+	}
+	// The first fill is the background of the plot.
+	if len(fills) != 2 {
+		t.Fatalf("unexpected number of fills: got:%d want:2", len(fills))
+	}
+	// Five points of the upper stepped line, three of
+	// the lower and the closing component.
+	if n := len(fills[1]); n != 9 {
+		t.Errorf("unexpected number of components of fill: got:%d want:9", n)
+	}
+
+	base := 1.0
+	l = &Line{XYs: upper, ShadeColor: l.ShadeColor, Baseline: &base}
+	if _, _, ymin, ymax := l.DataRange(); ymin != 1 || ymax != 3 {
+		t.Errorf("unexpected y range with baseline: got:[%v, %v] want:[1, 3]", ymin, ymax)
+	}
+}
+
+func TestLineThumbnail(t *testing.T) {
+	shade := color.Color(color.Gray{128})
+	for _, test := range []struct {
+		line   Line
+		fills  int
+		stroke int
+	}{
+		{line: Line{}, stroke: 2},
+		{line: Line{ShadeColor: &shade}, fills: 1},
+		{line: Line{Interpolation: MidStep}, stroke: 7},
+		{line: Line{Interpolation: PostStep, ShadeColor: &shade}, fills: 1, stroke: 5},
+		{line: Line{FillTo: XYs{}, ShadeColor: &shade}, fills: 1, stroke: 3},
+	} {
+		var r recorder.Canvas
+		c := draw.NewCanvas(&r, 20, 10)
+		test.line.LineStyle = DefaultLineStyle
+		test.line.Thumbnail(&c)
+		var fills, stroke int
+		for _, a := range r.Actions {
+			switch a := a.(type) {
+			case *recorder.Fill:
+				fills++
+			case *recorder.Stroke:
+				stroke = len(a.Path)
+			}
+		}
+		if fills != test.fills || stroke != test.stroke {
+			t.Errorf("unexpected thumbnail for interpolation %d: got %d fills and %d stroked points want %d and %d",
+				test.line.Interpolation, fills, stroke, test.fills, test.stroke)
+		}
+	}
+}
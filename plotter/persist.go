@@ -0,0 +1,100 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plotter
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// The gob package does not encode the fields of embedded
+// unexported types, nor unexported links between plotters,
+// so the plotters holding them implement gob.GobEncoder and
+// gob.GobDecoder. The remaining fields are encoded through
+// method-less copies of the plotter types.
+
+type (
+	boxPlotFields   BoxPlot
+	quartPlotFields QuartPlot
+	barChartFields  BarChart
+)
+
+type boxPlotGob struct {
+	Stats  fiveStatPlot
+	Fields boxPlotFields
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (b *BoxPlot) GobEncode() ([]byte, error) {
+	return encode(boxPlotGob{Stats: b.fiveStatPlot, Fields: boxPlotFields(*b)})
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (b *BoxPlot) GobDecode(data []byte) error {
+	var g boxPlotGob
+	err := decode(data, &g)
+	if err != nil {
+		return err
+	}
+	*b = BoxPlot(g.Fields)
+	b.fiveStatPlot = g.Stats
+	return nil
+}
+
+type quartPlotGob struct {
+	Stats  fiveStatPlot
+	Fields quartPlotFields
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (b *QuartPlot) GobEncode() ([]byte, error) {
+	return encode(quartPlotGob{Stats: b.fiveStatPlot, Fields: quartPlotFields(*b)})
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (b *QuartPlot) GobDecode(data []byte) error {
+	var g quartPlotGob
+	err := decode(data, &g)
+	if err != nil {
+		return err
+	}
+	*b = QuartPlot(g.Fields)
+	b.fiveStatPlot = g.Stats
+	return nil
+}
+
+type barChartGob struct {
+	Fields    barChartFields
+	StackedOn *BarChart
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+// A bar chart that is stacked on another is encoded
+// with a copy of the chart it is stacked on.
+func (b *BarChart) GobEncode() ([]byte, error) {
+	return encode(barChartGob{Fields: barChartFields(*b), StackedOn: b.stackedOn})
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (b *BarChart) GobDecode(data []byte) error {
+	var g barChartGob
+	err := decode(data, &g)
+	if err != nil {
+		return err
+	}
+	*b = BarChart(g.Fields)
+	b.stackedOn = g.StackedOn
+	return nil
+}
+
+func encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func decode(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
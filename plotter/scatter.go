@@ -5,7 +5,10 @@
 package plotter
 
 import (
+	"fmt"
+
 	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
 	"github.com/gonum/plot/vg/draw"
 )
 
@@ -18,6 +21,17 @@ type Scatter struct {
 	// GlyphStyle is the style of the glyphs drawn
 	// at each point.
 	draw.GlyphStyle
+
+	// Downsampling specifies how the points of the
+	// scatter are reduced before it is drawn.
+	Downsampling Downsampling
+
+	// Resolution is the number of pixels per point
+	// used to downsample the scatter. If Resolution
+	// is not positive, the resolution of the canvas
+	// is used if it has one, otherwise 2 pixels per
+	// point.
+	Resolution float64
 }
 
 // NewScatter returns a Scatter that uses the
@@ -37,8 +51,21 @@ func NewScatter(xys XYer) (*Scatter, error) {
 // interface.
 func (pts *Scatter) Plot(c draw.Canvas, plt *plot.Plot) {
 	trX, trY := plt.Transforms(&c)
-	for _, p := range pts.XYs {
-		c.DrawGlyph(pts.GlyphStyle, draw.Point{trX(p.X), trY(p.Y)})
+	ps := make([]draw.Point, len(pts.XYs))
+	for i, p := range pts.XYs {
+		ps[i] = draw.Point{X: trX(p.X), Y: trY(p.Y)}
+	}
+	idx := downsample(ps, pts.Downsampling, resolution(c, pts.Resolution), true)
+	annotate := c.Annotates()
+	for _, i := range idx {
+		if annotate {
+			p := pts.XYs[i]
+			c.BeginGroup(vg.Annotation{Class: "point", Title: fmt.Sprintf("%g, %g", p.X, p.Y)})
+		}
+		c.DrawGlyph(pts.GlyphStyle, ps[i])
+		if annotate {
+			c.EndGroup()
+		}
 	}
 }
 
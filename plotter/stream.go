@@ -0,0 +1,373 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plotter
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"image/color"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Ring is a buffer of a fixed number of x, y points that
+// keeps the points most recently appended to it, for plots
+// of data that arrive continuously. Ring implements the
+// XYer and Valuer interfaces, the values being the y
+// values of the points, and is safe for concurrent use.
+//
+// The points are expected to be appended in order of
+// increasing x, as are times.
+type Ring struct {
+	mu     sync.RWMutex
+	xys    XYs
+	start  int
+	n      int
+	window float64
+}
+
+// NewRing returns a new Ring holding at most capacity
+// points. If window is positive, points with x less than
+// that of the last appended point by more than window are
+// dropped, so that the ring holds the points in an x range
+// of width window. For points appended with AppendTime,
+// window is in seconds. NewRing panics if capacity is
+// not positive.
+func NewRing(capacity int, window float64) *Ring {
+	if capacity <= 0 {
+		panic("plotter: non-positive ring capacity")
+	}
+	return &Ring{xys: make(XYs, capacity), window: window}
+}
+
+// Append appends the point x, y to the ring, dropping the
+// oldest point if the ring is full, and the points that are
+// out of its window. Append returns an error, leaving the
+// ring unchanged, if x or y is NaN or infinite.
+func (r *Ring) Append(x, y float64) error {
+	if err := CheckFloats(x, y); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.n == len(r.xys) {
+		r.start = (r.start + 1) % len(r.xys)
+		r.n--
+	}
+	p := &r.xys[(r.start+r.n)%len(r.xys)]
+	p.X, p.Y = x, y
+	r.n++
+	if r.window > 0 {
+		for r.xys[r.start].X < x-r.window {
+			r.start = (r.start + 1) % len(r.xys)
+			r.n--
+		}
+	}
+	return nil
+}
+
+// AppendTime appends the value y at the time t to the ring,
+// with x being the time as given by plot.UnixTime.
+func (r *Ring) AppendTime(t time.Time, y float64) error {
+	return r.Append(plot.UnixTime(t), y)
+}
+
+// Len returns the number of points in the ring.
+func (r *Ring) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.n
+}
+
+// XY returns the ith point in the ring, the
+// points being ordered from oldest to newest.
+func (r *Ring) XY(i int) (x, y float64) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p := r.xys[r.index(i)]
+	return p.X, p.Y
+}
+
+// Value returns the y value of the ith point in the ring.
+func (r *Ring) Value(i int) float64 {
+	_, y := r.XY(i)
+	return y
+}
+
+// index returns the index in r.xys of the ith point.
+func (r *Ring) index(i int) int {
+	if i < 0 || i >= r.n {
+		panic("plotter: ring index out of range")
+	}
+	return (r.start + i) % len(r.xys)
+}
+
+// Snapshot returns a copy of the points in the ring.
+// Unlike calls to Len and XY, which may be interleaved
+// with calls to Append, the copy is consistent.
+func (r *Ring) Snapshot() XYs {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cpy := make(XYs, r.n)
+	for i := range cpy {
+		cpy[i] = r.xys[(r.start+i)%len(r.xys)]
+	}
+	return cpy
+}
+
+// DataRange returns the minimum and maximum x and y values
+// of the points in the ring, implementing the plot.DataRanger
+// interface. If the ring has a window, the x range is the
+// full window ending at the last appended point, so that an
+// axis following it keeps a constant width.
+func (r *Ring) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xys := r.Snapshot()
+	xmin, xmax, ymin, ymax = XYRange(xys)
+	if r.window > 0 && len(xys) > 0 {
+		xmin = math.Min(xmin, xmax-r.window)
+	}
+	return xmin, xmax, ymin, ymax
+}
+
+// ringGob is the gob encoding of a Ring.
+type ringGob struct {
+	Capacity int
+	Window   float64
+	XYs      XYs
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+// The ring is encoded by its capacity, its window and
+// a snapshot of its points.
+func (r *Ring) GobEncode() ([]byte, error) {
+	r.mu.RLock()
+	g := ringGob{Capacity: len(r.xys), Window: r.window}
+	r.mu.RUnlock()
+	g.XYs = r.Snapshot()
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(g)
+	return buf.Bytes(), err
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (r *Ring) GobDecode(b []byte) error {
+	var g ringGob
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&g)
+	if err != nil {
+		return err
+	}
+	if g.Capacity <= 0 || len(g.XYs) > g.Capacity {
+		return errors.New("plotter: invalid ring encoding")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.xys = make(XYs, g.Capacity)
+	r.start = 0
+	r.n = copy(r.xys, g.XYs)
+	r.window = g.Window
+	return nil
+}
+
+// snapshot returns a copy of the points of xys,
+// consistent if xys is a Ring. Points with NaN or
+// infinite values are dropped.
+func snapshot(xys XYer) XYs {
+	if r, ok := xys.(*Ring); ok {
+		return r.Snapshot()
+	}
+	cpy := make(XYs, 0, xys.Len())
+	for i := 0; i < xys.Len(); i++ {
+		x, y := xys.XY(i)
+		if CheckFloats(x, y) == nil {
+			cpy = append(cpy, struct{ X, Y float64 }{x, y})
+		}
+	}
+	return cpy
+}
+
+// sourceRange returns the data range of the points of xys.
+func sourceRange(xys XYer) (xmin, xmax, ymin, ymax float64) {
+	if r, ok := xys.(plot.DataRanger); ok {
+		return r.DataRange()
+	}
+	return XYRange(snapshot(xys))
+}
+
+// StreamLine implements the Plotter interface, drawing a
+// line through the points of a source as they are when the
+// line is plotted, rather than a copy of them taken when
+// the line is made. When the source is a Ring, a plot of
+// the line shows the latest points each time it is drawn.
+type StreamLine struct {
+	// Source is the source of the points of the line.
+	Source XYer
+
+	// LineStyle is the style of the line connecting
+	// the points.
+	draw.LineStyle
+
+	// ShadeColor is the color of the shaded area.
+	ShadeColor *color.Color
+
+	// FillTo, if not nil, is the source of the points
+	// of the lower boundary of the shaded area, which
+	// is then shaded between the line and the line
+	// through FillTo, joined in the same way as the
+	// line.
+	FillTo XYer
+
+	// Baseline, if not nil and FillTo is nil, is the y
+	// value down to which the area under the line is
+	// shaded. If Baseline is nil, the area is shaded
+	// down to the minimum of the Y axis.
+	Baseline *float64
+
+	// Interpolation specifies how the points
+	// of the line are joined.
+	Interpolation LineInterpolation
+
+	// Downsampling specifies how the points of the
+	// line are reduced before it is drawn.
+	Downsampling Downsampling
+
+	// Resolution is the number of pixels per point
+	// used to downsample the line. If Resolution is
+	// not positive, the resolution of the canvas is
+	// used if it has one, otherwise 2 pixels per point.
+	Resolution float64
+}
+
+// NewStreamLine returns a StreamLine drawing the points
+// of src that uses the default line style.
+func NewStreamLine(src XYer) *StreamLine {
+	return &StreamLine{
+		Source:    src,
+		LineStyle: DefaultLineStyle,
+	}
+}
+
+// line returns a Line drawing the current points of the source.
+func (l *StreamLine) line() *Line {
+	line := &Line{
+		XYs:           snapshot(l.Source),
+		LineStyle:     l.LineStyle,
+		ShadeColor:    l.ShadeColor,
+		Baseline:      l.Baseline,
+		Interpolation: l.Interpolation,
+		Downsampling:  l.Downsampling,
+		Resolution:    l.Resolution,
+	}
+	if l.FillTo != nil {
+		line.FillTo = snapshot(l.FillTo)
+	}
+	return line
+}
+
+// Plot draws the StreamLine, implementing the plot.Plotter
+// interface.
+func (l *StreamLine) Plot(c draw.Canvas, plt *plot.Plot) {
+	l.line().Plot(c, plt)
+}
+
+// DataRange returns the minimum and maximum
+// x and y values, implementing the plot.DataRanger
+// interface.
+func (l *StreamLine) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin, xmax, ymin, ymax = sourceRange(l.Source)
+	if l.ShadeColor == nil {
+		return xmin, xmax, ymin, ymax
+	}
+	if l.FillTo != nil {
+		fxmin, fxmax, fymin, fymax := sourceRange(l.FillTo)
+		xmin = math.Min(xmin, fxmin)
+		xmax = math.Max(xmax, fxmax)
+		ymin = math.Min(ymin, fymin)
+		ymax = math.Max(ymax, fymax)
+	} else if l.Baseline != nil {
+		ymin = math.Min(ymin, *l.Baseline)
+		ymax = math.Max(ymax, *l.Baseline)
+	}
+	return xmin, xmax, ymin, ymax
+}
+
+// Thumbnail the thumbnail for the StreamLine,
+// implementing the plot.Thumbnailer interface.
+func (l *StreamLine) Thumbnail(c *draw.Canvas) {
+	l.line().Thumbnail(c)
+}
+
+// StreamScatter implements the Plotter interface, drawing a
+// glyph for each of the points of a source as they are when
+// the scatter is plotted, rather than a copy of them taken
+// when the scatter is made.
+type StreamScatter struct {
+	// Source is the source of the points of the scatter.
+	Source XYer
+
+	// GlyphStyle is the style of the glyphs drawn
+	// at each point.
+	draw.GlyphStyle
+
+	// Downsampling specifies how the points of the
+	// scatter are reduced before it is drawn.
+	Downsampling Downsampling
+
+	// Resolution is the number of pixels per point
+	// used to downsample the scatter. If Resolution
+	// is not positive, the resolution of the canvas
+	// is used if it has one, otherwise 2 pixels per
+	// point.
+	Resolution float64
+}
+
+// NewStreamScatter returns a StreamScatter drawing the
+// points of src that uses the default glyph style.
+func NewStreamScatter(src XYer) *StreamScatter {
+	return &StreamScatter{
+		Source:     src,
+		GlyphStyle: DefaultGlyphStyle,
+	}
+}
+
+// scatter returns a Scatter drawing the current points of the source.
+func (s *StreamScatter) scatter() *Scatter {
+	return &Scatter{
+		XYs:          snapshot(s.Source),
+		GlyphStyle:   s.GlyphStyle,
+		Downsampling: s.Downsampling,
+		Resolution:   s.Resolution,
+	}
+}
+
+// Plot draws the StreamScatter, implementing the plot.Plotter
+// interface.
+func (s *StreamScatter) Plot(c draw.Canvas, plt *plot.Plot) {
+	s.scatter().Plot(c, plt)
+}
+
+// DataRange returns the minimum and maximum
+// x and y values, implementing the plot.DataRanger
+// interface.
+func (s *StreamScatter) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return sourceRange(s.Source)
+}
+
+// GlyphBoxes returns a slice of plot.GlyphBoxes,
+// implementing the plot.GlyphBoxer interface.
+func (s *StreamScatter) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	return s.scatter().GlyphBoxes(plt)
+}
+
+// Thumbnail the thumbnail for the StreamScatter,
+// implementing the plot.Thumbnailer interface.
+func (s *StreamScatter) Thumbnail(c *draw.Canvas) {
+	c.DrawGlyph(s.GlyphStyle, c.Center())
+}
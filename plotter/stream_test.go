@@ -0,0 +1,258 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plotter
+
+import (
+	"bytes"
+	"encoding/gob"
+	"image/color"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/recorder"
+)
+
+func TestRing(t *testing.T) {
+	r := NewRing(3, 0)
+	if r.Len() != 0 {
+		t.Errorf("unexpected length of new ring: got:%d want:0", r.Len())
+	}
+	for i := 0; i < 5; i++ {
+		if err := r.Append(float64(i), float64(10*i)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	want := XYs{{2, 20}, {3, 30}, {4, 40}}
+	if got := r.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected points: got:%v want:%v", got, want)
+	}
+	if r.Len() != 3 {
+		t.Errorf("unexpected length: got:%d want:3", r.Len())
+	}
+	if x, y := r.XY(0); x != 2 || y != 20 {
+		t.Errorf("unexpected first point: got:(%v, %v) want:(2, 20)", x, y)
+	}
+	if v := r.Value(2); v != 40 {
+		t.Errorf("unexpected last value: got:%v want:40", v)
+	}
+	if err := r.Append(math.NaN(), 0); err != ErrNaN {
+		t.Errorf("unexpected error for NaN: got:%v want:%v", err, ErrNaN)
+	}
+	if r.Len() != 3 {
+		t.Errorf("ring changed by invalid point")
+	}
+}
+
+func TestRingWindow(t *testing.T) {
+	r := NewRing(10, 2)
+	for _, x := range []float64{0, 1, 2, 2.5, 4} {
+		r.Append(x, x)
+	}
+	want := XYs{{2, 2}, {2.5, 2.5}, {4, 4}}
+	if got := r.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected points: got:%v want:%v", got, want)
+	}
+
+	r = NewRing(10, 5)
+	r.Append(3, 1)
+	r.Append(4, -1)
+	xmin, xmax, ymin, ymax := r.DataRange()
+	if xmin != -1 || xmax != 4 || ymin != -1 || ymax != 1 {
+		t.Errorf("unexpected data range: got:[%v, %v]×[%v, %v] want:[-1, 4]×[-1, 1]", xmin, xmax, ymin, ymax)
+	}
+}
+
+func TestRingGob(t *testing.T) {
+	r := NewRing(3, 2)
+	for i := 0; i < 4; i++ {
+		r.Append(float64(i), float64(10*i))
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(r); err != nil {
+		t.Fatalf("unexpected error encoding ring: %v", err)
+	}
+	var got Ring
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatalf("unexpected error decoding ring: %v", err)
+	}
+	if !reflect.DeepEqual(got.Snapshot(), r.Snapshot()) {
+		t.Errorf("unexpected points after round trip: got:%v want:%v", got.Snapshot(), r.Snapshot())
+	}
+
+	// The decoded ring keeps its capacity and window.
+	got.Append(4, 40)
+	want := XYs{{2, 20}, {3, 30}, {4, 40}}
+	if xys := got.Snapshot(); !reflect.DeepEqual(xys, want) {
+		t.Errorf("unexpected points after append: got:%v want:%v", xys, want)
+	}
+	got.Append(7, 70)
+	want = XYs{{7, 70}}
+	if xys := got.Snapshot(); !reflect.DeepEqual(xys, want) {
+		t.Errorf("unexpected points after append out of window: got:%v want:%v", xys, want)
+	}
+}
+
+func TestRingConcurrent(t *testing.T) {
+	r := NewRing(100, 0)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			r.Append(float64(i), float64(i))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		xys := r.Snapshot()
+		for j := 1; j < len(xys); j++ {
+			if xys[j].X != xys[j-1].X+1 {
+				t.Fatalf("inconsistent snapshot: %v", xys)
+			}
+		}
+	}
+	wg.Wait()
+	if x, _ := r.XY(0); x != 900 {
+		t.Errorf("unexpected first point: got:%v want:900", x)
+	}
+}
+
+func TestStreamAutoRange(t *testing.T) {
+	r := NewRing(10, 4)
+	r.Append(0, 1)
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l := NewStreamLine(r)
+	s := NewStreamScatter(r)
+	p.Add(l, s)
+	p.X.AutoRange = true
+	p.Y.AutoRange = true
+
+	var rec recorder.Canvas
+	c := draw.NewCanvas(&rec, 100, 100)
+	for i := 1; i <= 6; i++ {
+		r.Append(float64(i), float64(i*i))
+	}
+	p.Draw(c)
+	if p.X.Min != 2 || p.X.Max != 6 || p.Y.Min != 4 || p.Y.Max != 36 {
+		t.Errorf("unexpected axis ranges: got:[%v, %v]×[%v, %v] want:[2, 6]×[4, 36]", p.X.Min, p.X.Max, p.Y.Min, p.Y.Max)
+	}
+	if n := len(s.GlyphBoxes(p)); n != 5 {
+		t.Errorf("unexpected number of glyph boxes: got:%d want:5", n)
+	}
+	var strokes int
+	for _, a := range rec.Actions {
+		if _, ok := a.(*recorder.Stroke); ok {
+			strokes++
+		}
+	}
+	if strokes == 0 {
+		t.Errorf("stream line not drawn")
+	}
+
+	// Axes without AutoRange keep their range.
+	p.X.AutoRange = false
+	r.Append(10, 0)
+	p.Draw(c)
+	if p.X.Min != 2 || p.X.Max != 6 || p.Y.Min != 0 || p.Y.Max != 36 {
+		t.Errorf("unexpected axis ranges: got:[%v, %v]×[%v, %v] want:[2, 6]×[0, 36]", p.X.Min, p.X.Max, p.Y.Min, p.Y.Max)
+	}
+}
+
+// drawn returns the actions of drawing the plotter p
+// on a new plot with axes from 0 to xmax and -1 to 1.
+func drawn(t *testing.T, p plot.Plotter, xmax float64) []recorder.Action {
+	plt, err := plot.New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plt.X.Min, plt.X.Max = 0, xmax
+	plt.Y.Min, plt.Y.Max = -1, 1
+	var rec recorder.Canvas
+	p.Plot(draw.NewCanvas(&rec, 100, 100), plt)
+	return rec.Actions
+}
+
+func TestStreamStyle(t *testing.T) {
+	xys := make(XYs, 1000)
+	for i := range xys {
+		xys[i].X = float64(i)
+		xys[i].Y = math.Sin(float64(i) / 10)
+	}
+	r := NewRing(len(xys), 0)
+	for _, p := range xys {
+		r.Append(p.X, p.Y)
+	}
+
+	l, err := NewLine(xys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l.Downsampling = MinMaxDownsampling
+	l.Resolution = 1
+	sl := NewStreamLine(r)
+	sl.Downsampling = MinMaxDownsampling
+	sl.Resolution = 1
+	want := drawn(t, l, 1000)
+	if got := drawn(t, sl, 1000); !reflect.DeepEqual(got, want) {
+		t.Errorf("stream line not drawn as line")
+	}
+	if reflect.DeepEqual(drawn(t, NewStreamLine(r), 1000), want) {
+		t.Errorf("stream line not downsampled")
+	}
+
+	s, err := NewScatter(xys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.Downsampling = LTTBDownsampling
+	s.Resolution = 1
+	ss := NewStreamScatter(r)
+	ss.Downsampling = LTTBDownsampling
+	ss.Resolution = 1
+	want = drawn(t, s, 1000)
+	if got := drawn(t, ss, 1000); !reflect.DeepEqual(got, want) {
+		t.Errorf("stream scatter not drawn as scatter")
+	}
+	if len(want) >= len(xys) {
+		t.Errorf("scatter not downsampled: %d actions", len(want))
+	}
+}
+
+func TestStreamFill(t *testing.T) {
+	upper := XYs{{0, 1}, {1, 3}, {2, 2}, {3, 4}}
+	lower := XYs{{0, 0}, {1, 1}, {2, 1}, {3, 2}}
+	r := NewRing(len(upper), 0)
+	for _, p := range upper {
+		r.Append(p.X, p.Y)
+	}
+	var shade color.Color = color.Gray{Y: 0x80}
+
+	l, err := NewFillBetween(upper, lower, shade)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l.Interpolation = MonotoneCubic
+	sl := NewStreamLine(r)
+	sl.ShadeColor = &shade
+	sl.FillTo = lower
+	sl.Interpolation = MonotoneCubic
+	if got, want := drawn(t, sl, 3), drawn(t, l, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("stream line not filled as line")
+	}
+
+	base := -1.0
+	sl.FillTo = nil
+	sl.Baseline = &base
+	xmin, xmax, ymin, ymax := sl.DataRange()
+	if xmin != 0 || xmax != 3 || ymin != base || ymax != 4 {
+		t.Errorf("unexpected data range: got:[%v, %v]×[%v, %v] want:[0, 3]×[-1, 4]", xmin, xmax, ymin, ymax)
+	}
+}
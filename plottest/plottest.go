@@ -0,0 +1,290 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package plottest provides golden file testing of plot rendering.
+//
+// Check renders a plot in each requested format and compares the
+// output with golden files checked in to the package's testdata
+// directory. Raster output is compared pixel by pixel, and vector
+// output is compared as text after removing creation dates and
+// optionally rounding numbers. When a comparison fails the output
+// is written next to the golden file, along with a difference
+// image for raster formats.
+//
+// Running the tests with the -regen flag, registered by this
+// package, rewrites the golden files from the current output:
+//
+//	go test -regen
+package plottest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	// Register the raster decoders.
+	_ "image/jpeg"
+
+	_ "golang.org/x/image/tiff"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+)
+
+var regen = flag.Bool("regen", false, "regenerate golden files from the current output")
+
+// Options specify how plots are compared with golden files.
+// The zero value requires an exact match after the removal
+// of creation dates.
+type Options struct {
+	// Dir is the directory holding the golden files.
+	// If Dir is empty, "testdata" is used.
+	Dir string
+
+	// Formats are the output formats to check. If Formats
+	// is empty, png, svg, eps and pdf are checked.
+	Formats []string
+
+	// Delta is the largest difference of any 8-bit color
+	// channel at which two raster pixels are considered
+	// equal.
+	Delta uint8
+
+	// Tolerance is the fraction of raster pixels that
+	// may differ.
+	Tolerance float64
+
+	// Digits is the number of decimal places that numbers
+	// in vector output are rounded to before comparison.
+	// If Digits is zero numbers are compared exactly.
+	Digits int
+}
+
+// DefaultFormats are the formats checked when Options.Formats is empty.
+var DefaultFormats = []string{"png", "svg", "eps", "pdf"}
+
+// Check renders p with the given width and height in each of the
+// formats of opts and compares the output with the golden file
+// named name+"_golden."+format. A nil opts is the zero Options.
+//
+// Failures are reported with t.Errorf. The output of a failing
+// comparison is written to name+"_got."+format, and for raster
+// formats the differing pixels are marked in name+"_diff.png".
+func Check(t testing.TB, p *plot.Plot, w, h vg.Length, name string, opts *Options) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	dir := opts.Dir
+	if dir == "" {
+		dir = "testdata"
+	}
+	formats := opts.Formats
+	if len(formats) == 0 {
+		formats = DefaultFormats
+	}
+
+	for _, format := range formats {
+		c, err := p.WriterTo(w, h, format)
+		if err != nil {
+			t.Errorf("%s: failed to render %s: %v", name, format, err)
+			continue
+		}
+		var buf bytes.Buffer
+		_, err = c.WriteTo(&buf)
+		if err != nil {
+			t.Errorf("%s: failed to write %s: %v", name, format, err)
+			continue
+		}
+		got := buf.Bytes()
+
+		base := filepath.Join(dir, name)
+		golden := base + "_golden." + format
+		if *regen {
+			err = os.MkdirAll(dir, 0755)
+			if err == nil {
+				err = ioutil.WriteFile(golden, got, 0644)
+			}
+			if err != nil {
+				t.Errorf("%s: failed to regenerate golden file: %v", name, err)
+			}
+			continue
+		}
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Errorf("%s: failed to read golden file: %v (run the tests with -regen to create it)", name, err)
+			continue
+		}
+
+		var (
+			ok   bool
+			msg  string
+			diff image.Image
+		)
+		if IsRaster(format) {
+			var n int
+			diff, n, err = CompareRaster(got, want, opts.Delta)
+			if err != nil {
+				t.Errorf("%s: failed to compare %s: %v", name, format, err)
+				continue
+			}
+			total := diff.Bounds().Dx() * diff.Bounds().Dy()
+			ok = float64(n) <= opts.Tolerance*float64(total)
+			msg = fmt.Sprintf("%d of %d pixels differ", n, total)
+		} else {
+			var line int
+			line, ok = CompareVector(format, got, want, opts.Digits)
+			msg = fmt.Sprintf("first difference at line %d", line)
+		}
+		if ok {
+			continue
+		}
+
+		t.Errorf("%s: %s output does not match %s: %s", name, format, golden, msg)
+		err = ioutil.WriteFile(base+"_got."+format, got, 0644)
+		if err != nil {
+			t.Errorf("%s: failed to write output: %v", name, err)
+		}
+		if diff != nil {
+			err = writePNG(base+"_diff.png", diff)
+			if err != nil {
+				t.Errorf("%s: failed to write difference image: %v", name, err)
+			}
+		}
+	}
+}
+
+// IsRaster returns whether format is a raster image format.
+func IsRaster(format string) bool {
+	switch format {
+	case "png", "jpg", "jpeg", "tif", "tiff":
+		return true
+	}
+	return false
+}
+
+// CompareRaster compares the encoded raster images got and want.
+// It returns the number of pixels with a color channel differing
+// by more than delta, and an image of the differences in which
+// differing pixels are red and equal pixels are a faded copy of
+// want. Images of different sizes are an error.
+func CompareRaster(got, want []byte, delta uint8) (diff *image.RGBA, n int, err error) {
+	g, _, err := image.Decode(bytes.NewReader(got))
+	if err != nil {
+		return nil, 0, fmt.Errorf("plottest: decoding output: %v", err)
+	}
+	w, _, err := image.Decode(bytes.NewReader(want))
+	if err != nil {
+		return nil, 0, fmt.Errorf("plottest: decoding golden image: %v", err)
+	}
+	gb, wb := g.Bounds(), w.Bounds()
+	if gb.Size() != wb.Size() {
+		return nil, 0, fmt.Errorf("plottest: image size %v does not match golden size %v", gb.Size(), wb.Size())
+	}
+
+	diff = image.NewRGBA(image.Rect(0, 0, wb.Dx(), wb.Dy()))
+	red := color.RGBA{R: 0xff, A: 0xff}
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			gc := color.NRGBAModel.Convert(g.At(gb.Min.X+x, gb.Min.Y+y)).(color.NRGBA)
+			wc := color.NRGBAModel.Convert(w.At(wb.Min.X+x, wb.Min.Y+y)).(color.NRGBA)
+			if absDiff(gc.R, wc.R) > delta || absDiff(gc.G, wc.G) > delta ||
+				absDiff(gc.B, wc.B) > delta || absDiff(gc.A, wc.A) > delta {
+				n++
+				diff.Set(x, y, red)
+				continue
+			}
+			gray := color.GrayModel.Convert(wc).(color.Gray)
+			v := 0xff - (0xff-gray.Y)/4
+			diff.Set(x, y, color.RGBA{R: v, G: v, B: v, A: 0xff})
+		}
+	}
+	return diff, n, nil
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// CompareVector compares the vector image outputs got and want of the
+// given format after normalizing them with NormalizeVector. It returns
+// whether they are equal and, if not, the 1-based number of the first
+// differing line.
+func CompareVector(format string, got, want []byte, digits int) (line int, ok bool) {
+	gl := bytes.Split(NormalizeVector(format, got, digits), []byte("\n"))
+	wl := bytes.Split(NormalizeVector(format, want, digits), []byte("\n"))
+	for i := 0; i < len(gl) && i < len(wl); i++ {
+		if !bytes.Equal(gl[i], wl[i]) {
+			return i + 1, false
+		}
+	}
+	if len(gl) != len(wl) {
+		return min(len(gl), len(wl)) + 1, false
+	}
+	return 0, true
+}
+
+var (
+	creationDate = map[string]*regexp.Regexp{
+		"eps": regexp.MustCompile(`(?m)^%%CreationDate:.*$`),
+		"pdf": regexp.MustCompile(`/CreationDate\s*\([^)]*\)`),
+	}
+	number = regexp.MustCompile(`-?[0-9]+\.[0-9]+`)
+)
+
+// NormalizeVector returns a normalized copy of the vector image b
+// of the given format. Line endings are converted to "\n", creation
+// dates are removed and, if digits is positive, decimal numbers are
+// rounded to digits decimal places.
+func NormalizeVector(format string, b []byte, digits int) []byte {
+	b = bytes.Replace(b, []byte("\r\n"), []byte("\n"), -1)
+	if re, ok := creationDate[format]; ok {
+		b = re.ReplaceAll(b, nil)
+	}
+	if digits > 0 {
+		b = number.ReplaceAllFunc(b, func(n []byte) []byte {
+			v, err := strconv.ParseFloat(string(n), 64)
+			if err != nil {
+				return n
+			}
+			s := strconv.FormatFloat(v, 'f', digits, 64)
+			if s == "-"+strconv.FormatFloat(0, 'f', digits, 64) {
+				s = s[1:]
+			}
+			return []byte(s)
+		})
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(f, img)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
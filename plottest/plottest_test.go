@@ -0,0 +1,240 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plottest
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/palette"
+	"github.com/gonum/plot/plotter"
+	"github.com/gonum/plot/vg"
+)
+
+func TestCheck(t *testing.T) {
+	p := linePlot(t, plotter.XYs{{0, 0}, {1, 2}, {2, 1}})
+	Check(t, p, 2*vg.Inch, 2*vg.Inch, "line", &Options{Formats: []string{"svg", "eps", "pdf"}})
+}
+
+func TestCheckRaster(t *testing.T) {
+	p := heatPlot(t, 0)
+	Check(t, p, vg.Inch, vg.Inch, "heat", &Options{Formats: []string{"png"}})
+}
+
+func TestCheckRasterMismatch(t *testing.T) {
+	if *regen {
+		t.Skip("not checking mismatches while regenerating")
+	}
+	dir, err := ioutil.TempDir("", "plottest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "heat_golden.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "heat_golden.png"), b, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cycling the columns of the heat map
+	// changes the colors of all of its cells.
+	p := heatPlot(t, 1)
+	rec := &recorder{TB: t}
+	Check(rec, p, vg.Inch, vg.Inch, "heat", &Options{Dir: dir, Formats: []string{"png"}})
+	if len(rec.errors) != 1 {
+		t.Fatalf("unexpected number of errors: got:%d want:1\n%q", len(rec.errors), rec.errors)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "heat_got.png")); err != nil {
+		t.Errorf("failed output not written: %v", err)
+	}
+	f, err := os.Open(filepath.Join(dir, "heat_diff.png"))
+	if err != nil {
+		t.Fatalf("difference image not written: %v", err)
+	}
+	defer f.Close()
+	diff, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("failed to decode difference image: %v", err)
+	}
+	red := color.RGBA{R: 0xff, A: 0xff}
+	var n int
+	for y := diff.Bounds().Min.Y; y < diff.Bounds().Max.Y; y++ {
+		for x := diff.Bounds().Min.X; x < diff.Bounds().Max.X; x++ {
+			if color.RGBAModel.Convert(diff.At(x, y)) == red {
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		t.Error("no differing pixels marked in difference image")
+	}
+
+	// A tolerance of all pixels accepts the difference.
+	rec = &recorder{TB: t}
+	Check(rec, p, vg.Inch, vg.Inch, "heat", &Options{Dir: dir, Formats: []string{"png"}, Tolerance: 1})
+	if len(rec.errors) != 0 {
+		t.Errorf("unexpected errors with full tolerance: %q", rec.errors)
+	}
+}
+
+// grid is a plotter.GridXYZ with values increasing
+// along its columns and rows, offset by a number
+// of columns.
+type grid struct{ offset int }
+
+func (g grid) Dims() (c, r int)   { return 4, 4 }
+func (g grid) Z(c, r int) float64 { return float64((c+g.offset)%4 + 4*r) }
+func (g grid) X(c int) float64    { return float64(c) }
+func (g grid) Y(r int) float64    { return float64(r) }
+
+// heatPlot returns a plot of a heat map without axes,
+// drawn as an image so that the rendering of the plot
+// does not depend on the drawing of paths and text.
+func heatPlot(t *testing.T, offset int) *plot.Plot {
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("failed to create plot: %v", err)
+	}
+	h := plotter.NewHeatMap(grid{offset: offset}, palette.Heat(16, 1))
+	h.Rasterization = plotter.RasterAlways
+	p.Add(h)
+	p.HideAxes()
+	return p
+}
+
+func TestCheckMismatch(t *testing.T) {
+	if *regen {
+		t.Skip("not checking mismatches while regenerating")
+	}
+	dir, err := ioutil.TempDir("", "plottest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, format := range []string{"svg", "eps"} {
+		b, err := ioutil.ReadFile(filepath.Join("testdata", "line_golden."+format))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, "line_golden."+format), b, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := linePlot(t, plotter.XYs{{0, 0}, {1, 2}, {2, 1.5}})
+	rec := &recorder{TB: t}
+	Check(rec, p, 2*vg.Inch, 2*vg.Inch, "line", &Options{Dir: dir, Formats: []string{"svg", "eps"}})
+	if len(rec.errors) != 2 {
+		t.Errorf("unexpected number of errors: got:%d want:2\n%q", len(rec.errors), rec.errors)
+	}
+	for _, format := range []string{"svg", "eps"} {
+		if _, err := os.Stat(filepath.Join(dir, "line_got."+format)); err != nil {
+			t.Errorf("failed output not written: %v", err)
+		}
+	}
+}
+
+// recorder is a testing.TB that records errors.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func linePlot(t *testing.T, xys plotter.XYs) *plot.Plot {
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("failed to create plot: %v", err)
+	}
+	l, err := plotter.NewLine(xys)
+	if err != nil {
+		t.Fatalf("failed to create line: %v", err)
+	}
+	p.Add(l)
+	return p
+}
+
+func TestCompareRaster(t *testing.T) {
+	want := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	got := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := range want.Pix {
+		want.Pix[i] = 0xff
+		got.Pix[i] = 0xff
+	}
+	got.Set(1, 1, color.NRGBA{R: 0xfa, G: 0xff, B: 0xff, A: 0xff})
+	got.Set(2, 3, color.NRGBA{A: 0xff})
+
+	for _, test := range []struct {
+		delta uint8
+		want  int
+	}{
+		{delta: 0, want: 2},
+		{delta: 5, want: 1},
+		{delta: 0xff, want: 0},
+	} {
+		diff, n, err := CompareRaster(encodePNG(t, got), encodePNG(t, want), test.delta)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != test.want {
+			t.Errorf("unexpected number of differing pixels for delta %d: got:%d want:%d", test.delta, n, test.want)
+		}
+		red := color.RGBA{R: 0xff, A: 0xff}
+		if (diff.At(2, 3) == red) != (test.want > 0) {
+			t.Errorf("unexpected difference image pixel for delta %d: got:%v", test.delta, diff.At(2, 3))
+		}
+	}
+
+	_, _, err := CompareRaster(encodePNG(t, image.NewNRGBA(image.Rect(0, 0, 3, 4))), encodePNG(t, want), 0)
+	if err == nil {
+		t.Error("expected error for size mismatch")
+	}
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		t.Fatalf("failed to encode image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompareVector(t *testing.T) {
+	for i, test := range []struct {
+		format    string
+		got, want string
+		digits    int
+		line      int
+		ok        bool
+	}{
+		{format: "eps", got: "%!PS\n%%CreationDate: today\n1 2 lineto\n", want: "%!PS\n%%CreationDate: yesterday\n1 2 lineto\n", ok: true},
+		{format: "svg", got: "<svg>\r\n<path d=\"M1.0001 2\"/>", want: "<svg>\n<path d=\"M1.0002 2\"/>", line: 2},
+		{format: "svg", got: "<svg>\r\n<path d=\"M1.0001 2\"/>", want: "<svg>\n<path d=\"M1.0002 2\"/>", digits: 3, ok: true},
+		{format: "svg", got: "<path d=\"M-0.0001 2\"/>", want: "<path d=\"M0.0000 2\"/>", digits: 2, ok: true},
+		{format: "pdf", got: "/CreationDate (D:2015)\n1 0 0 RG", want: "/CreationDate (D:2016)\n1 0 0 RG", ok: true},
+		{format: "svg", got: "a\nb", want: "a\nb\nc", line: 3},
+	} {
+		line, ok := CompareVector(test.format, []byte(test.got), []byte(test.want), test.digits)
+		if line != test.line || ok != test.ok {
+			t.Errorf("unexpected comparison result for test %d: got:(%d, %t) want:(%d, %t)", i, line, ok, test.line, test.ok)
+		}
+	}
+}
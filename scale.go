@@ -0,0 +1,395 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plot
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// normalizeBy returns the normalized value of x for a scale
+// that is linear in the transform f of the data values.
+func normalizeBy(f func(float64) float64, min, max, x float64) float64 {
+	fmin := f(min)
+	return (f(x) - fmin) / (f(max) - fmin)
+}
+
+// transformedTicks returns the ticks chosen by DefaultTicks in
+// the range of the transformed values of min and max, mapped
+// back to data values by inv.
+func transformedTicks(f, inv func(float64) float64, min, max float64) []Tick {
+	ticks := DefaultTicks{}.Ticks(f(min), f(max))
+	vals := make([]float64, len(ticks))
+	for i, t := range ticks {
+		vals[i] = inv(t.Value)
+	}
+	prec := maxInt(precisionOf(min), precisionOf(max))
+	for i, t := range ticks {
+		ticks[i].Value = vals[i]
+		if !t.IsMinor() {
+			ticks[i].Label = formatFloatTick(vals[i], prec)
+		}
+	}
+	return ticks
+}
+
+// SymLogScale can be used as the value of an Axis.Scale function
+// to set the axis to a symmetric log scale, which is linear for
+// values within Linear of zero and logarithmic beyond them.
+// Unlike LogScale, it accepts zero and negative values.
+type SymLogScale struct {
+	// Linear is the extent of the linear region
+	// about zero. If Linear is not positive, 1
+	// is used.
+	Linear float64
+}
+
+var _ Normalizer = SymLogScale{}
+
+func (s SymLogScale) Normalize(min, max, x float64) float64 {
+	return normalizeBy(symLog(s.Linear), min, max, x)
+}
+
+// symLog returns the symmetric log transform with
+// the given extent of the linear region.
+func symLog(c float64) func(float64) float64 {
+	if c <= 0 {
+		c = 1
+	}
+	return func(x float64) float64 {
+		a := math.Abs(x)
+		if a <= c {
+			return x / c
+		}
+		return math.Copysign(1+math.Log10(a/c), x)
+	}
+}
+
+// SymLogTicks is suitable for the Tick.Marker field of an Axis
+// with a SymLogScale. It returns major ticks at zero and at
+// powers of ten times Linear, with minor ticks between them.
+type SymLogTicks struct {
+	// Linear is the extent of the linear
+	// region of the matching SymLogScale.
+	Linear float64
+}
+
+var _ Ticker = SymLogTicks{}
+
+// Ticks returns Ticks in a specified range
+func (t SymLogTicks) Ticks(min, max float64) []Tick {
+	if max < min {
+		panic("illegal range")
+	}
+	c := t.Linear
+	if c <= 0 {
+		c = 1
+	}
+	prec := maxInt(precisionOf(min), precisionOf(max))
+	var ticks []Tick
+	add := func(v float64, major bool) {
+		if v < min || v > max {
+			return
+		}
+		tick := Tick{Value: v}
+		if major {
+			tick.Label = formatFloatTick(v, prec)
+		}
+		ticks = append(ticks, tick)
+	}
+	if min >= -c && max <= c {
+		// The axis is within the linear region.
+		return DefaultTicks{}.Ticks(min, max)
+	}
+	add(0, true)
+	top := math.Max(math.Abs(min), math.Abs(max))
+	for d := c; d <= top*10 && !math.IsInf(d, 0); d *= 10 {
+		for i := 1; i < 10; i++ {
+			v := d * float64(i)
+			add(v, i == 1)
+			add(-v, i == 1)
+		}
+	}
+	sort.Sort(byValue(ticks))
+	return ticks
+}
+
+// SqrtScale can be used as the value of an Axis.Scale function to
+// set the axis to a square root scale. Negative values are placed
+// at the negated square root of their magnitude.
+type SqrtScale struct{}
+
+var _ Normalizer = SqrtScale{}
+
+func (SqrtScale) Normalize(min, max, x float64) float64 {
+	return normalizeBy(signedSqrt, min, max, x)
+}
+
+func signedSqrt(x float64) float64 {
+	return math.Copysign(math.Sqrt(math.Abs(x)), x)
+}
+
+func signedSquare(x float64) float64 {
+	return math.Copysign(x*x, x)
+}
+
+// SqrtTicks is suitable for the Tick.Marker field of an Axis
+// with a SqrtScale. It returns ticks that are evenly spaced
+// along the axis.
+type SqrtTicks struct{}
+
+var _ Ticker = SqrtTicks{}
+
+// Ticks returns Ticks in a specified range
+func (SqrtTicks) Ticks(min, max float64) []Tick {
+	if max < min {
+		panic("illegal range")
+	}
+	return transformedTicks(signedSqrt, signedSquare, min, max)
+}
+
+// LogitScale can be used as the value of an Axis.Scale function to
+// set the axis to a logit scale for probabilities, which spreads
+// out values near 0 and 1. Values are clamped to the range from
+// LogitClamp to 1-LogitClamp.
+type LogitScale struct{}
+
+var _ Normalizer = LogitScale{}
+
+// LogitClamp is the smallest distance from 0 and 1
+// of the values placed on a logit scale.
+const LogitClamp = 1e-6
+
+func (LogitScale) Normalize(min, max, x float64) float64 {
+	return normalizeBy(logit, min, max, x)
+}
+
+func logit(p float64) float64 {
+	p = math.Max(LogitClamp, math.Min(1-LogitClamp, p))
+	return math.Log(p / (1 - p))
+}
+
+// LogitTicks is suitable for the Tick.Marker field of an Axis
+// with a LogitScale. It returns major ticks at 0.5 and at powers
+// of ten and their complements, with minor ticks between them.
+type LogitTicks struct{}
+
+var _ Ticker = LogitTicks{}
+
+// Ticks returns Ticks in a specified range
+func (LogitTicks) Ticks(min, max float64) []Tick {
+	if max < min {
+		panic("illegal range")
+	}
+	lo := math.Max(min, LogitClamp)
+	hi := math.Min(max, 1-LogitClamp)
+	var ticks []Tick
+	add := func(v float64, major bool) {
+		if v < min || v > max {
+			return
+		}
+		tick := Tick{Value: v}
+		if major {
+			tick.Label = formatFloatTick(v, precisionOf(math.Min(v, 1-v))+1)
+		}
+		ticks = append(ticks, tick)
+	}
+	add(0.5, true)
+	for i := 2; i < 5; i++ {
+		add(float64(i)/10, false)
+		add(1-float64(i)/10, false)
+	}
+	for d := 0.1; d >= math.Min(lo, 1-hi)/10 && d >= LogitClamp; d /= 10 {
+		add(d, true)
+		add(1-d, true)
+		if d == 0.1 {
+			continue
+		}
+		for i := 2; i < 10; i++ {
+			add(d*float64(i), false)
+			add(1-d*float64(i), false)
+		}
+	}
+	sort.Sort(byValue(ticks))
+	return ticks
+}
+
+// InvertedScale can be used as the value of an Axis.Scale function
+// to invert the direction of an axis, so that values decrease along
+// it. The ticks of the inverted scale are those of the scale that it
+// inverts.
+type InvertedScale struct {
+	// Normalizer is the scale that is inverted.
+	// If Normalizer is nil, LinearScale is used.
+	Normalizer
+}
+
+var _ Normalizer = InvertedScale{}
+
+func (s InvertedScale) Normalize(min, max, x float64) float64 {
+	n := s.Normalizer
+	if n == nil {
+		n = LinearScale{}
+	}
+	return 1 - n.Normalize(min, max, x)
+}
+
+// BrokenScale can be used as the value of an Axis.Scale function to
+// set the axis to a linear scale that skips the range from Start
+// to End. Values within the skipped range are placed in a gap at
+// the break, which is marked on the axis line.
+type BrokenScale struct {
+	// Start and End are the start and end
+	// of the range that is skipped.
+	Start, End float64
+
+	// Gap is the width of the gap at the break as
+	// a fraction of the length of the axis.
+	Gap float64
+}
+
+var _ Normalizer = BrokenScale{}
+
+func (s BrokenScale) Normalize(min, max, x float64) float64 {
+	start, end, ok := s.skipped(min, max)
+	if !ok {
+		return (x - min) / (max - min)
+	}
+	gap := math.Max(0, math.Min(s.Gap, 1))
+	kept := (max - min) - (end - start)
+	scale := (1 - gap) / kept
+	switch {
+	case x <= start:
+		return (x - min) * scale
+	case x >= end:
+		return (start-min)*scale + gap + (x-end)*scale
+	default:
+		return (start-min)*scale + gap*(x-start)/(end-start)
+	}
+}
+
+// skipped returns the part of the skipped range that is within
+// the axis range, and whether the scale is broken there. A break
+// covering the whole axis leaves nothing to draw on either side,
+// so the scale is then not broken.
+func (s BrokenScale) skipped(min, max float64) (start, end float64, ok bool) {
+	start, end = math.Max(min, s.Start), math.Min(max, s.End)
+	if start >= end || (max-min)-(end-start) <= 0 {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// gap returns the normalized start and end of the gap of the
+// scale on an axis with the given range, and whether the
+// break is within the range.
+func (s BrokenScale) gap(min, max float64) (start, end float64, ok bool) {
+	start, end, ok = s.skipped(min, max)
+	if !ok {
+		return 0, 0, false
+	}
+	return s.Normalize(min, max, start), s.Normalize(min, max, end), true
+}
+
+// BrokenTicks is suitable for the Tick.Marker field of an Axis
+// with a BrokenScale. It returns the ticks chosen by Ticker for
+// each side of the break separately.
+type BrokenTicks struct {
+	// Start and End are the start and end
+	// of the range skipped by the matching
+	// BrokenScale.
+	Start, End float64
+
+	// Ticker chooses the ticks on each side
+	// of the break. If Ticker is nil,
+	// DefaultTicks is used.
+	Ticker
+}
+
+var _ Ticker = BrokenTicks{}
+
+// Ticks returns Ticks in a specified range
+func (t BrokenTicks) Ticks(min, max float64) []Tick {
+	ticker := t.Ticker
+	if ticker == nil {
+		ticker = DefaultTicks{}
+	}
+	start, end := math.Max(min, t.Start), math.Min(max, t.End)
+	if start >= end {
+		return ticker.Ticks(min, max)
+	}
+	var ticks []Tick
+	if start > min {
+		ticks = append(ticks, ticker.Ticks(min, start)...)
+	}
+	if end < max {
+		ticks = append(ticks, ticker.Ticks(end, max)...)
+	}
+	sort.Stable(byValue(ticks))
+	return ticks
+}
+
+// byValue sorts ticks by their value.
+type byValue []Tick
+
+func (t byValue) Len() int           { return len(t) }
+func (t byValue) Less(i, j int) bool { return t[i].Value < t[j].Value }
+func (t byValue) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+
+// gapOf returns the start and end of the gap of the scale s
+// from min to max, as fractions of the axis. A BrokenScale
+// inside an InvertedScale has its gap flipped.
+func gapOf(s Normalizer, min, max float64) (start, end float64, ok bool) {
+	switch s := s.(type) {
+	case BrokenScale:
+		return s.gap(min, max)
+	case InvertedScale:
+		start, end, ok = gapOf(s.Normalizer, min, max)
+		return 1 - end, 1 - start, ok
+	}
+	return 0, 0, false
+}
+
+// drawLine draws the axis line across c at y for a horizontal
+// axis or at x for a vertical axis. The line of an axis with
+// a BrokenScale is split at the break, and the ends of the
+// two parts are marked with slanted strokes.
+func (a *Axis) drawLine(c draw.Canvas, horizontal bool, at vg.Length) {
+	line := func(min, max vg.Length) {
+		if horizontal {
+			c.StrokeLine2(a.LineStyle, min, at, max, at)
+		} else {
+			c.StrokeLine2(a.LineStyle, at, min, at, max)
+		}
+	}
+	min, max := c.Min.Y, c.Max.Y
+	if horizontal {
+		min, max = c.Min.X, c.Max.X
+	}
+	start, end, ok := gapOf(a.Scale, a.Min, a.Max)
+	if !ok {
+		line(min, max)
+		return
+	}
+	p0 := min + vg.Length(start)*(max-min)
+	p1 := min + vg.Length(end)*(max-min)
+	line(min, p0)
+	line(p1, max)
+
+	d := a.Tick.Length
+	if d <= 0 {
+		d = vg.Points(4)
+	}
+	for _, p := range []vg.Length{p0, p1} {
+		if horizontal {
+			c.StrokeLine2(a.LineStyle, p-d/4, at-d/2, p+d/4, at+d/2)
+		} else {
+			c.StrokeLine2(a.LineStyle, at-d/2, p-d/4, at+d/2, p+d/4)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plot
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestScales(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		scale Normalizer
+		min   float64
+		max   float64
+		x     []float64
+		want  []float64
+	}{
+		{
+			name:  "symlog",
+			scale: SymLogScale{},
+			min:   -100, max: 100,
+			x:    []float64{-100, -10, -1, 0, 0.5, 1, 10, 100},
+			want: []float64{0, 1.0 / 6, 2.0 / 6, 0.5, 0.5 + 0.5/6, 4.0 / 6, 5.0 / 6, 1},
+		},
+		{
+			name:  "symlog linear",
+			scale: SymLogScale{Linear: 10},
+			min:   0, max: 100,
+			x:    []float64{0, 5, 10, 100},
+			want: []float64{0, 0.25, 0.5, 1},
+		},
+		{
+			name:  "sqrt",
+			scale: SqrtScale{},
+			min:   0, max: 100,
+			x:    []float64{0, 1, 25, 100},
+			want: []float64{0, 0.1, 0.5, 1},
+		},
+		{
+			name:  "logit",
+			scale: LogitScale{},
+			min:   0.01, max: 0.99,
+			x:    []float64{0, 0.01, 0.5, 0.99, 1},
+			want: []float64{-1, 0, 0.5, 1, 2},
+		},
+		{
+			name:  "inverted",
+			scale: InvertedScale{},
+			min:   0, max: 10,
+			x:    []float64{0, 2.5, 10},
+			want: []float64{1, 0.75, 0},
+		},
+		{
+			name:  "inverted log",
+			scale: InvertedScale{LogScale{}},
+			min:   1, max: 100,
+			x:    []float64{1, 10, 100},
+			want: []float64{1, 0.5, 0},
+		},
+		{
+			name:  "broken",
+			scale: BrokenScale{Start: 10, End: 90, Gap: 0.2},
+			min:   0, max: 100,
+			x:    []float64{0, 10, 50, 90, 100},
+			want: []float64{0, 0.4, 0.5, 0.6, 1},
+		},
+		{
+			name:  "broken outside",
+			scale: BrokenScale{Start: 110, End: 190, Gap: 0.2},
+			min:   0, max: 100,
+			x:    []float64{0, 50, 100},
+			want: []float64{0, 0.5, 1},
+		},
+		{
+			name:  "broken whole axis",
+			scale: BrokenScale{Start: -10, End: 110, Gap: 0.2},
+			min:   0, max: 100,
+			x:    []float64{0, 50, 100},
+			want: []float64{0, 0.5, 1},
+		},
+	} {
+		for i, x := range test.x {
+			got := test.scale.Normalize(test.min, test.max, x)
+			want := test.want[i]
+			if test.name == "logit" && (x == 0 || x == 1) {
+				// Values outside the axis range are clamped
+				// to LogitClamp, far beyond the axis ends.
+				if (want < 0) != (got < 0) || math.Abs(got-0.5) < 1 {
+					t.Errorf("%s: unexpected normalized value of %v: got:%v", test.name, x, got)
+				}
+				continue
+			}
+			if math.Abs(got-want) > 1e-12 {
+				t.Errorf("%s: unexpected normalized value of %v: got:%v want:%v", test.name, x, got, want)
+			}
+		}
+	}
+}
+
+func TestScaleTicks(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		ticker Ticker
+		min    float64
+		max    float64
+		want   []string
+	}{
+		{
+			name:   "symlog",
+			ticker: SymLogTicks{},
+			min:    -100, max: 1000,
+			want: []string{"-100", "-10", "-1", "0", "1", "10", "100", "1000"},
+		},
+		{
+			name:   "symlog linear",
+			ticker: SymLogTicks{Linear: 5},
+			min:    -2, max: 2,
+			want: []string{"-2", "-1", "0", "1", "2"},
+		},
+		{
+			name:   "symlog extreme",
+			ticker: SymLogTicks{},
+			min:    -1e308, max: 1e308,
+			want: symLogLabels(308),
+		},
+		{
+			name:   "sqrt",
+			ticker: SqrtTicks{},
+			min:    0, max: 100,
+			want: []string{"0", "9", "36", "81"},
+		},
+		{
+			name:   "logit",
+			ticker: LogitTicks{},
+			min:    0.001, max: 0.999,
+			want: []string{"0.001", "0.01", "0.1", "0.5", "0.9", "0.99", "0.999"},
+		},
+		{
+			name:   "broken",
+			ticker: BrokenTicks{Start: 10, End: 990},
+			min:    0, max: 1000,
+			want: []string{"0", "3", "6", "9", "990", "993", "996", "999"},
+		},
+	} {
+		ticks := test.ticker.Ticks(test.min, test.max)
+		var got []string
+		for _, tick := range ticks {
+			if tick.Value < test.min || tick.Value > test.max {
+				t.Errorf("%s: tick %v outside range", test.name, tick.Value)
+			}
+			if !tick.IsMinor() {
+				got = append(got, tick.Label)
+			}
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: unexpected labels: got:%q want:%q", test.name, got, test.want)
+		}
+	}
+}
+
+// symLogLabels returns the labels of the major symlog
+// ticks from -10^n to 10^n.
+func symLogLabels(n int) []string {
+	labels := []string{"0"}
+	for i := 0; i <= n; i++ {
+		v := math.Pow(10, float64(i))
+		labels = append([]string{formatFloatTick(-v, 0)}, labels...)
+		labels = append(labels, formatFloatTick(v, 0))
+	}
+	return labels
+}
+
+func TestGapOf(t *testing.T) {
+	b := BrokenScale{Start: 10, End: 90, Gap: 0.2}
+	start, end, ok := gapOf(b, 0, 100)
+	if !ok {
+		t.Fatal("no gap of broken scale")
+	}
+	istart, iend, ok := gapOf(InvertedScale{b}, 0, 100)
+	if !ok {
+		t.Fatal("no gap of inverted broken scale")
+	}
+	if istart != 1-end || iend != 1-start {
+		t.Errorf("unexpected gap of inverted broken scale: got:[%v, %v] want:[%v, %v]", istart, iend, 1-end, 1-start)
+	}
+	if _, _, ok := gapOf(InvertedScale{}, 0, 100); ok {
+		t.Error("unexpected gap of inverted linear scale")
+	}
+	if _, _, ok := gapOf(BrokenScale{Start: 0, End: 100, Gap: 0.2}, 0, 100); ok {
+		t.Error("unexpected gap of break covering the whole axis")
+	}
+}
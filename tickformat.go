@@ -0,0 +1,222 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plot
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gonum/floats"
+)
+
+// defaultSignificant is the number of significant digits of the
+// labels of formatters for which a precision is not given.
+const defaultSignificant = 3
+
+// siPrefixes are the SI prefixes of the powers of 1000
+// from 10^-24 to 10^24.
+var siPrefixes = []string{"y", "z", "a", "f", "p", "n", "µ", "m", "", "k", "M", "G", "T", "P", "E", "Z", "Y"}
+
+// engineering returns v rounded to sig significant digits as
+// m×10^exp, where exp is a multiple of 3 and 1 ≤ |m| < 1000.
+func engineering(v float64, sig int) (m float64, exp int) {
+	if sig <= 0 {
+		sig = defaultSignificant
+	}
+	if v == 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return v, 0
+	}
+	s := strconv.FormatFloat(v, 'e', sig-1, 64)
+	i := strings.IndexByte(s, 'e')
+	v, _ = strconv.ParseFloat(s, 64)
+	e, _ := strconv.Atoi(s[i+1:])
+	exp = 3 * floorDiv(e, 3)
+	m = floats.Round(v/math.Pow10(exp), sig-1-(e-exp))
+	return m, exp
+}
+
+// SIFormat is a TickFormatter that labels tick marks with
+// SI prefixes, such as 1.5k for 1500 and 2M for 2000000.
+type SIFormat struct {
+	// Precision is the number of significant digits
+	// of the labels. If Precision is not positive,
+	// three significant digits are used.
+	Precision int
+
+	// Unit is appended to the labels after
+	// the prefix.
+	Unit string
+}
+
+var _ TickFormatter = SIFormat{}
+
+// Format returns the label of a major tick mark at v.
+func (f SIFormat) Format(v float64) string {
+	m, exp := engineering(v, f.Precision)
+	i := exp/3 + 8
+	if i < 0 || i >= len(siPrefixes) {
+		return EngineeringFormat{Precision: f.Precision}.Format(v) + f.Unit
+	}
+	return strconv.FormatFloat(m, 'f', -1, 64) + siPrefixes[i] + f.Unit
+}
+
+// EngineeringFormat is a TickFormatter that labels tick marks in
+// engineering notation, with an exponent that is a multiple of
+// three, such as 1.5e3 for 1500 and 20e-6 for 0.00002.
+type EngineeringFormat struct {
+	// Precision is the number of significant digits
+	// of the labels. If Precision is not positive,
+	// three significant digits are used.
+	Precision int
+}
+
+var _ TickFormatter = EngineeringFormat{}
+
+// Format returns the label of a major tick mark at v.
+func (f EngineeringFormat) Format(v float64) string {
+	m, exp := engineering(v, f.Precision)
+	s := strconv.FormatFloat(m, 'f', -1, 64)
+	if exp == 0 {
+		return s
+	}
+	return s + "e" + strconv.Itoa(exp)
+}
+
+// FixedFormat is a TickFormatter that labels tick marks
+// with a fixed number of decimal places.
+type FixedFormat struct {
+	// Decimals is the number of decimal places.
+	Decimals int
+}
+
+var _ TickFormatter = FixedFormat{}
+
+// Format returns the label of a major tick mark at v.
+func (f FixedFormat) Format(v float64) string {
+	return strconv.FormatFloat(v, 'f', f.Decimals, 64)
+}
+
+// PercentFormat is a TickFormatter that labels tick marks
+// at fractions as percentages, such as 25% for 0.25.
+type PercentFormat struct {
+	// Decimals is the number of decimal
+	// places of the percentages.
+	Decimals int
+}
+
+var _ TickFormatter = PercentFormat{}
+
+// Format returns the label of a major tick mark at v.
+func (f PercentFormat) Format(v float64) string {
+	return strconv.FormatFloat(v*100, 'f', f.Decimals, 64) + "%"
+}
+
+// CurrencyFormat is a TickFormatter that labels tick marks as
+// amounts of money, with the currency symbol before the amount
+// and the digits of the whole amount grouped in threes, such as
+// $1,234.50 for 1234.5.
+type CurrencyFormat struct {
+	// Symbol is the currency symbol.
+	Symbol string
+
+	// Decimals is the number of decimal places.
+	Decimals int
+
+	// Separator separates the groups of digits
+	// of the whole amount. If Separator is empty,
+	// a comma is used.
+	Separator string
+}
+
+var _ TickFormatter = CurrencyFormat{}
+
+// Format returns the label of a major tick mark at v.
+func (f CurrencyFormat) Format(v float64) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', f.Decimals, 64)
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i:]
+	}
+	sep := f.Separator
+	if sep == "" {
+		sep = ","
+	}
+	var buf []byte
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		buf = append(buf, '-')
+	}
+	buf = append(buf, f.Symbol...)
+	for i := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			buf = append(buf, sep...)
+		}
+		buf = append(buf, whole[i])
+	}
+	buf = append(buf, frac...)
+	return string(buf)
+}
+
+// binaryPrefixes are the IEC prefixes of the powers of 1024.
+var binaryPrefixes = []string{"", "Ki", "Mi", "Gi", "Ti", "Pi", "Ei", "Zi", "Yi"}
+
+// ByteFormat is a TickFormatter that labels tick marks at
+// numbers of bytes as byte sizes with binary prefixes, such
+// as 512 B, 1.5 KiB and 2 MiB.
+type ByteFormat struct {
+	// Precision is the number of significant digits
+	// of the labels. If Precision is not positive,
+	// three significant digits are used.
+	Precision int
+}
+
+var _ TickFormatter = ByteFormat{}
+
+// Format returns the label of a major tick mark at v.
+func (f ByteFormat) Format(v float64) string {
+	sig := f.Precision
+	if sig <= 0 {
+		sig = defaultSignificant
+	}
+	i := 0
+	m := v
+	for math.Abs(m) >= 1024 && i < len(binaryPrefixes)-1 {
+		m /= 1024
+		i++
+	}
+	m, _ = strconv.ParseFloat(strconv.FormatFloat(m, 'g', sig, 64), 64)
+	return strconv.FormatFloat(m, 'f', -1, 64) + " " + binaryPrefixes[i] + "B"
+}
+
+// DurationFormat is a TickFormatter that labels tick marks at
+// durations as formatted by time.Duration, such as 1m30s for
+// a value of 90 seconds.
+type DurationFormat struct {
+	// Unit is the duration of a value of one.
+	// If Unit is zero, values are seconds.
+	Unit time.Duration
+
+	// Round is the duration to which the values are
+	// rounded. If Round is zero, values are rounded
+	// to the nearest millisecond.
+	Round time.Duration
+}
+
+var _ TickFormatter = DurationFormat{}
+
+// Format returns the label of a major tick mark at v.
+func (f DurationFormat) Format(v float64) string {
+	unit := f.Unit
+	if unit == 0 {
+		unit = time.Second
+	}
+	round := f.Round
+	if round <= 0 {
+		round = time.Millisecond
+	}
+	d := time.Duration(math.Floor(v*float64(unit)/float64(round)+0.5)) * round
+	return d.String()
+}
@@ -0,0 +1,72 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTickFormatters(t *testing.T) {
+	for _, test := range []struct {
+		f    TickFormatter
+		v    float64
+		want string
+	}{
+		{f: SIFormat{}, v: 0, want: "0"},
+		{f: SIFormat{}, v: 1500, want: "1.5k"},
+		{f: SIFormat{}, v: 2e6, want: "2M"},
+		{f: SIFormat{}, v: -0.00025, want: "-250µ"},
+		{f: SIFormat{}, v: 999.9, want: "1k"},
+		{f: SIFormat{Unit: "Hz"}, v: 12345678, want: "12.3MHz"},
+		{f: SIFormat{Precision: 2}, v: 1e30, want: "1e30"},
+		{f: EngineeringFormat{}, v: 1500, want: "1.5e3"},
+		{f: EngineeringFormat{}, v: 2e-5, want: "20e-6"},
+		{f: EngineeringFormat{}, v: 12, want: "12"},
+		{f: FixedFormat{Decimals: 2}, v: 0.1 + 0.2, want: "0.30"},
+		{f: FixedFormat{}, v: 1e6, want: "1000000"},
+		{f: PercentFormat{}, v: 0.25, want: "25%"},
+		{f: PercentFormat{Decimals: 1}, v: 0.0125, want: "1.2%"},
+		{f: CurrencyFormat{Symbol: "$", Decimals: 2}, v: 1234.5, want: "$1,234.50"},
+		{f: CurrencyFormat{Symbol: "€", Separator: "."}, v: -1234567, want: "-€1.234.567"},
+		{f: CurrencyFormat{Symbol: "$"}, v: -0.2, want: "$0"},
+		{f: ByteFormat{}, v: 512, want: "512 B"},
+		{f: ByteFormat{}, v: 1536, want: "1.5 KiB"},
+		{f: ByteFormat{}, v: 2 << 20, want: "2 MiB"},
+		{f: DurationFormat{}, v: 90, want: "1m30s"},
+		{f: DurationFormat{}, v: 0.1 + 0.2, want: "300ms"},
+		{f: DurationFormat{Unit: time.Hour}, v: 1.5, want: "1h30m0s"},
+	} {
+		got := test.f.Format(test.v)
+		if got != test.want {
+			t.Errorf("unexpected label of %v formatted by %#v: got:%q want:%q", test.v, test.f, got, test.want)
+		}
+	}
+}
+
+func TestAxisTickFormatter(t *testing.T) {
+	a, err := makeAxis()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ticks := ConstantTicks{{Value: 1000, Label: "a"}, {Value: 1500}, {Value: 2000, Label: "b"}}
+	a.Min, a.Max = 1000, 2000
+	a.Tick.Marker = ticks
+	a.Tick.Formatter = SIFormat{}
+	var got []string
+	for _, t := range a.ticks() {
+		got = append(got, t.Label)
+	}
+	want := []string{"1k", "", "2k"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("unexpected labels: got:%q want:%q", got, want)
+			break
+		}
+	}
+	if ticks[0].Label != "a" || ticks[2].Label != "b" {
+		t.Errorf("ticker labels modified by formatter: %v", ticks)
+	}
+}
@@ -27,6 +27,18 @@ type TextStyle struct {
 
 	// Font is the font description.
 	Font vg.Font
+
+	// Markup specifies whether the text is TeX-like
+	// markup, where ^{...} and _{...} are superscripts
+	// and subscripts and \alpha and other names in
+	// Symbols stand for symbols. Without markup, text
+	// is drawn as it is given.
+	Markup bool
+
+	// Rotation is the angle of the text in radians,
+	// counter-clockwise about the point at which it
+	// is drawn.
+	Rotation float64
 }
 
 // LineStyle describes what a line will look like.
@@ -330,6 +342,32 @@ func (ts Tiles) At(c Canvas, x, y int) Canvas {
 	}
 }
 
+// Annotates returns whether the underlying vg.Canvas
+// keeps annotations, that is whether it implements
+// the vg.Annotator interface and annotates.
+func (c Canvas) Annotates() bool {
+	vc, ok := c.Canvas.(vg.Annotator)
+	return ok && vc.Annotates()
+}
+
+// BeginGroup begins a group of elements described
+// by the given annotation if the underlying vg.Canvas
+// implements the vg.Annotator interface, otherwise
+// it does nothing.
+func (c Canvas) BeginGroup(a vg.Annotation) {
+	if vc, ok := c.Canvas.(vg.Annotator); ok {
+		vc.BeginGroup(a)
+	}
+}
+
+// EndGroup ends the group begun by the most recent
+// call to BeginGroup.
+func (c Canvas) EndGroup() {
+	if vc, ok := c.Canvas.(vg.Annotator); ok {
+		vc.EndGroup()
+	}
+}
+
 // SetLineStyle sets the current line style
 func (c *Canvas) SetLineStyle(sty LineStyle) {
 	c.SetColor(sty.Color)
@@ -541,30 +579,66 @@ func isect(p0, p1, clip, norm Point) Point {
 // The text is offset by its width times xalign and
 // its height times yalign.  x and y give the bottom
 // left corner of the text befor e it is offset.
+// Rotated text is offset along its rotated axes
+// and then rotated about x and y.
 func (c *Canvas) FillText(sty TextStyle, x, y vg.Length, xalign, yalign float64, txt string) {
 	txt = strings.TrimRight(txt, "\n")
 	if len(txt) == 0 {
 		return
 	}
 
+	if sty.Rotation != 0 {
+		c.Push()
+		c.Translate(x, y)
+		c.Rotate(sty.Rotation)
+		sty.Rotation = 0
+		c.FillText(sty, 0, 0, xalign, yalign, txt)
+		c.Pop()
+		return
+	}
+
 	c.SetColor(sty.Color)
 
 	ht := sty.Height(txt)
 	y += ht*vg.Length(yalign) - sty.Font.Extents().Ascent
-	nl := textNLines(txt)
-	for i, line := range strings.Split(txt, "\n") {
-		xoffs := vg.Length(xalign) * sty.Font.Width(line)
+	lines := strings.Split(txt, "\n")
+	if sty.Markup {
+		_, below := sty.extents(lines)
+		y += below
+	}
+	nl := len(lines)
+	for i, line := range lines {
 		n := vg.Length(nl - i)
-		c.FillString(sty.Font, x+xoffs, y+n*sty.Font.Size, line)
+		if !sty.Markup {
+			xoffs := vg.Length(xalign) * sty.Font.Width(line)
+			c.FillString(sty.Font, x+xoffs, y+n*sty.Font.Size, line)
+			continue
+		}
+		spans, w := markup(sty.Font, line)
+		xoffs := vg.Length(xalign) * w
+		for _, s := range spans {
+			c.FillString(s.font, x+xoffs+s.x, y+n*sty.Font.Size+s.y, s.text)
+		}
 	}
 }
 
 // Width returns the width of lines of text
-// when using the given font.
+// when using the given font. The width of
+// rotated text is the width of its bounds.
 func (sty TextStyle) Width(txt string) (max vg.Length) {
+	if sty.Rotation != 0 {
+		r := sty.Rectangle(txt)
+		return r.Max.X - r.Min.X
+	}
 	txt = strings.TrimRight(txt, "\n")
 	for _, line := range strings.Split(txt, "\n") {
-		if w := sty.Font.Width(line); w > max {
+		var w vg.Length
+		if sty.Markup {
+			_, w = markup(sty.Font, line)
+		} else {
+			w = sty.Font.Width(line)
+		}
+		if w > max {
 			max = w
 		}
 	}
@@ -572,20 +646,73 @@ func (sty TextStyle) Width(txt string) (max vg.Length) {
 }
 
 // Height returns the height of the text when using
-// the given font.
+// the given font. The height of markup includes the
+// superscripts of its first line and the subscripts
+// of its last line. The height of rotated text is
+// the height of its bounds.
 func (sty TextStyle) Height(txt string) vg.Length {
+	if sty.Rotation != 0 {
+		r := sty.Rectangle(txt)
+		return r.Max.Y - r.Min.Y
+	}
 	nl := textNLines(txt)
 	if nl == 0 {
 		return vg.Length(0)
 	}
 	e := sty.Font.Extents()
-	return e.Height*vg.Length(nl-1) + e.Ascent
+	ht := e.Height*vg.Length(nl-1) + e.Ascent
+	if sty.Markup {
+		above, below := sty.extents(strings.Split(strings.TrimRight(txt, "\n"), "\n"))
+		ht += above + below
+	}
+	return ht
+}
+
+// extents returns the distances that the first line of
+// markup extends above the ascent of the font, and that
+// the last line extends below the descent of the font.
+func (sty TextStyle) extents(lines []string) (above, below vg.Length) {
+	spans, _ := markup(sty.Font, lines[0])
+	above, _ = markupExtents(sty.Font, spans)
+	spans, _ = markup(sty.Font, lines[len(lines)-1])
+	_, below = markupExtents(sty.Font, spans)
+	return above, below
 }
 
 // Rectangle returns a rectangle giving the bounds of
 // this text assuming that it is drawn at 0, 0
 func (sty TextStyle) Rectangle(txt string) Rectangle {
-	return Rectangle{Max: Point{sty.Width(txt), sty.Height(txt)}}
+	return sty.AlignedRectangle(txt, 0, 0)
+}
+
+// AlignedRectangle returns a rectangle giving the bounds
+// of this text assuming that it is drawn at 0, 0 by FillText
+// with the given alignment. The bounds of rotated text are
+// the bounds of its rotated rectangle.
+func (sty TextStyle) AlignedRectangle(txt string, xalign, yalign float64) Rectangle {
+	rot := sty.Rotation
+	sty.Rotation = 0
+	w, h := sty.Width(txt), sty.Height(txt)
+	min := Point{X: w * vg.Length(xalign), Y: h * vg.Length(yalign)}
+	max := Point{X: min.X + w, Y: min.Y + h}
+	if rot == 0 {
+		return Rectangle{Min: min, Max: max}
+	}
+
+	sin, cos := vg.Length(math.Sin(rot)), vg.Length(math.Cos(rot))
+	var r Rectangle
+	for i, p := range []Point{min, {max.X, min.Y}, {min.X, max.Y}, max} {
+		q := Point{X: p.X*cos - p.Y*sin, Y: p.X*sin + p.Y*cos}
+		if i == 0 {
+			r = Rectangle{Min: q, Max: q}
+			continue
+		}
+		r.Min.X = vg.Length(math.Min(float64(r.Min.X), float64(q.X)))
+		r.Min.Y = vg.Length(math.Min(float64(r.Min.Y), float64(q.Y)))
+		r.Max.X = vg.Length(math.Max(float64(r.Max.X), float64(q.X)))
+		r.Max.Y = vg.Length(math.Max(float64(r.Max.Y), float64(q.Y)))
+	}
+	return r
 }
 
 // textNLines returns the number of lines in the text.
@@ -2,6 +2,7 @@ package draw
 
 import (
 	"image/color"
+	"math"
 	"reflect"
 	"testing"
 
@@ -111,3 +112,49 @@ func TestTile(t *testing.T) {
 		}
 	}
 }
+
+func TestRotatedText(t *testing.T) {
+	font, err := vg.MakeFont("Helvetica", 10)
+	if err != nil {
+		t.Fatalf("failed to create font: %v", err)
+	}
+	sty := TextStyle{Font: font}
+	w, h := sty.Width("text"), sty.Height("text")
+
+	sty.Rotation = math.Pi / 2
+	r := sty.AlignedRectangle("text", -0.5, 0)
+	want := Rectangle{Min: Point{X: -h, Y: -w / 2}, Max: Point{X: 0, Y: w / 2}}
+	const tol = 1e-9
+	if math.Abs(float64(r.Min.X-want.Min.X)) > tol || math.Abs(float64(r.Min.Y-want.Min.Y)) > tol ||
+		math.Abs(float64(r.Max.X-want.Max.X)) > tol || math.Abs(float64(r.Max.Y-want.Max.Y)) > tol {
+		t.Errorf("unexpected rotated rectangle: got:%v want:%v", r, want)
+	}
+	if got := sty.Width("text"); math.Abs(float64(got-h)) > tol {
+		t.Errorf("unexpected rotated width: got:%v want:%v", got, h)
+	}
+	if got := sty.Height("text"); math.Abs(float64(got-w)) > tol {
+		t.Errorf("unexpected rotated height: got:%v want:%v", got, w)
+	}
+
+	var rec recorder.Canvas
+	c := NewCanvas(&rec, 100, 100)
+	c.FillText(sty, 10, 20, 0, 0, "text")
+	var calls []string
+	for _, a := range rec.Actions {
+		switch a.(type) {
+		case *recorder.Push:
+			calls = append(calls, "Push")
+		case *recorder.Translate:
+			calls = append(calls, "Translate")
+		case *recorder.Rotate:
+			calls = append(calls, "Rotate")
+		case *recorder.FillString:
+			calls = append(calls, "FillString")
+		case *recorder.Pop:
+			calls = append(calls, "Pop")
+		}
+	}
+	if !reflect.DeepEqual(calls, []string{"Push", "Translate", "Rotate", "FillString", "Pop"}) {
+		t.Errorf("unexpected rotated text actions: got:%v", calls)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package draw
+
+import (
+	"unicode"
+
+	"github.com/gonum/plot/vg"
+)
+
+const (
+	// scriptScale is the font size of superscripts and
+	// subscripts relative to the text they follow.
+	scriptScale = 0.7
+
+	// superRise and subDrop are the distances that the baselines
+	// of superscripts are raised and those of subscripts lowered,
+	// relative to the font size of the text they follow.
+	superRise = 0.4
+	subDrop   = 0.2
+)
+
+// Symbols maps the names of the commands of text markup
+// to the text they stand for. Greek letters and mathematical
+// symbols are drawn using the font of the text, so the font
+// must include glyphs for them. Symbols may be added to the
+// map before drawing.
+var Symbols = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ",
+	"epsilon": "ε", "zeta": "ζ", "eta": "η", "theta": "θ",
+	"iota": "ι", "kappa": "κ", "lambda": "λ", "mu": "μ",
+	"nu": "ν", "xi": "ξ", "omicron": "ο", "pi": "π",
+	"rho": "ρ", "sigma": "σ", "tau": "τ", "upsilon": "υ",
+	"phi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+
+	"Alpha": "Α", "Beta": "Β", "Gamma": "Γ", "Delta": "Δ",
+	"Epsilon": "Ε", "Zeta": "Ζ", "Eta": "Η", "Theta": "Θ",
+	"Iota": "Ι", "Kappa": "Κ", "Lambda": "Λ", "Mu": "Μ",
+	"Nu": "Ν", "Xi": "Ξ", "Omicron": "Ο", "Pi": "Π",
+	"Rho": "Ρ", "Sigma": "Σ", "Tau": "Τ", "Upsilon": "Υ",
+	"Phi": "Φ", "Chi": "Χ", "Psi": "Ψ", "Omega": "Ω",
+
+	"pm": "±", "mp": "∓", "times": "×", "div": "÷", "cdot": "·",
+	"leq": "≤", "geq": "≥", "neq": "≠", "approx": "≈", "equiv": "≡",
+	"sim": "∼", "propto": "∝", "infty": "∞", "partial": "∂",
+	"nabla": "∇", "sum": "∑", "prod": "∏", "int": "∫", "sqrt": "√",
+	"degree": "°", "circ": "∘", "prime": "′", "angstrom": "Å",
+	"hbar": "ħ", "ell": "ℓ", "leftarrow": "←", "rightarrow": "→",
+	"in": "∈", "cdots": "⋯", "ldots": "…",
+}
+
+// span is a run of text that is drawn in a single font.
+type span struct {
+	text string
+	font vg.Font
+
+	// x and y are the offset of the start of the
+	// baseline of the span from the start of the
+	// baseline of its line.
+	x, y vg.Length
+}
+
+// markup lays out a line of text markup drawn in the given
+// font, returning its spans and width. The markup is a subset
+// of TeX: ^ and _ start a superscript or subscript of the next
+// character or {group}, \name stands for the text of the name
+// in Symbols, and \ followed by another character stands for
+// that character.
+func markup(font vg.Font, line string) (spans []span, width vg.Length) {
+	p := parser{in: []rune(line)}
+	width = p.group(font, 0, 0, false)
+	return p.spans, width
+}
+
+// parser is a text markup parser.
+type parser struct {
+	in    []rune
+	pos   int
+	spans []span
+}
+
+// group lays out the markup up to the end of the input, or
+// to the closing brace of the group if braced is true, and
+// returns the end of the baseline.
+func (p *parser) group(font vg.Font, x, y vg.Length, braced bool) vg.Length {
+	var text []rune
+	flush := func() {
+		if len(text) == 0 {
+			return
+		}
+		s := string(text)
+		p.spans = append(p.spans, span{text: s, font: font, x: x, y: y})
+		x += font.Width(s)
+		text = text[:0]
+	}
+	for p.pos < len(p.in) {
+		r := p.in[p.pos]
+		p.pos++
+		switch r {
+		case '}':
+			if braced {
+				flush()
+				return x
+			}
+			text = append(text, r)
+		case '{':
+			flush()
+			x = p.group(font, x, y, true)
+		case '^', '_':
+			flush()
+			x = p.scripts(font, x, y, r)
+		case '\\':
+			text = append(text, []rune(p.command())...)
+		default:
+			text = append(text, r)
+		}
+	}
+	flush()
+	return x
+}
+
+// scripts lays out the superscript or subscript started by op,
+// and the script of the other kind if it follows immediately,
+// so that a subscript and superscript are stacked.
+func (p *parser) scripts(font vg.Font, x, y vg.Length, op rune) vg.Length {
+	end := p.script(font, x, y, op)
+	if p.pos < len(p.in) && (p.in[p.pos] == '^' || p.in[p.pos] == '_') && p.in[p.pos] != op {
+		op = p.in[p.pos]
+		p.pos++
+		if e := p.script(font, x, y, op); e > end {
+			end = e
+		}
+	}
+	return end
+}
+
+// script lays out the superscript or subscript started by op.
+func (p *parser) script(font vg.Font, x, y vg.Length, op rune) vg.Length {
+	small := font
+	small.Size *= scriptScale
+	if op == '^' {
+		y += font.Size * superRise
+	} else {
+		y -= font.Size * subDrop
+	}
+	if p.pos >= len(p.in) {
+		return x
+	}
+	r := p.in[p.pos]
+	p.pos++
+	switch r {
+	case '{':
+		return p.group(small, x, y, true)
+	case '\\':
+		s := p.command()
+		p.spans = append(p.spans, span{text: s, font: small, x: x, y: y})
+		return x + small.Width(s)
+	}
+	p.spans = append(p.spans, span{text: string(r), font: small, x: x, y: y})
+	return x + small.Width(string(r))
+}
+
+// command returns the text of the command following a
+// backslash. Unknown commands stand for themselves.
+func (p *parser) command() string {
+	if p.pos >= len(p.in) {
+		return `\`
+	}
+	start := p.pos
+	for p.pos < len(p.in) && p.pos-start < 32 && isLetter(p.in[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		p.pos++
+		return string(p.in[start])
+	}
+	name := string(p.in[start:p.pos])
+	if s, ok := Symbols[name]; ok {
+		// A space ends a command without being drawn.
+		if p.pos < len(p.in) && p.in[p.pos] == ' ' {
+			p.pos++
+		}
+		return s
+	}
+	return `\` + name
+}
+
+func isLetter(r rune) bool {
+	return r < unicode.MaxASCII && unicode.IsLetter(r)
+}
+
+// markupExtents returns the distances that the spans of a line
+// extend above the ascent and below the descent of font.
+func markupExtents(font vg.Font, spans []span) (above, below vg.Length) {
+	e := font.Extents()
+	for _, s := range spans {
+		se := s.font.Extents()
+		if a := s.y + se.Ascent - e.Ascent; a > above {
+			above = a
+		}
+		if b := e.Descent - (s.y + se.Descent); b > below {
+			below = b
+		}
+	}
+	return above, below
+}
@@ -0,0 +1,115 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package draw
+
+import (
+	"testing"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/recorder"
+)
+
+func TestMarkup(t *testing.T) {
+	font, err := vg.MakeFont("Helvetica", 10)
+	if err != nil {
+		t.Fatalf("failed to create font: %v", err)
+	}
+	small := font
+	small.Size *= scriptScale
+
+	type want struct {
+		text string
+		size vg.Length
+		y    vg.Length
+	}
+	for _, test := range []struct {
+		in   string
+		want []want
+	}{
+		{in: "plain", want: []want{{"plain", 10, 0}}},
+		{in: `\sigma^2`, want: []want{{"σ", 10, 0}, {"2", 7, 4}}},
+		{in: "m/s^{2}", want: []want{{"m/s", 10, 0}, {"2", 7, 4}}},
+		{in: `10^{-3}`, want: []want{{"10", 10, 0}, {"-3", 7, 4}}},
+		{in: "x_i^2", want: []want{{"x", 10, 0}, {"i", 7, -2}, {"2", 7, 4}}},
+		{in: `a\_b \{c\}`, want: []want{{"a_b {c}", 10, 0}}},
+		{in: `\unknown \alpha x`, want: []want{{`\unknown αx`, 10, 0}}},
+		{in: `e^{i\pi}`, want: []want{{"e", 10, 0}, {"iπ", 7, 4}}},
+	} {
+		spans, width := markup(font, test.in)
+		if len(spans) != len(test.want) {
+			t.Errorf("unexpected number of spans for %q: got:%d want:%d", test.in, len(spans), len(test.want))
+			continue
+		}
+		var end vg.Length
+		for i, s := range spans {
+			w := test.want[i]
+			if s.text != w.text || s.font.Size != w.size || !near(s.y, w.y) {
+				t.Errorf("unexpected span %d for %q: got:(%q, %v, %v) want:(%q, %v, %v)",
+					i, test.in, s.text, s.font.Size, s.y, w.text, w.size, w.y)
+			}
+			if e := s.x + s.font.Width(s.text); e > end {
+				end = e
+			}
+		}
+		if width != end {
+			t.Errorf("unexpected width for %q: got:%v want:%v", test.in, width, end)
+		}
+	}
+
+	// Stacked scripts start at the same position.
+	spans, _ := markup(font, "x_i^2")
+	if spans[1].x != spans[2].x {
+		t.Errorf("scripts not stacked: got:%v and %v", spans[1].x, spans[2].x)
+	}
+}
+
+func near(a, b vg.Length) bool {
+	const tol = 1e-9
+	return a-b < tol && b-a < tol
+}
+
+func TestMarkupText(t *testing.T) {
+	font, err := vg.MakeFont("Helvetica", 10)
+	if err != nil {
+		t.Fatalf("failed to create font: %v", err)
+	}
+	plain := TextStyle{Font: font}
+	sty := TextStyle{Font: font, Markup: true}
+
+	if got, want := sty.Width("x^2"), plain.Width("x")+scriptScale*plain.Width("2"); !near(got, want) {
+		t.Errorf("unexpected width: got:%v want:%v", got, want)
+	}
+	if got, want := sty.Width("a_b"), plain.Width("a_b"); got >= want {
+		t.Errorf("markup width not less than plain width: got:%v plain:%v", got, want)
+	}
+	if got, want := sty.Height("x"), plain.Height("x"); got != want {
+		t.Errorf("unexpected height without scripts: got:%v want:%v", got, want)
+	}
+	if got, want := sty.Height("x^2"), plain.Height("x"); got <= want {
+		t.Errorf("superscript does not increase height: got:%v plain:%v", got, want)
+	}
+	if got, want := sty.Height("x_2"), plain.Height("x"); got <= want {
+		t.Errorf("subscript does not increase height: got:%v plain:%v", got, want)
+	}
+
+	var r recorder.Canvas
+	c := NewCanvas(&r, 100, 100)
+	c.FillText(sty, 10, 10, 0, 0, "x^2")
+	var got []*recorder.FillString
+	for _, a := range r.Actions {
+		if fs, ok := a.(*recorder.FillString); ok {
+			got = append(got, fs)
+		}
+	}
+	if len(got) != 2 {
+		t.Fatalf("unexpected number of strings: got:%d want:2", len(got))
+	}
+	if got[0].String != "x" || got[1].String != "2" || got[1].Size != 7 {
+		t.Errorf("unexpected strings: got:%q at %v and %q at %v", got[0].String, got[0].Size, got[1].String, got[1].Size)
+	}
+	if !near(got[1].Y-got[0].Y, 4) || !near(got[1].X-got[0].X, font.Width("x")) {
+		t.Errorf("unexpected superscript offset: got:(%v, %v)", got[1].X-got[0].X, got[1].Y-got[0].Y)
+	}
+}
@@ -11,6 +11,8 @@
 package vg
 
 import (
+	"bytes"
+	"encoding/gob"
 	"errors"
 	"go/build"
 	"io/ioutil"
@@ -34,7 +36,11 @@ const (
 var (
 	// FontMap maps Postscript/PDF font names to compatible
 	// free fonts (TrueType converted ghostscript fonts).
-	// Fonts that are not keys of this map are not supported.
+	// The fonts that are keys of this map are the standard
+	// fonts, which are referred to by name in PDF and
+	// Postscript output. The font file for any other name
+	// is the name with the .ttf extension, and fonts may
+	// also be added with AddFontData and LoadFont.
 	FontMap = map[string]string{
 
 		// At the moment, we use fonts from GNU's freefont
@@ -60,7 +66,12 @@ var (
 	// caches the associated *truetype.Font.
 	loadedFonts = make(map[string]*truetype.Font)
 
-	// FontLock protects access to the loadedFonts map.
+	// fontData is indexed by a font name and it
+	// caches the TrueType data of the font.
+	fontData = make(map[string][]byte)
+
+	// FontLock protects access to the loadedFonts
+	// and fontData maps.
 	fontLock sync.RWMutex
 )
 
@@ -92,18 +103,23 @@ type Font struct {
 	// font is the truetype font pointer for this
 	// font.
 	font *truetype.Font
+
+	// data is the TrueType data of the font, or
+	// nil if it is not known.
+	data []byte
 }
 
-// MakeFont returns a font object.  The name of the font must
-// be a key of the FontMap.  The font file is located by searching
-// the FontDirs slice for a directory containing the relevant font
-// file.  The font file name is name mapped by FontMap with the
-// .ttf extension.  For example, the font file for the font name
-// Courier is NimbusMonL-Regu.ttf.
+// MakeFont returns a font object.  Unless the font has been
+// added with AddFont, AddFontData or LoadFont, the font file is
+// located by searching the FontDirs slice for a directory
+// containing the relevant font file.  The font file name is name
+// mapped by FontMap with the .ttf extension, or name with the .ttf
+// extension if name is not a key of FontMap.  For example, the font
+// file for the font name Courier is NimbusMonL-Regu.ttf.
 func MakeFont(name string, size Length) (font Font, err error) {
 	font.Size = size
 	font.name = name
-	font.font, err = getFont(name)
+	font.font, font.data, err = getFont(name)
 	return
 }
 
@@ -117,19 +133,65 @@ func (f *Font) Font() *truetype.Font {
 	return f.font
 }
 
+// Data returns the TrueType data of the font, or nil if the
+// font was added with AddFont and its data is not known.
+func (f *Font) Data() []byte {
+	return f.data
+}
+
+// IsStandard returns whether the font is one of the standard
+// fonts that are keys of the FontMap.
+func (f *Font) IsStandard() bool {
+	_, ok := FontMap[f.name]
+	return ok
+}
+
 // SetName sets the name of the font, effectively
 // changing the font.  If an error is returned then
 // the font is left unchanged.
 func (f *Font) SetName(name string) error {
-	font, err := getFont(name)
+	font, data, err := getFont(name)
 	if err != nil {
 		return err
 	}
 	f.name = name
 	f.font = font
+	f.data = data
 	return nil
 }
 
+// fontGob is the gob encoding of a Font.
+type fontGob struct {
+	Name string
+	Size Length
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+// Only the name and size of the font are encoded.
+func (f Font) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(fontGob{Name: f.name, Size: f.Size})
+	return buf.Bytes(), err
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+// The font is looked up by its encoded name.
+func (f *Font) GobDecode(b []byte) error {
+	var g fontGob
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&g)
+	if err != nil {
+		return err
+	}
+	f.Size = g.Size
+	if g.Name == "" {
+		f.name = ""
+		f.font = nil
+		f.data = nil
+		return nil
+	}
+	return f.SetName(g.Name)
+}
+
 // FontExtents contains font metric information.
 type FontExtents struct {
 	// Ascent is the distance that the text
@@ -177,47 +239,77 @@ func (f *Font) Width(s string) Length {
 }
 
 // AddFont associates a truetype.Font with the given name.
+// The TrueType data of a font added with AddFont is not known,
+// so backends that embed fonts refer to it by name instead.
 func AddFont(name string, font *truetype.Font) {
 	fontLock.Lock()
 	loadedFonts[name] = font
+	delete(fontData, name)
+	fontLock.Unlock()
+}
+
+// AddFontData parses the TrueType font data and associates
+// the font with the given name.
+func AddFontData(name string, data []byte) error {
+	font, err := freetype.ParseFont(data)
+	if err != nil {
+		return errors.New("Failed to parse font data: " + err.Error())
+	}
+	fontLock.Lock()
+	loadedFonts[name] = font
+	fontData[name] = data
 	fontLock.Unlock()
+	return nil
+}
+
+// LoadFont reads the TrueType font file at the given path
+// and associates the font with the given name.
+func LoadFont(name, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.New("Failed to read font file: " + err.Error())
+	}
+	return AddFontData(name, data)
 }
 
-// getFont returns the truetype.Font for the given font name or an error.
-func getFont(name string) (*truetype.Font, error) {
+// getFont returns the truetype.Font and its TrueType data
+// for the given font name or an error.
+func getFont(name string) (*truetype.Font, []byte, error) {
 	fontLock.RLock()
 	f, ok := loadedFonts[name]
+	data := fontData[name]
 	fontLock.RUnlock()
 	if ok {
-		return f, nil
+		return f, data, nil
 	}
 
 	path, err := fontPath(name)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, errors.New("Failed to open font file: " + err.Error())
+		return nil, nil, errors.New("Failed to open font file: " + err.Error())
 	}
 	defer file.Close()
 
 	bytes, err := ioutil.ReadAll(file)
 	if err != nil {
-		return nil, errors.New("Failed to read font file: " + err.Error())
+		return nil, nil, errors.New("Failed to read font file: " + err.Error())
 	}
 
 	font, err := freetype.ParseFont(bytes)
 	if err == nil {
 		fontLock.Lock()
 		loadedFonts[name] = font
+		fontData[name] = bytes
 		fontLock.Unlock()
 	} else {
 		err = errors.New("Failed to parse font file: " + err.Error())
 	}
 
-	return font, err
+	return font, bytes, err
 }
 
 // FontPath returns the path for a font name or an error if it is not found.
@@ -235,6 +327,9 @@ func fontPath(name string) (string, error) {
 		return p, nil
 	}
 
+	if _, ok := FontMap[name]; !ok {
+		return "", errors.New("Unknown font: " + name + ".  Failed to locate a font file " + fname)
+	}
 	return "", errors.New("Failed to locate a font file " + fname + " for font name " + name)
 }
 
@@ -269,16 +364,14 @@ func initFontDirs() []string {
 }
 
 // FontFile returns the font file name for a font name or an error
-// if it is an unknown font (i.e., not in the FontMap).
+// if the name is empty.
 func fontFile(name string) (string, error) {
-	var err error
+	if name == "" {
+		return "", errors.New("Unknown font: empty font name")
+	}
 	n, ok := FontMap[name]
 	if !ok {
-		errStr := "Unknown font: " + name + ".  Available fonts are:"
-		for n := range FontMap {
-			errStr += " " + n
-		}
-		err = errors.New(errStr)
+		n = name
 	}
-	return n + ".ttf", err
+	return n + ".ttf", nil
 }
@@ -0,0 +1,110 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vg_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/gonum/plot/vg"
+)
+
+func TestLoadFont(t *testing.T) {
+	err := vg.LoadFont("Corporate", filepath.Join("fonts", "NimbusSanL-Regu.ttf"))
+	if err != nil {
+		t.Fatalf("failed to load font: %v", err)
+	}
+	f, err := vg.MakeFont("Corporate", 12)
+	if err != nil {
+		t.Fatalf("failed to make font: %v", err)
+	}
+	if f.IsStandard() {
+		t.Error("loaded font is standard")
+	}
+	if f.Data() == nil {
+		t.Error("loaded font has no data")
+	}
+	std, err := vg.MakeFont("Helvetica", 12)
+	if err != nil {
+		t.Fatalf("failed to make font: %v", err)
+	}
+	if got, want := f.Width("Text"), std.Width("Text"); got != want {
+		t.Errorf("unexpected width: got:%v want:%v", got, want)
+	}
+
+	err = vg.LoadFont("Missing", filepath.Join("fonts", "missing.ttf"))
+	if err == nil {
+		t.Error("expected error loading missing font file")
+	}
+	_, err = vg.MakeFont("Missing", 12)
+	if err == nil {
+		t.Error("expected error making unknown font")
+	}
+}
+
+func TestFontGobReuse(t *testing.T) {
+	f, err := vg.MakeFont("Helvetica", 12)
+	if err != nil {
+		t.Fatalf("failed to make font: %v", err)
+	}
+	if f.Data() == nil {
+		t.Skip("no data for standard font")
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(vg.Font{Size: 10}); err != nil {
+		t.Fatalf("failed to encode font: %v", err)
+	}
+	if err := gob.NewDecoder(&buf).Decode(&f); err != nil {
+		t.Fatalf("failed to decode font: %v", err)
+	}
+	if f.Name() != "" || f.Font() != nil || f.Data() != nil || f.Size != 10 {
+		t.Errorf("unexpected font decoded into used font: name:%q size:%v has data:%t", f.Name(), f.Size, f.Data() != nil)
+	}
+}
+
+func TestSubset(t *testing.T) {
+	f, err := vg.MakeFont("Helvetica", 12)
+	if err != nil {
+		t.Fatalf("failed to make font: %v", err)
+	}
+	data, err := f.Subset("ab")
+	if err != nil {
+		t.Fatalf("failed to subset font: %v", err)
+	}
+	if len(data) >= len(f.Data()) {
+		t.Errorf("subset is not smaller than font: got:%d font:%d", len(data), len(f.Data()))
+	}
+	sub, err := truetype.Parse(data)
+	if err != nil {
+		t.Fatalf("failed to parse subset: %v", err)
+	}
+	scale := fixed.Int26_6(sub.FUnitsPerEm())
+	var g truetype.GlyphBuf
+	for _, test := range []struct {
+		r    rune
+		kept bool
+	}{
+		{r: 'a', kept: true},
+		{r: 'b', kept: true},
+		{r: 'c', kept: false},
+	} {
+		err = g.Load(sub, scale, sub.Index(test.r), font.HintingNone)
+		if err != nil {
+			t.Fatalf("failed to load glyph %q: %v", test.r, err)
+		}
+		if kept := len(g.Points) > 0; kept != test.kept {
+			t.Errorf("unexpected outline for %q: got:%t want:%t", test.r, kept, test.kept)
+		}
+		if got, want := sub.HMetric(scale, sub.Index(test.r)), f.Font().HMetric(scale, f.Font().Index(test.r)); got != want {
+			t.Errorf("unexpected metrics for %q: got:%v want:%v", test.r, got, want)
+		}
+	}
+}
@@ -7,6 +7,7 @@ package recorder
 
 import (
 	"fmt"
+	"image"
 	"image/color"
 	"runtime"
 
@@ -404,6 +405,34 @@ func (a *FillString) callerLocation() *callerLocation {
 	return &a.l
 }
 
+// DrawImage corresponds to the vg.Canvas.DrawImage method.
+type DrawImage struct {
+	X, Y, W, H vg.Length
+	Image      image.Image
+
+	l callerLocation
+}
+
+// DrawImage implements the DrawImage method of the vg.Canvas interface.
+func (c *Canvas) DrawImage(x, y, w, h vg.Length, img image.Image) {
+	c.append(&DrawImage{X: x, Y: y, W: w, H: h, Image: img})
+}
+
+// Call returns the pseudo method call that generated the action.
+// The image is given by its bounds.
+func (a *DrawImage) Call() string {
+	return fmt.Sprintf("%sDrawImage(%v, %v, %v, %v, %v)", a.l, a.X, a.Y, a.W, a.H, a.Image.Bounds())
+}
+
+// ApplyTo applies the action to the given vg.Canvas.
+func (a *DrawImage) ApplyTo(c vg.Canvas) {
+	c.DrawImage(a.X, a.Y, a.W, a.H, a.Image)
+}
+
+func (a *DrawImage) callerLocation() *callerLocation {
+	return &a.l
+}
+
 // Commenter defines types that can record comments.
 type Commenter interface {
 	Comment(string)
@@ -0,0 +1,238 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vg
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+
+	"github.com/golang/freetype/truetype"
+)
+
+// Subset returns the TrueType data of the font with the outlines
+// of the glyphs that are not needed to draw the given text removed.
+// Glyph indices are unchanged, so the character map and metrics of
+// the subset are those of the complete font. Subset returns an error
+// if the TrueType data of the font is not known.
+func (f *Font) Subset(text string) ([]byte, error) {
+	if f.data == nil {
+		return nil, errors.New("vg: font data of " + f.name + " is not known")
+	}
+	glyphs := []truetype.Index{0}
+	for _, r := range text {
+		glyphs = append(glyphs, f.font.Index(r))
+	}
+	return subset(f.data, glyphs)
+}
+
+// ttfTable is an entry of the table directory of a TrueType font.
+type ttfTable struct {
+	tag  string
+	data []byte
+}
+
+// byTag sorts TrueType tables by their tags.
+type byTag []ttfTable
+
+func (t byTag) Len() int           { return len(t) }
+func (t byTag) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t byTag) Less(i, j int) bool { return t[i].tag < t[j].tag }
+
+// subset returns the TrueType font data with the outlines of all
+// glyphs except the given glyphs and their components removed.
+// The loca table of the subset is always in the long format.
+func subset(data []byte, glyphs []truetype.Index) ([]byte, error) {
+	tables, err := ttfTables(data)
+	if err != nil {
+		return nil, err
+	}
+	var head, maxp, loca, glyf *ttfTable
+	for i := range tables {
+		switch tables[i].tag {
+		case "head":
+			head = &tables[i]
+		case "maxp":
+			maxp = &tables[i]
+		case "loca":
+			loca = &tables[i]
+		case "glyf":
+			glyf = &tables[i]
+		}
+	}
+	if head == nil || maxp == nil || loca == nil || glyf == nil ||
+		len(head.data) < 54 || len(maxp.data) < 6 {
+		return nil, errors.New("vg: font has no TrueType outlines")
+	}
+
+	n := int(binary.BigEndian.Uint16(maxp.data[4:]))
+	long := binary.BigEndian.Uint16(head.data[50:]) != 0
+	offsets := make([]int, n+1)
+	for i := range offsets {
+		if long {
+			if 4*i+4 > len(loca.data) {
+				return nil, errors.New("vg: invalid loca table")
+			}
+			offsets[i] = int(binary.BigEndian.Uint32(loca.data[4*i:]))
+		} else {
+			if 2*i+2 > len(loca.data) {
+				return nil, errors.New("vg: invalid loca table")
+			}
+			offsets[i] = 2 * int(binary.BigEndian.Uint16(loca.data[2*i:]))
+		}
+	}
+	glyph := func(i int) []byte {
+		if i >= n || offsets[i] >= offsets[i+1] || offsets[i+1] > len(glyf.data) {
+			return nil
+		}
+		return glyf.data[offsets[i]:offsets[i+1]]
+	}
+
+	keep := make(map[int]bool)
+	stack := make([]int, len(glyphs))
+	for i, g := range glyphs {
+		stack[i] = int(g)
+	}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if keep[i] || i >= n {
+			continue
+		}
+		keep[i] = true
+		stack = append(stack, components(glyph(i))...)
+	}
+
+	var newGlyf []byte
+	newLoca := make([]byte, 4*(n+1))
+	for i := 0; i < n; i++ {
+		binary.BigEndian.PutUint32(newLoca[4*i:], uint32(len(newGlyf)))
+		if keep[i] {
+			newGlyf = append(newGlyf, glyph(i)...)
+			for len(newGlyf)%4 != 0 {
+				newGlyf = append(newGlyf, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*n:], uint32(len(newGlyf)))
+
+	newHead := append([]byte(nil), head.data...)
+	binary.BigEndian.PutUint16(newHead[50:], 1)
+	head.data, loca.data, glyf.data = newHead, newLoca, newGlyf
+	return ttfData(tables), nil
+}
+
+// components returns the indices of the components
+// of the glyph if it is a composite glyph.
+func components(g []byte) []int {
+	if len(g) < 10 || int16(binary.BigEndian.Uint16(g)) >= 0 {
+		return nil
+	}
+	const (
+		argsAreWords    = 0x0001
+		haveScale       = 0x0008
+		moreComponents  = 0x0020
+		haveXYScale     = 0x0040
+		haveTwoByTwo    = 0x0080
+		componentHeader = 4
+	)
+	var comps []int
+	for p := 10; p+componentHeader <= len(g); {
+		flags := binary.BigEndian.Uint16(g[p:])
+		comps = append(comps, int(binary.BigEndian.Uint16(g[p+2:])))
+		p += componentHeader
+		if flags&argsAreWords != 0 {
+			p += 4
+		} else {
+			p += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			p += 2
+		case flags&haveXYScale != 0:
+			p += 4
+		case flags&haveTwoByTwo != 0:
+			p += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+	return comps
+}
+
+// ttfTables returns the tables of the TrueType font data.
+func ttfTables(data []byte) ([]ttfTable, error) {
+	if len(data) < 12 {
+		return nil, errors.New("vg: invalid TrueType font data")
+	}
+	n := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*n {
+		return nil, errors.New("vg: invalid TrueType table directory")
+	}
+	tables := make([]ttfTable, n)
+	for i := range tables {
+		e := data[12+16*i:]
+		off := int(binary.BigEndian.Uint32(e[8:]))
+		length := int(binary.BigEndian.Uint32(e[12:]))
+		if off < 0 || length < 0 || off+length > len(data) {
+			return nil, errors.New("vg: invalid TrueType table " + string(e[:4]))
+		}
+		tables[i] = ttfTable{tag: string(e[:4]), data: data[off : off+length]}
+	}
+	return tables, nil
+}
+
+// ttfData returns the TrueType font data holding the
+// given tables, with the table checksums and the
+// checksum adjustment of the head table updated.
+func ttfData(tables []ttfTable) []byte {
+	sort.Sort(byTag(tables))
+	n := len(tables)
+	searchRange, entrySelector := 1, 0
+	for searchRange*2 <= n {
+		searchRange *= 2
+		entrySelector++
+	}
+
+	data := make([]byte, 12+16*n)
+	binary.BigEndian.PutUint32(data, 0x00010000)
+	binary.BigEndian.PutUint16(data[4:], uint16(n))
+	binary.BigEndian.PutUint16(data[6:], uint16(16*searchRange))
+	binary.BigEndian.PutUint16(data[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(data[10:], uint16(16*(n-searchRange)))
+	head := -1
+	for i, t := range tables {
+		if t.tag == "head" {
+			head = len(data)
+			t.data = append([]byte(nil), t.data...)
+			binary.BigEndian.PutUint32(t.data[8:], 0)
+		}
+		e := data[12+16*i:]
+		copy(e, t.tag)
+		binary.BigEndian.PutUint32(e[4:], checksum(t.data))
+		binary.BigEndian.PutUint32(e[8:], uint32(len(data)))
+		binary.BigEndian.PutUint32(e[12:], uint32(len(t.data)))
+		data = append(data, t.data...)
+		for len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+	}
+	if head >= 0 {
+		binary.BigEndian.PutUint32(data[head+8:], 0xb1b0afba-checksum(data))
+	}
+	return data
+}
+
+// checksum returns the TrueType checksum of b.
+func checksum(b []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(b); i += 4 {
+		var v [4]byte
+		copy(v[:], b[i:])
+		sum += binary.BigEndian.Uint32(v[:])
+	}
+	return sum
+}
@@ -8,6 +8,7 @@
 package vg
 
 import (
+	"image"
 	"image/color"
 	"io"
 )
@@ -74,6 +75,12 @@ type Canvas interface {
 	// FillString fills in text at the specified
 	// location using the given font.
 	FillString(f Font, x, y Length, text string)
+
+	// DrawImage draws the image scaled to fill the
+	// rectangle of width w and height h with its lower
+	// left corner at x, y. The top row of the image
+	// is drawn at the top of the rectangle.
+	DrawImage(x, y, w, h Length, img image.Image)
 }
 
 // CanvasSizer is a Canvas with a defined size.
@@ -88,6 +95,42 @@ type CanvasWriterTo interface {
 	io.WriterTo
 }
 
+// An Annotation describes a group of drawn elements
+// for canvases that implement Annotator.
+type Annotation struct {
+	// ID is a unique identifier of the group.
+	ID string
+
+	// Class is a space separated list of class
+	// names given to the group.
+	Class string
+
+	// Title is a description of the group, for
+	// example text to show in a tooltip.
+	Title string
+}
+
+// Annotator is a Canvas that can group the elements
+// drawn on it and attach an Annotation to each group.
+// Groups may be nested, and must be ended within
+// the Push and Pop pair that they were begun in.
+type Annotator interface {
+	Canvas
+
+	// Annotates returns whether the canvas keeps
+	// annotations. If it does not, BeginGroup and
+	// EndGroup do nothing.
+	Annotates() bool
+
+	// BeginGroup begins a group of elements
+	// described by the given Annotation.
+	BeginGroup(Annotation)
+
+	// EndGroup ends the most recently begun
+	// group.
+	EndGroup()
+}
+
 // Initialize sets all of the canvas's values to their
 // initial values.
 func Initialize(c Canvas) {
@@ -0,0 +1,255 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vgeps
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/image/math/fixed"
+
+	"github.com/gonum/plot/vg"
+)
+
+// font is a TrueType font that is embedded in the
+// output as a Type 42 font.
+type font struct {
+	vg.Font
+
+	// name is the Postscript name of the font.
+	name string
+
+	// codes maps the runes drawn in the font to
+	// character codes of the font encoding, and
+	// runes holds the runes in code order.
+	codes map[rune]byte
+	runes []rune
+}
+
+// embed returns the embedded font for fnt, adding it
+// to the canvas if fnt has not been used before. Fonts
+// with unknown TrueType data are not embedded and nil
+// is returned for them.
+func (e *Canvas) embed(fnt vg.Font) *font {
+	if fnt.Data() == nil {
+		return nil
+	}
+	for _, f := range e.fonts {
+		if f.Name() == fnt.Name() {
+			return f
+		}
+	}
+	name := fnt.Name()
+	if std, ok := vg.FontMap[name]; ok {
+		// Embedded standard fonts are named after
+		// their font file so that they are not
+		// mistaken for the Postscript fonts.
+		name = std
+	}
+	f := &font{
+		Font:  fnt,
+		name:  psName(name),
+		codes: make(map[rune]byte),
+	}
+	e.fonts = append(e.fonts, f)
+	return f
+}
+
+// encode returns str as a Postscript string of the character
+// codes of the font, assigning codes to runes that have not been
+// drawn before. Runes that do not fit in the 255 available codes
+// are drawn as the missing glyph.
+func (f *font) encode(str string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('(')
+	for _, r := range str {
+		c, ok := f.codes[r]
+		if !ok && len(f.runes) < 255 {
+			f.runes = append(f.runes, r)
+			c = byte(len(f.runes))
+			f.codes[r] = c
+		}
+		fmt.Fprintf(&buf, "\\%03o", c)
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+// latin1 returns the name of the standard font reencoded
+// to ISO Latin-1, adding the font to the fonts that are
+// reencoded by the canvas if it has not been used before.
+func (e *Canvas) latin1(name string) string {
+	for _, n := range e.reencoded {
+		if n == name {
+			return name + "-Latin1"
+		}
+	}
+	e.reencoded = append(e.reencoded, name)
+	return name + "-Latin1"
+}
+
+// quote returns str as a Postscript string of ISO Latin-1
+// character codes. Runes outside Latin-1 are drawn as '?'.
+func quote(str string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('(')
+	for _, r := range str {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r < 0x20 || r > 0xff:
+			buf.WriteByte('?')
+		case r > 0x7e:
+			fmt.Fprintf(&buf, "\\%03o", r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func isLatin1(s string) bool {
+	for _, r := range s {
+		if r > 0xff {
+			return false
+		}
+	}
+	return true
+}
+
+// writeTo writes the Type 42 definition of the font,
+// holding the glyphs of the drawn runes, to w.
+func (f *font) writeTo(w io.Writer) error {
+	data, err := f.Subset(string(f.runes))
+	if err != nil {
+		return err
+	}
+	ttf := f.Font.Font()
+	units := ttf.FUnitsPerEm()
+	b := ttf.Bounds(fixed.Int26_6(units))
+	em := float64(units)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%%%%BeginResource: font %s\n", f.name)
+	buf.WriteString("11 dict begin\n")
+	fmt.Fprintf(&buf, "/FontName /%s def\n", f.name)
+	buf.WriteString("/FontType 42 def\n")
+	buf.WriteString("/PaintType 0 def\n")
+	buf.WriteString("/FontMatrix [1 0 0 1 0 0] def\n")
+	fmt.Fprintf(&buf, "/FontBBox [%.*g %.*g %.*g %.*g] def\n",
+		pr, float64(b.Min.X)/em, pr, float64(b.Min.Y)/em,
+		pr, float64(b.Max.X)/em, pr, float64(b.Max.Y)/em)
+	buf.WriteString("/Encoding 256 array\n0 1 255 {1 index exch /.notdef put} for\n")
+	for i := range f.runes {
+		fmt.Fprintf(&buf, "dup %d /c%d put\n", i+1, i+1)
+	}
+	buf.WriteString("readonly def\n")
+	fmt.Fprintf(&buf, "/CharStrings %d dict dup begin\n/.notdef 0 def\n", len(f.runes)+1)
+	for i, r := range f.runes {
+		fmt.Fprintf(&buf, "/c%d %d def\n", i+1, ttf.Index(r))
+	}
+	buf.WriteString("end readonly def\n")
+	buf.WriteString("/sfnts [\n")
+	for _, s := range sfnts(data) {
+		buf.WriteByte('<')
+		for i := 0; i < len(s); i += 32 {
+			if i > 0 {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString(hex.EncodeToString(s[i:min(i+32, len(s))]))
+		}
+		// Type 42 strings hold an extra padding byte.
+		buf.WriteString("00>\n")
+	}
+	buf.WriteString("] def\n")
+	buf.WriteString("FontName currentdict end definefont pop\n")
+	buf.WriteString("%%EndResource\n")
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// maxString is the largest number of bytes of font
+// data held by a string of the sfnts array.
+const maxString = 65534
+
+// sfnts splits the TrueType font data into the strings of the
+// sfnts array of a Type 42 font. Strings end at table boundaries,
+// and the glyf table is split at glyph boundaries if it is too
+// large for a single string. The data must have a long format
+// loca table, as returned by vg.Font.Subset.
+func sfnts(data []byte) [][]byte {
+	n := int(binary.BigEndian.Uint16(data[4:]))
+	breaks := []int{12 + 16*n, len(data)}
+	glyf, loca, locaLen := -1, -1, 0
+	for i := 0; i < n; i++ {
+		e := data[12+16*i:]
+		off := int(binary.BigEndian.Uint32(e[8:]))
+		length := int(binary.BigEndian.Uint32(e[12:]))
+		breaks = append(breaks, off, off+length+(4-length%4)%4)
+		switch string(e[:4]) {
+		case "glyf":
+			glyf = off
+		case "loca":
+			loca, locaLen = off, length
+		}
+	}
+	if glyf >= 0 && loca >= 0 {
+		for p := loca; p+4 <= loca+locaLen; p += 4 {
+			breaks = append(breaks, glyf+int(binary.BigEndian.Uint32(data[p:])))
+		}
+	}
+	sort.Ints(breaks)
+
+	var s [][]byte
+	start, end := 0, 0
+	for _, b := range breaks {
+		if b <= end || b > len(data) {
+			continue
+		}
+		if b-start > maxString && end > start {
+			s = append(s, data[start:end])
+			start = end
+		}
+		end = b
+	}
+	if end > start {
+		s = append(s, data[start:end])
+	}
+	return s
+}
+
+// psName returns name with the characters that
+// may not appear in a Postscript name replaced.
+func psName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || strings.ContainsRune("()<>[]{}/%", r) {
+			return '-'
+		}
+		return r
+	}, name)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -9,7 +9,9 @@ package vgeps
 import (
 	"bufio"
 	"bytes"
+	"encoding/hex"
 	"fmt"
+	"image"
 	"image/color"
 	"io"
 	"math"
@@ -25,6 +27,12 @@ type Canvas struct {
 	stk  []ctx
 	w, h vg.Length
 	buf  *bytes.Buffer
+
+	// prolog is the offset in buf at which the
+	// definitions of embedded fonts are written.
+	prolog    int
+	fonts     []*font
+	reencoded []string
 }
 
 type ctx struct {
@@ -62,6 +70,7 @@ func NewTitle(w, h vg.Length, title string) *Canvas {
 	c.buf.WriteString("%%Orientation: Portrait\n")
 	c.buf.WriteString("%%EndComments\n")
 	c.buf.WriteString("\n")
+	c.prolog = c.buf.Len()
 	vg.Initialize(c)
 	return c
 }
@@ -176,28 +185,113 @@ func (e *Canvas) trace(path vg.Path) {
 	}
 }
 
+// DrawImage draws the image scaled to fill the rectangle
+// of width w and height h with its lower left corner at x, y.
+// Postscript images are opaque, so transparent pixels are
+// drawn as if over white.
+func (e *Canvas) DrawImage(x, y, w, h vg.Length, img image.Image) {
+	b := img.Bounds()
+	if b.Empty() {
+		return
+	}
+	e.buf.WriteString("gsave\n")
+	fmt.Fprintf(e.buf, "%.*g %.*g translate\n", pr, x.Dots(DPI), pr, y.Dots(DPI))
+	fmt.Fprintf(e.buf, "%.*g %.*g scale\n", pr, w.Dots(DPI), pr, h.Dots(DPI))
+	fmt.Fprintf(e.buf, "%d %d 8 [%d 0 0 %d 0 %d]\n", b.Dx(), b.Dy(), b.Dx(), -b.Dy(), b.Dy())
+	e.buf.WriteString("currentfile /ASCIIHexDecode filter false 3 colorimage\n")
+	var rgb [3]byte
+	n := 0
+	for j := b.Min.Y; j < b.Max.Y; j++ {
+		for i := b.Min.X; i < b.Max.X; i++ {
+			r, g, bl, a := img.At(i, j).RGBA()
+			white := 0xffff - a
+			rgb[0], rgb[1], rgb[2] = byte((r+white)>>8), byte((g+white)>>8), byte((bl+white)>>8)
+			e.buf.WriteString(hex.EncodeToString(rgb[:]))
+			if n++; n%12 == 0 {
+				e.buf.WriteByte('\n')
+			}
+		}
+	}
+	e.buf.WriteString(">\ngrestore\n")
+}
+
+// FillString fills in text at the specified location
+// using the given font. Standard fonts are referred to
+// by name, and are reencoded to ISO Latin-1 for text
+// that is not ASCII. Other fonts with known TrueType
+// data, and standard fonts drawing text outside Latin-1,
+// are embedded as Type 42 fonts holding the glyphs
+// that are drawn.
 func (e *Canvas) FillString(fnt vg.Font, x, y vg.Length, str string) {
-	if e.cur().font != fnt.Name() || e.cur().fsize != fnt.Size {
-		e.cur().font = fnt.Name()
+	name := fnt.Name()
+	var f *font
+	switch {
+	case !fnt.IsStandard() || !isLatin1(str):
+		f = e.embed(fnt)
+	case !isASCII(str):
+		name = e.latin1(name)
+	}
+	if f != nil {
+		name = f.name
+	}
+	if e.cur().font != name || e.cur().fsize != fnt.Size {
+		e.cur().font = name
 		e.cur().fsize = fnt.Size
 		fmt.Fprintf(e.buf, "/%s findfont %.*g scalefont setfont\n",
-			fnt.Name(), pr, fnt.Size)
+			name, pr, fnt.Size)
 	}
 	fmt.Fprintf(e.buf, "%.*g %.*g moveto\n", pr, x.Dots(DPI), pr, y.Dots(DPI))
-	fmt.Fprintf(e.buf, "(%s) show\n", str)
+	if f != nil {
+		fmt.Fprintf(e.buf, "%s show\n", f.encode(str))
+		return
+	}
+	fmt.Fprintf(e.buf, "%s show\n", quote(str))
 }
 
 // WriteTo writes the canvas to an io.Writer.
 func (e *Canvas) WriteTo(w io.Writer) (int64, error) {
-	b := bufio.NewWriter(w)
-	n, err := e.buf.WriteTo(b)
+	wc := writerCounter{Writer: w}
+	b := bufio.NewWriter(&wc)
+	_, err := b.Write(e.buf.Bytes()[:e.prolog])
 	if err != nil {
-		return n, err
+		return wc.n, err
+	}
+	for _, name := range e.reencoded {
+		_, err = fmt.Fprintf(b, "/%s-Latin1 /%s findfont dup length dict begin\n"+
+			"{1 index /FID ne {def} {pop pop} ifelse} forall\n"+
+			"/Encoding ISOLatin1Encoding def\n"+
+			"currentdict end definefont pop\n", name, name)
+		if err != nil {
+			return wc.n, err
+		}
 	}
-	m, err := fmt.Fprintln(b, "showpage")
-	n += int64(m)
+	for _, f := range e.fonts {
+		err = f.writeTo(b)
+		if err != nil {
+			return wc.n, err
+		}
+	}
+	_, err = b.Write(e.buf.Bytes()[e.prolog:])
 	if err != nil {
-		return n, err
+		return wc.n, err
 	}
-	return n, b.Flush()
+	_, err = fmt.Fprintln(b, "showpage")
+	if err != nil {
+		return wc.n, err
+	}
+	err = b.Flush()
+	return wc.n, err
+}
+
+// writerCounter implements the io.Writer interface, and counts
+// the total number of bytes written.
+type writerCounter struct {
+	io.Writer
+	n int64
+}
+
+func (w *writerCounter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.n += int64(n)
+	return n, err
 }
@@ -0,0 +1,65 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vgeps
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/gonum/plot/vg"
+)
+
+func TestFillString(t *testing.T) {
+	font, err := vg.MakeFont("Helvetica", 12)
+	if err != nil {
+		t.Fatalf("failed to create font: %v", err)
+	}
+	c := New(100, 100)
+	c.FillString(font, 0, 0, `f(x) \ 1`)
+	c.FillString(font, 0, 0, "20 °C")
+	c.FillString(font, 0, 0, "x ≤ 1")
+	var buf bytes.Buffer
+	_, err = c.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("failed to write eps: %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		`(f\(x\) \\ 1) show`,
+		"/Helvetica-Latin1 /Helvetica findfont",
+		"/Helvetica-Latin1 findfont 12 scalefont setfont\n0 0 moveto\n(20 \\260C) show",
+		"/FontName /NimbusSanL-Regu def\n/FontType 42 def",
+		"/NimbusSanL-Regu findfont 12 scalefont setfont\n0 0 moveto\n(\\001\\002\\003\\002\\004) show",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("eps output does not contain %q", want)
+		}
+	}
+	if i, j := strings.Index(got, "%%EndResource"), strings.Index(got, "moveto"); i < 0 || i > j {
+		t.Errorf("font not defined before use")
+	}
+}
+
+func TestDrawImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{R: 0xff, A: 0xff})
+	img.Set(1, 0, color.NRGBA{B: 0xff, A: 0x80})
+	c := New(100, 100)
+	c.DrawImage(10, 20, 30, 40, img)
+	var buf bytes.Buffer
+	_, err := c.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("failed to write eps: %v", err)
+	}
+	want := "gsave\n10 20 translate\n30 40 scale\n2 1 8 [2 0 0 -1 0 1]\n" +
+		"currentfile /ASCIIHexDecode filter false 3 colorimage\nff00007f7fff>\ngrestore\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("eps output does not contain %q", want)
+	}
+}
@@ -16,9 +16,12 @@ import (
 	"image/jpeg"
 	"image/png"
 	"io"
+	"math"
 
 	"github.com/llgcode/draw2d"
 	"github.com/llgcode/draw2d/draw2dimg"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
 
 	"github.com/gonum/plot/vg"
 )
@@ -36,6 +39,11 @@ type Canvas struct {
 
 	// width is the current line width.
 	width vg.Length
+
+	// tr is the stack of transforms from canvas
+	// dots to the pixels of img, which mirrors the
+	// transforms of gc for drawing images.
+	tr []f64.Aff3
 }
 
 const (
@@ -100,6 +108,8 @@ func NewWith(o ...option) *Canvas {
 	}
 	draw.Draw(c.img, c.img.Bounds(), image.White, image.ZP, draw.Src)
 	c.color = []color.Color{color.Black}
+	h := float64(c.img.Bounds().Max.Y - c.img.Bounds().Min.Y)
+	c.tr = []f64.Aff3{{1, 0, 0, 0, -1, h}}
 	vg.Initialize(c)
 	return c
 }
@@ -190,26 +200,47 @@ func (c *Canvas) SetColor(clr color.Color) {
 
 func (c *Canvas) Rotate(t float64) {
 	c.gc.Rotate(t)
+	sin, cos := math.Sincos(t)
+	c.transform(f64.Aff3{cos, -sin, 0, sin, cos, 0})
 }
 
 func (c *Canvas) Translate(x, y vg.Length) {
 	c.gc.Translate(x.Dots(c.DPI()), y.Dots(c.DPI()))
+	c.transform(f64.Aff3{1, 0, x.Dots(c.DPI()), 0, 1, y.Dots(c.DPI())})
 }
 
 func (c *Canvas) Scale(x, y float64) {
 	c.gc.Scale(x, y)
+	c.transform(f64.Aff3{x, 0, 0, 0, y, 0})
 }
 
 func (c *Canvas) Push() {
 	c.color = append(c.color, c.color[len(c.color)-1])
+	c.tr = append(c.tr, c.tr[len(c.tr)-1])
 	c.gc.Save()
 }
 
 func (c *Canvas) Pop() {
 	c.color = c.color[:len(c.color)-1]
+	c.tr = c.tr[:len(c.tr)-1]
 	c.gc.Restore()
 }
 
+// transform applies the transform t before
+// the current transform of the canvas.
+func (c *Canvas) transform(t f64.Aff3) {
+	m := &c.tr[len(c.tr)-1]
+	*m = mul(*m, t)
+}
+
+// mul returns the transform that applies n then m.
+func mul(m, n f64.Aff3) f64.Aff3 {
+	return f64.Aff3{
+		m[0]*n[0] + m[1]*n[3], m[0]*n[1] + m[1]*n[4], m[0]*n[2] + m[1]*n[5] + m[2],
+		m[3]*n[0] + m[4]*n[3], m[3]*n[1] + m[4]*n[4], m[3]*n[2] + m[4]*n[5] + m[5],
+	}
+}
+
 func (c *Canvas) Stroke(p vg.Path) {
 	if c.width <= 0 {
 		return
@@ -247,6 +278,28 @@ func (c *Canvas) outline(p vg.Path) {
 	}
 }
 
+// DrawImage draws the image scaled to fill the rectangle
+// of width w and height h with its lower left corner at x, y.
+// The image is drawn directly to the pixels of the canvas
+// image, using nearest neighbor sampling. Canvases created
+// with UseImageWithContext must use a graphic context that
+// places the origin at the bottom left of the image, as
+// for the other options.
+func (c *Canvas) DrawImage(x, y, w, h vg.Length, img image.Image) {
+	b := img.Bounds()
+	if b.Empty() {
+		return
+	}
+	dpi := c.DPI()
+	sx := w.Dots(dpi) / float64(b.Dx())
+	sy := h.Dots(dpi) / float64(b.Dy())
+	src := f64.Aff3{
+		sx, 0, x.Dots(dpi) - float64(b.Min.X)*sx,
+		0, -sy, (y + h).Dots(dpi) + float64(b.Min.Y)*sy,
+	}
+	xdraw.NearestNeighbor.Transform(c.img, mul(c.tr[len(c.tr)-1], src), img, b, xdraw.Over, nil)
+}
+
 func (c *Canvas) DPI() float64 {
 	return float64(c.gc.GetDPI())
 }
@@ -257,7 +310,7 @@ func (c *Canvas) FillString(font vg.Font, x, y vg.Length, str string) {
 
 	data, ok := fontMap[font.Name()]
 	if !ok {
-		panic(fmt.Sprintf("Font name %s is unknown", font.Name()))
+		data = draw2d.FontData{Name: font.Name()}
 	}
 	if !registeredFont[font.Name()] {
 		draw2d.RegisterFont(data, font.Font())
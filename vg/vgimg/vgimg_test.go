@@ -2,6 +2,8 @@ package vgimg_test
 
 import (
 	"bytes"
+	"image"
+	"image/color"
 	"io/ioutil"
 	"log"
 	"os"
@@ -48,3 +50,37 @@ func TestIssue179(t *testing.T) {
 		t.Error("Image mismatch")
 	}
 }
+
+func TestDrawImage(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	red := color.RGBA{R: 0xff, A: 0xff}
+	green := color.RGBA{G: 0xff, A: 0xff}
+	blue := color.RGBA{B: 0xff, A: 0xff}
+	src.Set(0, 0, red)
+	src.Set(1, 0, green)
+	src.Set(0, 1, blue)
+
+	dst := image.NewRGBA(image.Rect(0, 0, 192, 192))
+	c := vgimg.NewWith(vgimg.UseImage(dst), vgimg.UseDPI(96))
+	c.DrawImage(0, 0, vg.Inch, vg.Inch, src)
+	c.Push()
+	c.Translate(vg.Inch, vg.Inch)
+	c.DrawImage(0, 0, vg.Inch, vg.Inch, src)
+	c.Pop()
+
+	for _, test := range []struct {
+		x, y int
+		want color.Color
+	}{
+		{x: 24, y: 120, want: red},
+		{x: 72, y: 120, want: green},
+		{x: 24, y: 168, want: blue},
+		{x: 120, y: 24, want: red},
+		{x: 168, y: 72, want: color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{x: 120, y: 120, want: color.RGBA{0xff, 0xff, 0xff, 0xff}},
+	} {
+		if got := dst.At(test.x, test.y); got != test.want {
+			t.Errorf("unexpected color at (%d, %d): got:%v want:%v", test.x, test.y, got, test.want)
+		}
+	}
+}
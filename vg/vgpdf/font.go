@@ -0,0 +1,322 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vgpdf
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	xfont "golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/gonum/plot/vg"
+)
+
+// font is a TrueType font that is embedded in the
+// output as a composite font, with character codes
+// that are the glyph indices of the font.
+type font struct {
+	vg.Font
+
+	// name is the name of the font resource,
+	// which is the font name without the tag
+	// of its subset.
+	name string
+
+	// glyphs maps the indices of the glyphs
+	// drawn in the font to the first rune
+	// drawn with them.
+	glyphs map[truetype.Index]rune
+}
+
+// embed returns the embedded font for fnt, adding it
+// to the canvas if fnt has not been used before. Fonts
+// with unknown TrueType data are not embedded and nil
+// is returned for them.
+func (c *Canvas) embed(fnt vg.Font) *font {
+	if fnt.Data() == nil {
+		return nil
+	}
+	for _, f := range c.fonts {
+		if f.Name() == fnt.Name() {
+			return f
+		}
+	}
+	name := fnt.Name()
+	if std, ok := vg.FontMap[name]; ok {
+		// Embedded standard fonts are named after
+		// their font file so that they are not
+		// mistaken for the standard PDF fonts.
+		name = std
+	}
+	f := &font{
+		Font:   fnt,
+		name:   pdfName(name),
+		glyphs: make(map[truetype.Index]rune),
+	}
+	c.fonts = append(c.fonts, f)
+	return f
+}
+
+// encode returns str as the operand of a TJ operator,
+// an array of the glyph indices of the runes of str,
+// with the kerning between glyphs, adding the glyphs
+// to those drawn in the font.
+func (f *font) encode(str string) string {
+	ttf := f.Font.Font()
+	units := fixed.Int26_6(ttf.FUnitsPerEm())
+	var buf bytes.Buffer
+	buf.WriteString("[<")
+	prev, hasPrev := truetype.Index(0), false
+	for _, r := range str {
+		index := ttf.Index(r)
+		if _, ok := f.glyphs[index]; !ok {
+			f.glyphs[index] = r
+		}
+		if hasPrev {
+			if k := ttf.Kern(units, prev, index); k != 0 {
+				// Positive TJ adjustments move the
+				// next glyph left, in thousandths
+				// of the font size.
+				fmt.Fprintf(&buf, "> %s <", num(-1000*float64(k)/float64(units)))
+			}
+		}
+		fmt.Fprintf(&buf, "%04x", index)
+		prev, hasPrev = index, true
+	}
+	buf.WriteString(">]")
+	return buf.String()
+}
+
+// writeTo writes the font, holding the glyphs that are
+// drawn in it, and returns the number of its object.
+func (f *font) writeTo(w *writer) (int, error) {
+	indices := make([]int, 0, len(f.glyphs))
+	runes := make([]rune, 0, len(f.glyphs))
+	for index, r := range f.glyphs {
+		indices = append(indices, int(index))
+		runes = append(runes, r)
+	}
+	sort.Ints(indices)
+	data, err := f.Subset(string(runes))
+	if err != nil {
+		return 0, err
+	}
+
+	ttf := f.Font.Font()
+	units := ttf.FUnitsPerEm()
+	em := func(v fixed.Int26_6) string {
+		return num(1000 * float64(v) / float64(units))
+	}
+	b := ttf.Bounds(fixed.Int26_6(units))
+
+	var widths bytes.Buffer
+	for _, index := range indices {
+		adv := ttf.HMetric(fixed.Int26_6(units), truetype.Index(index)).AdvanceWidth
+		fmt.Fprintf(&widths, " %d [%s]", index, em(adv))
+	}
+
+	name := subsetTag(indices) + "+" + f.name
+	type0, cid, desc, file, cmap := w.alloc(), w.alloc(), w.alloc(), w.alloc(), w.alloc()
+	w.object(type0, "<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H"+
+		" /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>", name, cid, cmap)
+	w.object(cid, "<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s"+
+		" /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >>"+
+		" /FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s ] >>", name, desc, widths.String())
+	w.object(desc, "<< /Type /FontDescriptor /FontName /%s /Flags 4"+
+		" /FontBBox [%s %s %s %s] /ItalicAngle 0 /Ascent %[5]s /Descent %[3]s"+
+		" /CapHeight %[5]s /StemV 80 /FontFile2 %[6]d 0 R >>",
+		name, em(b.Min.X), em(b.Min.Y), em(b.Max.X), em(b.Max.Y), file)
+	w.stream(file, fmt.Sprintf("/Length1 %d", len(data)), data)
+	w.stream(cmap, "", f.toUnicode(indices))
+	return type0, nil
+}
+
+// toUnicode returns the CMap mapping the glyphs
+// with the given indices to the runes drawn with
+// them.
+func (f *font) toUnicode(indices []int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("/CIDInit /ProcSet findresource begin\n" +
+		"12 dict begin\n" +
+		"begincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n" +
+		"/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	// At most 100 mappings may be given
+	// in each section of the CMap.
+	for i := 0; i < len(indices); i += 100 {
+		section := indices[i:min(i+100, len(indices))]
+		fmt.Fprintf(&buf, "%d beginbfchar\n", len(section))
+		for _, index := range section {
+			fmt.Fprintf(&buf, "<%04x> <", index)
+			for _, u := range utf16.Encode([]rune{f.glyphs[truetype.Index(index)]}) {
+				fmt.Fprintf(&buf, "%04x", u)
+			}
+			buf.WriteString(">\n")
+		}
+		buf.WriteString("endbfchar\n")
+	}
+	buf.WriteString("endcmap\n" +
+		"CMapName currentdict /CMap defineresource pop\n" +
+		"end\n" +
+		"end\n")
+	return buf.Bytes()
+}
+
+// subsetTag returns the tag naming the subset of
+// a font holding the glyphs with the given indices,
+// six upper case letters derived from the indices.
+func subsetTag(indices []int) string {
+	h := crc32.NewIEEE()
+	for _, index := range indices {
+		fmt.Fprintf(h, "%d,", index)
+	}
+	sum := h.Sum32()
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + byte(sum%26)
+		sum /= 26
+	}
+	return string(tag)
+}
+
+// pdfName returns name with the characters that
+// may not appear in a PDF name replaced.
+func pdfName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || strings.ContainsRune("()<>[]{}/%#", r) {
+			return '-'
+		}
+		return r
+	}, name)
+}
+
+// fillOutlines fills the outlines of the glyphs of str,
+// drawn in fnt with the start of the baseline at (x, y).
+// Fonts whose TrueType data is not known can not be
+// embedded, so text in them is drawn as the outlines
+// of its glyphs.
+func (c *Canvas) fillOutlines(fnt vg.Font, x, y vg.Length, str string) {
+	ttf := fnt.Font()
+	units := fixed.Int26_6(ttf.FUnitsPerEm())
+	// With a scale of the units per em, glyph
+	// coordinates are in font units.
+	scale := fnt.Size / vg.Length(units)
+
+	p := new(path)
+	var g truetype.GlyphBuf
+	prev, hasPrev := truetype.Index(0), false
+	for _, r := range str {
+		index := ttf.Index(r)
+		if hasPrev {
+			x += scale * vg.Length(ttf.Kern(units, prev, index))
+		}
+		if err := g.Load(ttf, units, index, xfont.HintingNone); err == nil {
+			start := 0
+			for _, end := range g.Ends {
+				contour(p, g.Points[start:end], x, y, scale)
+				start = end
+			}
+		}
+		x += scale * vg.Length(ttf.HMetric(units, index).AdvanceWidth)
+		prev, hasPrev = index, true
+	}
+	if p.Len() > 0 {
+		c.buf.Write(p.Bytes())
+		c.buf.WriteString("f\n")
+	}
+}
+
+// contour adds the closed glyph contour through the
+// points to p. The quadratic Bézier curves of the
+// contour are converted to cubic Bézier curves.
+func contour(p *path, pts []truetype.Point, x, y, scale vg.Length) {
+	if len(pts) == 0 {
+		return
+	}
+	pt := func(q truetype.Point) point {
+		return pdfPoint(x+scale*vg.Length(q.X), y+scale*vg.Length(q.Y))
+	}
+	onCurve := func(q truetype.Point) bool {
+		return q.Flags&0x01 != 0
+	}
+
+	// The contour starts at an on-curve point, or
+	// between two off-curve points if it has none
+	// at either end.
+	var start point
+	last := len(pts) - 1
+	switch {
+	case onCurve(pts[0]):
+		start, pts = pt(pts[0]), pts[1:]
+	case onCurve(pts[last]):
+		start, pts = pt(pts[last]), pts[:last]
+	default:
+		start = mid(pt(pts[0]), pt(pts[last]))
+	}
+
+	p.Move(start)
+	cur := start
+	var ctrl point
+	hasCtrl := false
+	for _, q := range pts {
+		v := pt(q)
+		switch {
+		case onCurve(q) && hasCtrl:
+			quad(p, cur, ctrl, v)
+			hasCtrl = false
+		case onCurve(q):
+			p.Line(v)
+		case hasCtrl:
+			m := mid(ctrl, v)
+			quad(p, cur, ctrl, m)
+			cur, ctrl = m, v
+			continue
+		default:
+			ctrl, hasCtrl = v, true
+			continue
+		}
+		cur = v
+	}
+	if hasCtrl {
+		quad(p, cur, ctrl, start)
+	}
+	p.Close()
+}
+
+// quad adds the quadratic Bézier curve from p0 to p1
+// with the control point c to p as a cubic Bézier curve.
+func quad(p *path, p0, c, p1 point) {
+	c1 := point{X: p0.X + (c.X-p0.X)*2/3, Y: p0.Y + (c.Y-p0.Y)*2/3}
+	c2 := point{X: p1.X + (c.X-p1.X)*2/3, Y: p1.Y + (c.Y-p1.Y)*2/3}
+	p.Curve(c1, c2, p1)
+}
+
+func mid(a, b point) point {
+	return point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
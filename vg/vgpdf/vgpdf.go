@@ -3,17 +3,31 @@
 // license that can be found in the LICENSE file.
 
 // Package vgpdf implements the vg.Canvas interface
-// using gopdf (bitbucket.org/zombiezen/gopdf/pdf).
+// by writing single page PDF files.
+//
+// ASCII text in the standard fonts is drawn in the
+// standard PDF fonts, which are referred to by name.
+// Other text is drawn in a subset of its TrueType font
+// that is embedded in the PDF file, with a map from
+// its glyphs to Unicode so that the text can be
+// selected and searched. Only text in fonts whose
+// TrueType data is not known, such as fonts added
+// with vg.AddFont, is drawn as the filled outlines
+// of its glyphs.
 package vgpdf
 
 import (
 	"bufio"
+	"bytes"
+	"compress/zlib"
 	"fmt"
+	"image"
 	"image/color"
 	"io"
 	"math"
+	"strconv"
+	"strings"
 
-	"bitbucket.org/zombiezen/gopdf/pdf"
 	"github.com/gonum/plot/vg"
 )
 
@@ -23,21 +37,30 @@ const DPI = 72
 // Canvas implements the vg.Canvas interface,
 // drawing to a PDF.
 type Canvas struct {
-	doc         *pdf.Document
-	w, h        vg.Length
-	page        *pdf.Canvas
+	w, h vg.Length
+
+	// buf holds the content stream of the page.
+	buf bytes.Buffer
+
+	// standard holds the names of the standard
+	// fonts that are used, and fonts holds the
+	// fonts that are embedded.
+	standard []string
+	fonts    []*font
+
+	// images holds the images that are drawn.
+	images []image.Image
+
 	lineVisible bool
 }
 
 // New creates a new PDF Canvas.
 func New(w, h vg.Length) *Canvas {
 	c := &Canvas{
-		doc:         pdf.New(),
 		w:           w,
 		h:           h,
 		lineVisible: true,
 	}
-	c.page = c.doc.NewPage(unit(w), unit(h))
 	vg.Initialize(c)
 	return c
 }
@@ -47,65 +70,136 @@ func (c *Canvas) Size() (w, h vg.Length) {
 }
 
 func (c *Canvas) SetLineWidth(w vg.Length) {
-	c.page.SetLineWidth(unit(w))
+	fmt.Fprintf(&c.buf, "%s w\n", num(w.Points()))
 	c.lineVisible = w > 0
 }
 
 func (c *Canvas) SetLineDash(dashes []vg.Length, offs vg.Length) {
-	ds := make([]pdf.Unit, len(dashes))
+	ds := make([]string, len(dashes))
 	for i, d := range dashes {
-		ds[i] = unit(d)
+		ds[i] = num(d.Points())
 	}
-	c.page.SetLineDash(unit(offs), ds)
+	fmt.Fprintf(&c.buf, "[%s] %s d\n", strings.Join(ds, " "), num(offs.Points()))
 }
 
 func (c *Canvas) SetColor(clr color.Color) {
-	c.page.SetStrokeColor(pdfColor(clr))
-	c.page.SetColor(pdfColor(clr))
+	r, g, b := pdfColor(clr)
+	fmt.Fprintf(&c.buf, "%s %s %s RG\n%[1]s %[2]s %[3]s rg\n", num(r), num(g), num(b))
 }
 
 func (c *Canvas) Rotate(r float64) {
-	c.page.Rotate(float32(r))
+	s, cos := math.Sin(r), math.Cos(r)
+	fmt.Fprintf(&c.buf, "%s %s %s %[1]s 0 0 cm\n", num(cos), num(s), num(-s))
 }
 
 func (c *Canvas) Translate(x vg.Length, y vg.Length) {
-	c.page.Translate(unit(x), unit(y))
+	fmt.Fprintf(&c.buf, "1 0 0 1 %s %s cm\n", num(x.Points()), num(y.Points()))
 }
 
 func (c *Canvas) Scale(x float64, y float64) {
-	c.page.Scale(float32(x), float32(y))
+	fmt.Fprintf(&c.buf, "%s 0 0 %s 0 0 cm\n", num(x), num(y))
 }
 
 func (c *Canvas) Push() {
-	c.page.Push()
+	c.buf.WriteString("q\n")
 }
 
 func (c *Canvas) Pop() {
-	c.page.Pop()
+	c.buf.WriteString("Q\n")
 }
 
 func (c *Canvas) Stroke(p vg.Path) {
-	if c.lineVisible {
-		c.page.Stroke(pdfPath(c, p))
+	if c.lineVisible && len(p) > 0 {
+		c.buf.Write(pdfPath(c, p).Bytes())
+		c.buf.WriteString("S\n")
 	}
 }
 
 func (c *Canvas) Fill(p vg.Path) {
-	c.page.Fill(pdfPath(c, p))
+	if len(p) > 0 {
+		c.buf.Write(pdfPath(c, p).Bytes())
+		c.buf.WriteString("f\n")
+	}
 }
 
+// FillString fills in text at the specified location
+// using the given font. ASCII text in the standard fonts
+// is drawn in the standard PDF fonts. Other text is drawn
+// in an embedded subset of the font, or, if the TrueType
+// data of the font is not known, as glyph outlines.
 func (c *Canvas) FillString(fnt vg.Font, x, y vg.Length, str string) {
-	t := new(pdf.Text)
-	t.SetFont(fnt.Name(), unit(fnt.Size))
-	t.NextLineOffset(unit(x), unit(y))
-	t.Text(str)
-	c.page.DrawText(t)
+	var name, text string
+	switch {
+	case fnt.IsStandard() && isASCII(str):
+		name, text = c.standardFont(fnt.Name()), quote(str)+" Tj"
+	default:
+		f := c.embed(fnt)
+		if f == nil {
+			c.fillOutlines(fnt, x, y, str)
+			return
+		}
+		name, text = f.name, f.encode(str)+" TJ"
+	}
+	fmt.Fprintf(&c.buf, "BT\n/%s %s Tf\n%s %s Td\n%s\nET\n",
+		name, num(fnt.Size.Points()), num(x.Points()), num(y.Points()), text)
+}
+
+// standardFont returns the name of the standard font,
+// adding it to the fonts that are used by the canvas
+// if it has not been used before.
+func (c *Canvas) standardFont(name string) string {
+	for _, n := range c.standard {
+		if n == name {
+			return name
+		}
+	}
+	c.standard = append(c.standard, name)
+	return name
+}
+
+// DrawImage draws the image scaled to fill the rectangle
+// of width w and height h with its lower left corner at x, y.
+// The image is embedded in the PDF as an image XObject.
+func (c *Canvas) DrawImage(x, y, w, h vg.Length, img image.Image) {
+	if img.Bounds().Empty() {
+		return
+	}
+	c.images = append(c.images, img)
+	fmt.Fprintf(&c.buf, "q\n%s 0 0 %s %s %s cm\n/Im%d Do\nQ\n",
+		num(w.Points()), num(h.Points()), num(x.Points()), num(y.Points()), len(c.images))
 }
 
-// pdfPath returns a pdf.Path from a vg.Path.
-func pdfPath(c *Canvas, path vg.Path) *pdf.Path {
-	p := new(pdf.Path)
-	for _, comp := range path {
+// point is a point in PDF user space.
+type point struct {
+	X, Y float64
+}
+
+// path is a PDF path under construction, held as the
+// operators of a content stream that construct it.
+type path struct {
+	bytes.Buffer
+}
+
+func (p *path) Move(pt point) {
+	fmt.Fprintf(p, "%s %s m\n", num(pt.X), num(pt.Y))
+}
+
+func (p *path) Line(pt point) {
+	fmt.Fprintf(p, "%s %s l\n", num(pt.X), num(pt.Y))
+}
+
+func (p *path) Curve(c1, c2, pt point) {
+	fmt.Fprintf(p, "%s %s %s %s %s %s c\n", num(c1.X), num(c1.Y), num(c2.X), num(c2.Y), num(pt.X), num(pt.Y))
+}
+
+func (p *path) Close() {
+	p.WriteString("h\n")
+}
+
+// pdfPath returns a path from a vg.Path.
+func pdfPath(c *Canvas, vp vg.Path) *path {
+	p := new(path)
+	for _, comp := range vp {
 		switch comp.Type {
 		case vg.MoveComp:
 			p.Move(pdfPoint(comp.X, comp.Y))
@@ -127,7 +221,7 @@ func pdfPath(c *Canvas, path vg.Path) *pdf.Path {
 //
 // This is from:
 // 	http://hansmuller-flex.blogspot.com/2011/04/approximating-circular-arc-with-cubic.html
-func arc(p *pdf.Path, comp vg.PathComp) {
+func arc(p *path, comp vg.PathComp) {
 	x0 := comp.X + comp.Radius*vg.Length(math.Cos(comp.Start))
 	y0 := comp.Y + comp.Radius*vg.Length(math.Sin(comp.Start))
 	p.Line(pdfPoint(x0, y0))
@@ -155,7 +249,7 @@ func arc(p *pdf.Path, comp vg.PathComp) {
 
 // Approximate a circular arc of fewer than π/2
 // radians with cubic Bézier curve.
-func partialArc(p *pdf.Path, x, y, r vg.Length, a1, a2 float64) {
+func partialArc(p *path, x, y, r vg.Length, a1, a2 float64) {
 	a := (a2 - a1) / 2
 	x4 := r * vg.Length(math.Cos(a))
 	y4 := r * vg.Length(math.Sin(a))
@@ -182,23 +276,44 @@ func partialArc(p *pdf.Path, x, y, r vg.Length, a1, a2 float64) {
 	p.Curve(pdfPoint(x2r, y2r), pdfPoint(x3r, y3r), pdfPoint(x4, y4))
 }
 
-func pdfPoint(x, y vg.Length) pdf.Point {
-	return pdf.Point{X: unit(x), Y: unit(y)}
+func pdfPoint(x, y vg.Length) point {
+	return point{X: x.Points(), Y: y.Points()}
 }
 
-func pdfColor(clr color.Color) (float32, float32, float32) {
+func pdfColor(clr color.Color) (float64, float64, float64) {
 	if clr == nil {
 		clr = color.Black
 	}
 	r, g, b, _ := clr.RGBA()
-	return float32(r) / math.MaxUint16,
-		float32(g) / math.MaxUint16,
-		float32(b) / math.MaxUint16
+	return float64(r) / math.MaxUint16,
+		float64(g) / math.MaxUint16,
+		float64(b) / math.MaxUint16
 }
 
-// unit returns a pdf.Unit, converted from a vg.Length.
-func unit(l vg.Length) pdf.Unit {
-	return pdf.Unit(l.Points()) * pdf.Pt
+// num returns v formatted as a PDF number, which
+// may not be written in exponential notation.
+func num(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 5, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// quote returns str as a PDF string.
+func quote(str string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('(')
+	for _, r := range str {
+		if r == '(' || r == ')' || r == '\\' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(r)
+	}
+	buf.WriteByte(')')
+	return buf.String()
 }
 
 // WriterCounter implements the io.Writer interface, and counts
@@ -214,16 +329,138 @@ func (w *writerCounter) Write(p []byte) (int, error) {
 	return n, err
 }
 
+// writer writes the objects of a PDF file, keeping
+// their offsets for the cross-reference table. The
+// first error is kept and stops further writing.
+type writer struct {
+	wc *writerCounter
+	b  *bufio.Writer
+
+	// offsets holds the offset of each
+	// object, numbered from one.
+	offsets []int64
+
+	err error
+}
+
+// alloc returns the number of a new object.
+func (w *writer) alloc() int {
+	w.offsets = append(w.offsets, 0)
+	return len(w.offsets)
+}
+
+func (w *writer) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.b, format, args...)
+}
+
+// offset returns the number of bytes written.
+func (w *writer) offset() int64 {
+	return w.wc.n + int64(w.b.Buffered())
+}
+
+// object writes the object numbered n, the dictionary
+// or other value given by the format and arguments.
+func (w *writer) object(n int, format string, args ...interface{}) {
+	w.offsets[n-1] = w.offset()
+	w.printf("%d 0 obj\n", n)
+	w.printf(format, args...)
+	w.printf("\nendobj\n")
+}
+
+// stream writes the object numbered n, a stream of data
+// compressed with the Flate filter. The entries of dict
+// are added to the dictionary of the stream.
+func (w *writer) stream(n int, dict string, data []byte) {
+	var buf bytes.Buffer
+	z := zlib.NewWriter(&buf)
+	z.Write(data)
+	z.Close()
+	if dict != "" {
+		dict += " "
+	}
+	w.offsets[n-1] = w.offset()
+	w.printf("%d 0 obj\n<< %s/Length %d /Filter /FlateDecode >>\nstream\n", n, dict, buf.Len())
+	if w.err == nil {
+		_, w.err = buf.WriteTo(w.b)
+	}
+	w.printf("\nendstream\nendobj\n")
+}
+
+// image writes img as an image XObject, with its alpha
+// channel as a soft mask if it is not opaque, and returns
+// the number of the object.
+func (w *writer) image(img image.Image) int {
+	b := img.Bounds()
+	rgb := make([]byte, 0, 3*b.Dx()*b.Dy())
+	alpha := make([]byte, 0, b.Dx()*b.Dy())
+	opaque := true
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			rgb = append(rgb, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			opaque = opaque && c.A == 0xff
+		}
+	}
+	n := w.alloc()
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /BitsPerComponent 8", b.Dx(), b.Dy())
+	if opaque {
+		w.stream(n, dict+" /ColorSpace /DeviceRGB", rgb)
+		return n
+	}
+	mask := w.alloc()
+	w.stream(n, fmt.Sprintf("%s /ColorSpace /DeviceRGB /SMask %d 0 R", dict, mask), rgb)
+	w.stream(mask, dict+" /ColorSpace /DeviceGray", alpha)
+	return n
+}
+
 // WriteTo writes the Canvas to an io.Writer.
-// After calling Write, the canvas is closed
-// and may no longer be used for drawing.
+// Drawing to the canvas may continue after
+// it is written.
 func (c *Canvas) WriteTo(w io.Writer) (int64, error) {
-	c.page.Close()
 	wc := writerCounter{Writer: w}
-	b := bufio.NewWriter(&wc)
-	if err := c.doc.Encode(b); err != nil {
-		return wc.n, err
+	pw := &writer{wc: &wc, b: bufio.NewWriter(&wc)}
+	catalog, pages, page, content := pw.alloc(), pw.alloc(), pw.alloc(), pw.alloc()
+
+	pw.printf("%%PDF-1.7\n")
+	pw.object(catalog, "<< /Type /Catalog /Pages %d 0 R >>", pages)
+	pw.object(pages, "<< /Type /Pages /Kids [%d 0 R] /Count 1 >>", page)
+	pw.stream(content, "", c.buf.Bytes())
+
+	var fonts, images bytes.Buffer
+	for _, name := range c.standard {
+		n := pw.alloc()
+		pw.object(n, "<< /Type /Font /Subtype /Type1 /BaseFont /%s >>", name)
+		fmt.Fprintf(&fonts, " /%s %d 0 R", name, n)
+	}
+	for _, f := range c.fonts {
+		n, err := f.writeTo(pw)
+		if err != nil {
+			return wc.n, err
+		}
+		fmt.Fprintf(&fonts, " /%s %d 0 R", f.name, n)
+	}
+	for i, img := range c.images {
+		fmt.Fprintf(&images, " /Im%d %d 0 R", i+1, pw.image(img))
+	}
+	w2, h2 := num(c.w.Points()), num(c.h.Points())
+	pw.object(page, "<< /Type /Page /Parent %d 0 R"+
+		" /Resources << /ProcSet [/PDF /Text /ImageC] /Font <<%s >> /XObject <<%s >> >>"+
+		" /MediaBox [0 0 %s %s] /CropBox [0 0 %[4]s %[5]s] /Contents %[6]d 0 R >>",
+		pages, fonts.String(), images.String(), w2, h2, content)
+
+	xref := pw.offset()
+	pw.printf("xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets)+1)
+	for _, off := range pw.offsets {
+		pw.printf("%010d 00000 n \n", off)
+	}
+	pw.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pw.offsets)+1, catalog, xref)
+	if pw.err != nil {
+		return wc.n, pw.err
 	}
-	err := b.Flush()
+	err := pw.b.Flush()
 	return wc.n, err
 }
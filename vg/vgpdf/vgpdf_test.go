@@ -0,0 +1,234 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vgpdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/gonum/plot/vg"
+)
+
+// objects returns the objects of the PDF file b, found
+// through its cross-reference table, keyed by number.
+func objects(t *testing.T, b []byte) map[int]string {
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(b)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	lines := strings.Split(string(b[xref:]), "\n")
+	if lines[0] != "xref" {
+		t.Fatalf("startxref does not point to xref: %q", lines[0])
+	}
+	var n int
+	fmt.Sscanf(lines[1], "0 %d", &n)
+	objs := make(map[int]string)
+	for i := 1; i < n; i++ {
+		off, _ := strconv.Atoi(lines[2+i][:10])
+		obj := string(b[off:])
+		prefix := fmt.Sprintf("%d 0 obj\n", i)
+		if !strings.HasPrefix(obj, prefix) {
+			t.Fatalf("xref entry of object %d does not point to it", i)
+		}
+		objs[i] = obj[len(prefix):strings.Index(obj, "\nendobj\n")]
+	}
+	return objs
+}
+
+// stream returns the decompressed data of the stream object obj.
+func stream(t *testing.T, obj string) []byte {
+	i := strings.Index(obj, "\nstream\n")
+	if i < 0 {
+		t.Fatalf("object is not a stream: %q", obj)
+	}
+	var n int
+	fmt.Sscanf(obj[strings.Index(obj, "/Length "):], "/Length %d", &n)
+	r, err := zlib.NewReader(strings.NewReader(obj[i+len("\nstream\n"):][:n]))
+	if err != nil {
+		t.Fatalf("failed to decompress stream: %v", err)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to decompress stream: %v", err)
+	}
+	return b
+}
+
+// ref returns the stream object referred to by the
+// given key of the dictionary of obj.
+func ref(t *testing.T, objs map[int]string, obj, key string) string {
+	var n int
+	i := strings.Index(obj, key)
+	if i < 0 {
+		t.Fatalf("object has no %s: %q", key, obj)
+	}
+	fmt.Sscanf(obj[i+len(key):], "%d 0 R", &n)
+	return objs[n]
+}
+
+func write(t *testing.T, c *Canvas) map[int]string {
+	var buf bytes.Buffer
+	_, err := c.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("failed to write pdf: %v", err)
+	}
+	return objects(t, buf.Bytes())
+}
+
+func TestFillString(t *testing.T) {
+	font, err := vg.MakeFont("Helvetica", 12)
+	if err != nil {
+		t.Fatalf("failed to create font: %v", err)
+	}
+	ttf := font.Font()
+	c := New(100, 100)
+	c.FillString(font, 0, 0, `f(x) \ 1`)
+	c.FillString(font, 10, 20, "x ≤ 1")
+	objs := write(t, c)
+
+	var page, type0 string
+	for _, obj := range objs {
+		switch {
+		case strings.HasPrefix(obj, "<< /Type /Page "):
+			page = obj
+		case strings.HasPrefix(obj, "<< /Type /Font /Subtype /Type0 "):
+			type0 = obj
+		}
+	}
+	content := string(stream(t, ref(t, objs, page, "/Contents")))
+	x, le := ttf.Index('x'), ttf.Index('≤')
+	for _, want := range []string{
+		"BT\n/Helvetica 12 Tf\n0 0 Td\n(f\\(x\\) \\\\ 1) Tj\nET\n",
+		fmt.Sprintf("BT\n/NimbusSanL-Regu 12 Tf\n10 20 Td\n[<%04x", x),
+		fmt.Sprintf("%04x", le),
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content does not contain %q:\n%s", want, content)
+		}
+	}
+	if !strings.Contains(page, "/Helvetica ") || !strings.Contains(page, "/NimbusSanL-Regu ") {
+		t.Errorf("fonts missing from page resources: %q", page)
+	}
+
+	if !regexp.MustCompile(`/BaseFont /[A-Z]{6}\+NimbusSanL-Regu /Encoding /Identity-H`).MatchString(type0) {
+		t.Errorf("unexpected composite font: %q", type0)
+	}
+	cid := ref(t, objs, type0, "/DescendantFonts [")
+	units := ttf.FUnitsPerEm()
+	adv := ttf.HMetric(fixed.Int26_6(units), le).AdvanceWidth
+	w := fmt.Sprintf(" %d [%s]", le, num(1000*float64(adv)/float64(units)))
+	if !strings.Contains(cid, "/CIDToGIDMap /Identity") || !strings.Contains(cid, w) {
+		t.Errorf("CID font does not contain %q: %q", w, cid)
+	}
+	desc := ref(t, objs, cid, "/FontDescriptor")
+	data := stream(t, ref(t, objs, desc, "/FontFile2"))
+	sub, err := truetype.Parse(data)
+	if err != nil {
+		t.Fatalf("failed to parse embedded font: %v", err)
+	}
+	if sub.Index('≤') != le {
+		t.Errorf("unexpected glyph index in embedded font: got:%d want:%d", sub.Index('≤'), le)
+	}
+	cmap := string(stream(t, ref(t, objs, type0, "/ToUnicode")))
+	for _, want := range []string{
+		fmt.Sprintf("<%04x> <2264>\n", le),
+		fmt.Sprintf("<%04x> <0078>\n", x),
+		"4 beginbfchar\n",
+	} {
+		if !strings.Contains(cmap, want) {
+			t.Errorf("ToUnicode CMap does not contain %q:\n%s", want, cmap)
+		}
+	}
+}
+
+func TestFillStringOutlines(t *testing.T) {
+	font, err := vg.MakeFont("Helvetica", 12)
+	if err != nil {
+		t.Fatalf("failed to create font: %v", err)
+	}
+	vg.AddFont("outlined", font.Font())
+	font, err = vg.MakeFont("outlined", 12)
+	if err != nil {
+		t.Fatalf("failed to create font: %v", err)
+	}
+	c := New(100, 100)
+	c.FillString(font, 0, 0, "x")
+	objs := write(t, c)
+	for _, obj := range objs {
+		if strings.Contains(obj, "/Type /Font") {
+			t.Errorf("unexpected font for text in font without data: %q", obj)
+		}
+		if !strings.HasPrefix(obj, "<< /Type /Page ") {
+			continue
+		}
+		content := string(stream(t, ref(t, objs, obj, "/Contents")))
+		if strings.Contains(content, "BT\n") || !strings.HasSuffix(content, "h\nf\n") {
+			t.Errorf("text in font without data not drawn as outlines:\n%s", content)
+		}
+	}
+}
+
+func TestDrawImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{R: 0xff, A: 0xff})
+	img.Set(1, 0, color.NRGBA{B: 0xff, A: 0x80})
+	c := New(100, 100)
+	c.DrawImage(10, 20, 30, 40, img)
+	objs := write(t, c)
+
+	var page, im string
+	for _, obj := range objs {
+		switch {
+		case strings.HasPrefix(obj, "<< /Type /Page "):
+			page = obj
+		case strings.Contains(obj, "/ColorSpace /DeviceRGB"):
+			im = obj
+		}
+	}
+	want := "q\n30 0 0 40 10 20 cm\n/Im1 Do\nQ\n"
+	if content := string(stream(t, ref(t, objs, page, "/Contents"))); !strings.Contains(content, want) {
+		t.Errorf("content does not contain %q:\n%s", want, content)
+	}
+	if got := ref(t, objs, page, "/Im1"); got != im {
+		t.Errorf("unexpected image resource: %q", got)
+	}
+	if got, want := stream(t, im), []byte{0xff, 0, 0, 0, 0, 0xff}; !bytes.Equal(got, want) {
+		t.Errorf("unexpected image samples: got:%x want:%x", got, want)
+	}
+	if got, want := stream(t, ref(t, objs, im, "/SMask")), []byte{0xff, 0x80}; !bytes.Equal(got, want) {
+		t.Errorf("unexpected image mask: got:%x want:%x", got, want)
+	}
+}
+
+func TestNum(t *testing.T) {
+	for _, test := range []struct {
+		v    float64
+		want string
+	}{
+		{v: 0, want: "0"},
+		{v: 1, want: "1"},
+		{v: -1.5, want: "-1.5"},
+		{v: 1e-7, want: "0"},
+		{v: -1e-7, want: "0"},
+		{v: 1e7, want: "10000000"},
+		{v: 0.123456, want: "0.12346"},
+	} {
+		if got := num(test.v); got != test.want {
+			t.Errorf("unexpected number for %v: got:%q want:%q", test.v, got, test.want)
+		}
+	}
+}
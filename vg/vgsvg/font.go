@@ -0,0 +1,65 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vgsvg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gonum/plot/vg"
+)
+
+// embed records that str is drawn using fnt so that
+// the glyphs of str are embedded by writeFonts. Fonts
+// with unknown TrueType data are not embedded.
+func (c *Canvas) embed(fnt vg.Font, str string) {
+	if fnt.Data() == nil {
+		return
+	}
+	for i := range c.fonts {
+		if c.fonts[i].Name() == fnt.Name() {
+			c.fonts[i].text.WriteString(str)
+			return
+		}
+	}
+	c.fonts = append(c.fonts, font{Font: fnt})
+	c.fonts[len(c.fonts)-1].text.WriteString(str)
+}
+
+// writeFonts writes a style element holding an @font-face
+// rule for each embedded font to w. The font data of each
+// rule is the subset of the font needed for its text.
+func (c *Canvas) writeFonts(w io.Writer) (int, error) {
+	if len(c.fonts) == 0 {
+		return 0, nil
+	}
+	var buf bytes.Buffer
+	buf.WriteString("<defs>\n<style type=\"text/css\"><![CDATA[\n")
+	for i := range c.fonts {
+		f := &c.fonts[i]
+		data, err := f.Subset(f.text.String())
+		if err != nil {
+			return 0, err
+		}
+		fmt.Fprintf(&buf, "@font-face {\n\tfont-family: '%s';\n\tsrc: url(data:font/ttf;base64,%s) format('truetype');\n}\n",
+			family(f.Name()), base64.StdEncoding.EncodeToString(data))
+	}
+	buf.WriteString("]]></style>\n</defs>\n")
+	return w.Write(buf.Bytes())
+}
+
+// family returns the font name with the characters that
+// may not appear in a quoted CSS font family name removed.
+func family(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`'"\<>&`, r) {
+			return -1
+		}
+		return r
+	}, name)
+}
@@ -9,8 +9,12 @@ package vgsvg
 import (
 	"bufio"
 	"bytes"
+	"encoding/base64"
+	"encoding/xml"
 	"fmt"
+	"image"
 	"image/color"
+	"image/png"
 	"io"
 	"math"
 
@@ -26,12 +30,38 @@ const DPI = 90
 // pr is the precision to use when outputting float64s.
 const pr = 5
 
+var _ vg.Annotator = (*Canvas)(nil)
+
 type Canvas struct {
+	// Annotate specifies whether the groups of elements
+	// described by annotations, such as the plotters of
+	// a plot and the points of a scatter, are written to
+	// the SVG. Annotations are not written by default.
+	Annotate bool
+
+	// ids counts the uses of each group id.
+	ids map[string]int
+
+	// groups holds the annotation groups that
+	// have been begun and not ended.
+	groups []group
+
 	svg  *svgo.SVG
 	w, h vg.Length
 	buf  *bytes.Buffer
 	ht   float64
 	stk  []context
+
+	// fonts are the non-standard fonts that
+	// have been drawn, along with their text.
+	fonts []font
+}
+
+// font is a font that is embedded in the SVG
+// using an @font-face rule.
+type font struct {
+	vg.Font
+	text bytes.Buffer
 }
 
 type context struct {
@@ -39,7 +69,18 @@ type context struct {
 	dashArray  []vg.Length
 	dashOffset vg.Length
 	lineWidth  vg.Length
-	gEnds      int
+
+	// transforms holds the transform attributes of
+	// the group elements begun in the context.
+	transforms []string
+}
+
+// group is an annotation group element.
+type group struct {
+	// depth and transforms are the depth of the stack
+	// of contexts and the number of transforms in the
+	// top context when the group was begun.
+	depth, transforms int
 }
 
 func New(w, h vg.Length) *Canvas {
@@ -96,33 +137,108 @@ func (c *Canvas) SetColor(clr color.Color) {
 
 func (c *Canvas) Rotate(rot float64) {
 	rot = rot * 180 / math.Pi
-	c.svg.Rotate(rot)
-	c.cur().gEnds++
+	c.transform(fmt.Sprintf("rotate(%g)", rot))
 }
 
 func (c *Canvas) Translate(x, y vg.Length) {
-	c.svg.Gtransform(fmt.Sprintf("translate(%.*g, %.*g)", pr, x.Dots(DPI), pr, y.Dots(DPI)))
-	c.cur().gEnds++
+	c.transform(fmt.Sprintf("translate(%.*g, %.*g)", pr, x.Dots(DPI), pr, y.Dots(DPI)))
 }
 
 func (c *Canvas) Scale(x, y float64) {
-	c.svg.ScaleXY(x, y)
-	c.cur().gEnds++
+	c.transform(fmt.Sprintf("scale(%g,%g)", x, y))
+}
+
+// transform begins a group element with the
+// given transform attribute.
+func (c *Canvas) transform(t string) {
+	c.svg.Gtransform(t)
+	c.cur().transforms = append(c.cur().transforms, t)
 }
 
 func (c *Canvas) Push() {
 	top := *c.cur()
-	top.gEnds = 0
+	top.transforms = nil
 	c.stk = append(c.stk, top)
 }
 
+// Pop ends the annotation groups begun since the
+// matching Push, and the transforms of the context.
 func (c *Canvas) Pop() {
-	for i := 0; i < c.cur().gEnds; i++ {
+	for len(c.groups) > 0 && c.groups[len(c.groups)-1].depth >= len(c.stk) {
+		c.EndGroup()
+	}
+	for i := 0; i < len(c.cur().transforms); i++ {
 		c.svg.Gend()
 	}
 	c.stk = c.stk[:len(c.stk)-1]
 }
 
+// Annotates implements the vg.Annotator interface,
+// returning whether Annotate is set.
+func (c *Canvas) Annotates() bool {
+	return c.Annotate
+}
+
+// BeginGroup implements the vg.Annotator interface,
+// beginning an SVG group element with the id and class
+// of the annotation if Annotate is set.  The annotation's
+// title is added to the group as a title element, which
+// is shown as a tooltip by most viewers.  An id that has
+// already been used in the SVG, as by the plots of a
+// Figure, is made unique by appending its count of uses.
+func (c *Canvas) BeginGroup(a vg.Annotation) {
+	if !c.Annotate {
+		return
+	}
+	c.buf.WriteString("<g")
+	if a.ID != "" {
+		if c.ids == nil {
+			c.ids = make(map[string]int)
+		}
+		id := a.ID
+		if n := c.ids[a.ID]; n > 0 {
+			id = fmt.Sprintf("%s-%d", a.ID, n)
+		}
+		c.ids[a.ID]++
+		fmt.Fprintf(c.buf, ` id="%s"`, escape(id))
+	}
+	if a.Class != "" {
+		fmt.Fprintf(c.buf, ` class="%s"`, escape(a.Class))
+	}
+	c.buf.WriteString(">\n")
+	if a.Title != "" {
+		fmt.Fprintf(c.buf, "<title>%s</title>\n", escape(a.Title))
+	}
+	c.groups = append(c.groups, group{depth: len(c.stk), transforms: len(c.cur().transforms)})
+}
+
+// EndGroup implements the vg.Annotator interface,
+// ending the most recently begun group. Transforms
+// begun within the group are ended before it and
+// begun again after it, so that they still apply.
+func (c *Canvas) EndGroup() {
+	if !c.Annotate || len(c.groups) == 0 {
+		return
+	}
+	g := c.groups[len(c.groups)-1]
+	c.groups = c.groups[:len(c.groups)-1]
+	var inner []string
+	for i, ctx := range c.stk[g.depth-1:] {
+		ts := ctx.transforms
+		if i == 0 {
+			ts = ts[g.transforms:]
+		}
+		inner = append(inner, ts...)
+	}
+	for i := 0; i < len(inner); i++ {
+		c.svg.Gend()
+	}
+	c.svg.Gend()
+	for _, t := range inner {
+		c.svg.Gtransform(t)
+	}
+}
+
 func (c *Canvas) Stroke(path vg.Path) {
 	if c.cur().lineWidth.Dots(DPI) <= 0 {
 		return
@@ -242,10 +358,16 @@ func large(a float64) int {
 	return 0
 }
 
+// FillString fills in text at the specified location
+// using the given font. Fonts other than the standard
+// fonts are referred to by name, and if their TrueType
+// data is known, the glyphs that are drawn are embedded
+// in the SVG using an @font-face rule.
 func (c *Canvas) FillString(font vg.Font, x, y vg.Length, str string) {
 	fontStr, ok := fontMap[font.Name()]
 	if !ok {
-		panic(fmt.Sprintf("Unknown font: %s", font.Name()))
+		fontStr = "font-family:'" + family(font.Name()) + "'"
+		c.embed(font, str)
 	}
 	sty := style(fontStr,
 		elm("font-size", "medium", "%.*gpt", pr, font.Size.Points()),
@@ -254,7 +376,27 @@ func (c *Canvas) FillString(font vg.Font, x, y vg.Length, str string) {
 		sty = "\n\t" + sty
 	}
 	fmt.Fprintf(c.buf, `<text x="%.*g" y="%.*g" transform="scale(1, -1)"%s>%s</text>`+"\n",
-		pr, x.Dots(DPI), pr, -y.Dots(DPI), sty, str)
+		pr, x.Dots(DPI), pr, -y.Dots(DPI), sty, escape(str))
+}
+
+// DrawImage draws the image scaled to fill the rectangle
+// of width w and height h with its lower left corner at x, y.
+// The image is embedded in the SVG as a PNG data URI, and is
+// drawn without smoothing between its pixels.
+func (c *Canvas) DrawImage(x, y, w, h vg.Length, img image.Image) {
+	if img.Bounds().Empty() {
+		return
+	}
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		panic(fmt.Errorf("vgsvg: failed to encode image: %v", err))
+	}
+	fmt.Fprintf(c.buf, `<image x="%.*g" y="%.*g" width="%.*g" height="%.*g" transform="scale(1, -1)"
+	preserveAspectRatio="none" style="image-rendering:pixelated"
+	xlink:href="data:image/png;base64,%s"/>`+"\n",
+		pr, x.Dots(DPI), pr, -(y + h).Dots(DPI), pr, w.Dots(DPI), pr, h.Dots(DPI),
+		base64.StdEncoding.EncodeToString(buf.Bytes()))
 }
 
 var (
@@ -295,7 +437,13 @@ func (c *Canvas) WriteTo(w io.Writer) (int64, error) {
 		}
 	}
 
-	m, err := fmt.Fprintln(b, "</svg>")
+	m, err := c.writeFonts(b)
+	n += int64(m)
+	if err != nil {
+		return n, err
+	}
+
+	m, err = fmt.Fprintln(b, "</svg>")
 	n += int64(m)
 	if err != nil {
 		return n, err
@@ -309,9 +457,9 @@ func (c *Canvas) WriteTo(w io.Writer) (int64, error) {
 func (c *Canvas) nEnds() int {
 	n := 1 // close the transform that moves the origin
 	for _, ctx := range c.stk {
-		n += ctx.gEnds
+		n += len(ctx.transforms)
 	}
-	return n
+	return n + len(c.groups)
 }
 
 // style returns a style string composed of
@@ -346,6 +494,13 @@ func elm(key, def, f string, vls ...interface{}) string {
 	return key + ":" + value
 }
 
+// escape returns s with the XML special characters escaped.
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
 // dashArrayString returns a string representing the
 // dash array specification.
 func dashArrayString(c *Canvas) string {
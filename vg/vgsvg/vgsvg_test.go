@@ -0,0 +1,176 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vgsvg_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/plotter"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/vgsvg"
+)
+
+func TestAnnotations(t *testing.T) {
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("failed to create plot: %v", err)
+	}
+	s, err := plotter.NewScatter(plotter.XYs{{1, 2}, {3, 4}})
+	if err != nil {
+		t.Fatalf("failed to create scatter: %v", err)
+	}
+	p.Add(s)
+	p.Legend.Add("series <a>", s)
+
+	c := vgsvg.New(4*vg.Inch, 4*vg.Inch)
+	p.Draw(draw.New(c))
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); err != nil {
+		t.Fatalf("failed to write svg: %v", err)
+	}
+	if got := buf.String(); strings.Contains(got, "<title>") {
+		t.Errorf("svg output contains annotations without Annotate set")
+	}
+
+	// Draw the plot twice, as the panels of a figure.
+	c = vgsvg.New(4*vg.Inch, 4*vg.Inch)
+	c.Annotate = true
+	dc := draw.New(c)
+	top, bottom := dc, dc
+	top.Min.Y = dc.Center().Y
+	bottom.Max.Y = dc.Center().Y
+	p.Draw(top)
+	p.Draw(bottom)
+	buf.Reset()
+	if _, err := c.WriteTo(&buf); err != nil {
+		t.Fatalf("failed to write svg: %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		`<g id="plotter0" class="plotter">` + "\n<title>series &lt;a&gt;</title>\n",
+		`<g id="plotter0-1" class="plotter">` + "\n<title>series &lt;a&gt;</title>\n",
+		`<g class="point">` + "\n<title>1, 2</title>\n",
+		`<g class="point">` + "\n<title>3, 4</title>\n",
+		`<g class="legend-entry">` + "\n<title>series &lt;a&gt;</title>\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("svg output does not contain %q", want)
+		}
+	}
+	if opened, closed := strings.Count(got, "<g"), strings.Count(got, "</g>"); opened != closed {
+		t.Errorf("unbalanced groups: %d opened, %d closed", opened, closed)
+	}
+}
+
+func TestAnnotationTransforms(t *testing.T) {
+	c := vgsvg.New(4*vg.Inch, 4*vg.Inch)
+	c.Annotate = true
+	c.BeginGroup(vg.Annotation{ID: "outer"})
+	c.Translate(vg.Inch, 0)
+	c.EndGroup()
+	var p vg.Path
+	p.Move(0, 0)
+	p.Line(vg.Inch, 0)
+	p.Line(0, vg.Inch)
+	p.Close()
+	c.Fill(p)
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); err != nil {
+		t.Fatalf("failed to write svg: %v", err)
+	}
+	got := buf.String()
+
+	// The translation is ended with the group and
+	// begun again, so that it applies to the path
+	// drawn after the group.
+	want := `<g id="outer">` + "\n" + `<g transform="translate(90, 0)">` + "\n</g>\n</g>\n" +
+		`<g transform="translate(90, 0)">` + "\n<path"
+	if !strings.Contains(got, want) {
+		t.Errorf("svg output does not contain %q:\n%s", want, got)
+	}
+	if opened, closed := strings.Count(got, "<g"), strings.Count(got, "</g>"); opened != closed {
+		t.Errorf("unbalanced groups: %d opened, %d closed", opened, closed)
+	}
+}
+
+func TestEmbeddedFont(t *testing.T) {
+	err := vg.LoadFont("Corporate Sans", filepath.Join("..", "fonts", "NimbusSanL-Regu.ttf"))
+	if err != nil {
+		t.Fatalf("failed to load font: %v", err)
+	}
+	fnt, err := vg.MakeFont("Corporate Sans", 12)
+	if err != nil {
+		t.Fatalf("failed to make font: %v", err)
+	}
+
+	c := vgsvg.New(2*vg.Inch, 2*vg.Inch)
+	c.FillString(fnt, 10, 10, "Text")
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); err != nil {
+		t.Fatalf("failed to write svg: %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		`font-family:'Corporate Sans'`,
+		"@font-face {\n\tfont-family: 'Corporate Sans';\n\tsrc: url(data:font/ttf;base64,",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("svg output does not contain %q", want)
+		}
+	}
+	if !strings.HasSuffix(got, "</defs>\n</svg>\n") {
+		t.Errorf("font definitions not at end of svg output")
+	}
+}
+
+func TestDrawImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(0, 0, color.NRGBA{R: 0xff, A: 0xff})
+
+	c := vgsvg.New(2*vg.Inch, 2*vg.Inch)
+	c.DrawImage(10, 10, 20, 20, img)
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); err != nil {
+		t.Fatalf("failed to write svg: %v", err)
+	}
+	got := buf.String()
+
+	const uri = "data:image/png;base64,"
+	want := `<image x="12.5" y="-37.5" width="25" height="25" transform="scale(1, -1)"`
+	if !strings.Contains(got, want) {
+		t.Errorf("svg output does not contain %q", want)
+	}
+	i := strings.Index(got, uri)
+	if i < 0 {
+		t.Fatalf("svg output does not contain image data")
+	}
+	data := got[i+len(uri):]
+	data = data[:strings.IndexByte(data, '"')]
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		t.Fatalf("failed to decode image data: %v", err)
+	}
+	dec, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("failed to decode png: %v", err)
+	}
+	if dec.Bounds() != img.Bounds() {
+		t.Errorf("unexpected image bounds: got:%v want:%v", dec.Bounds(), img.Bounds())
+	}
+	if r, _, _, _ := dec.At(0, 0).RGBA(); r != 0xffff {
+		t.Errorf("unexpected color of image: got:%v", dec.At(0, 0))
+	}
+}
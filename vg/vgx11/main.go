@@ -8,12 +8,11 @@ package main
 
 import (
 	"math/rand"
-	"time"
 
 	"github.com/gonum/plot"
 	"github.com/gonum/plot/plotter"
 	"github.com/gonum/plot/plotutil"
-	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg"
 	"github.com/gonum/plot/vg/vgx11"
 )
 
@@ -32,37 +31,22 @@ func main() {
 	err = plotutil.AddLinePoints(
 		p,
 		"First", randomPoints(15),
+		"Second", randomPoints(15),
+		"Third", randomPoints(15),
 	)
 	if err != nil {
 		panic(err)
 	}
 
-	cnvs, err := vgx11.New(4*96, 4*96, "Example")
-	if err != nil {
-		panic(err)
-	}
-
-	p.Draw(draw.New(cnvs))
-	cnvs.Paint()
-	time.Sleep(5 * time.Second)
-
-	err = plotutil.AddLinePoints(
-		p,
-		"Second", randomPoints(15),
-		"Third", randomPoints(15),
-	)
+	// Show the plot in a window that can be zoomed with
+	// the mouse wheel, panned by dragging and saved to
+	// points.png with the s key, until it is closed.
+	v, err := vgx11.NewViewer(p, 4*vg.Inch, 4*vg.Inch, "Example")
 	if err != nil {
 		panic(err)
 	}
-
-	p.Draw(draw.New(cnvs))
-	cnvs.Paint()
-	time.Sleep(10 * time.Second)
-
-	// Save the plot to a PNG file.
-	//        if err := p.Save(4, 4, "points.png"); err != nil {
-	//                panic(err)
-	//        }
+	v.SaveFile = "points.png"
+	v.Wait()
 }
 
 // randomPoints returns some random x, y points.
@@ -0,0 +1,530 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package vgx11
+
+import (
+	"image"
+	"log"
+	"math"
+	"sync"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil"
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/keybind"
+	"github.com/BurntSushi/xgbutil/xevent"
+	"github.com/BurntSushi/xgbutil/xgraphics"
+	"github.com/BurntSushi/xgbutil/xwindow"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/vgimg"
+)
+
+// Viewer is an interactive X window showing a plot.
+//
+// The plot is redrawn to fill the window when the window is
+// resized. Turning the mouse wheel zooms the plot in and out
+// about the pointer, and dragging with the left mouse button
+// pans it. Zooming and panning change the Min and Max of the
+// axes of a copy of the plot, so the plot itself is not
+// changed. The keys of the viewer are:
+//
+//	r, Home    reset the axes to their ranges in the plot
+//	s          save the plot as shown to SaveFile
+//	q, Escape  close the viewer
+type Viewer struct {
+	// SaveFile is the name of the file that the plot
+	// is saved to by the s key. The format of the file
+	// is given by its extension, as for plot.Save.
+	SaveFile string
+
+	// ZoomStep is the factor by which the axis ranges
+	// are scaled by each step of the mouse wheel.
+	ZoomStep float64
+
+	// plot returns the plot shown by the viewer.
+	plot func() *plot.Plot
+
+	x    *xgbutil.XUtil
+	win  *xwindow.Window
+	done chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+	ximg   *xgraphics.Image
+
+	// w and h are the size of the window in pixels.
+	w, h int
+
+	// home is the view of the plot as it was last drawn
+	// without zooming or panning, and cur is the view
+	// shown if moved is true.
+	home, cur view
+	moved     bool
+
+	// data is the data area of the plot as it was
+	// last drawn.
+	data draw.Rectangle
+
+	drag struct {
+		on   bool
+		x, y int
+		from view
+	}
+}
+
+// NewViewer returns a new viewer showing the plot p in a
+// window of the given size and name. The plot is redrawn
+// when Redraw is called, so changes to the plot are shown
+// by calling Redraw after making them.
+func NewViewer(p *plot.Plot, width, height vg.Length, name string) (*Viewer, error) {
+	return NewViewerFunc(func() *plot.Plot { return p }, width, height, name)
+}
+
+// NewViewerFunc returns a new viewer showing the plot returned
+// by f in a window of the given size and name. The function is
+// called each time that the viewer is redrawn. While the plot
+// is zoomed or panned, the axis ranges of the returned plots
+// are replaced by those of the view.
+func NewViewerFunc(f func() *plot.Plot, width, height vg.Length, name string) (*Viewer, error) {
+	X, err := xgbutil.NewConn()
+	if err != nil {
+		return nil, err
+	}
+	keybind.Initialize(X)
+	win, err := xwindow.Generate(X)
+	if err != nil {
+		X.Conn().Close()
+		return nil, err
+	}
+
+	v := &Viewer{
+		SaveFile: "plot.png",
+		ZoomStep: 1.25,
+		plot:     f,
+		x:        X,
+		win:      win,
+		done:     make(chan struct{}),
+		w:        int(width/vg.Inch*dpi + 0.5),
+		h:        int(height/vg.Inch*dpi + 0.5),
+	}
+	win.Create(X.RootWin(), 0, 0, v.w, v.h,
+		xproto.CwBackPixel|xproto.CwEventMask, 0xffffff,
+		xproto.EventMaskExposure|xproto.EventMaskStructureNotify|
+			xproto.EventMaskButtonPress|xproto.EventMaskButtonRelease|
+			xproto.EventMaskButtonMotion|xproto.EventMaskKeyPress)
+	err = ewmh.WmNameSet(X, win.Id, name)
+	if err != nil {
+		win.Destroy()
+		X.Conn().Close()
+		return nil, err
+	}
+	win.WMGracefulClose(func(*xwindow.Window) { v.Close() })
+
+	xevent.ExposeFun(func(_ *xgbutil.XUtil, e xevent.ExposeEvent) {
+		if e.Count == 0 {
+			v.paint()
+		}
+	}).Connect(X, win.Id)
+	xevent.ConfigureNotifyFun(func(_ *xgbutil.XUtil, e xevent.ConfigureNotifyEvent) {
+		v.resize(int(e.Width), int(e.Height))
+	}).Connect(X, win.Id)
+	xevent.ButtonPressFun(func(_ *xgbutil.XUtil, e xevent.ButtonPressEvent) {
+		switch e.Detail {
+		case xproto.ButtonIndex1:
+			v.startDrag(int(e.EventX), int(e.EventY))
+		case xproto.ButtonIndex4:
+			v.zoom(int(e.EventX), int(e.EventY), 1/v.ZoomStep)
+		case xproto.ButtonIndex5:
+			v.zoom(int(e.EventX), int(e.EventY), v.ZoomStep)
+		}
+	}).Connect(X, win.Id)
+	xevent.MotionNotifyFun(func(_ *xgbutil.XUtil, e xevent.MotionNotifyEvent) {
+		v.dragTo(int(e.EventX), int(e.EventY))
+	}).Connect(X, win.Id)
+	xevent.ButtonReleaseFun(func(_ *xgbutil.XUtil, e xevent.ButtonReleaseEvent) {
+		if e.Detail == xproto.ButtonIndex1 {
+			v.endDrag()
+		}
+	}).Connect(X, win.Id)
+	xevent.KeyPressFun(func(X *xgbutil.XUtil, e xevent.KeyPressEvent) {
+		v.key(keybind.LookupString(X, e.State, e.Detail))
+	}).Connect(X, win.Id)
+
+	win.Map()
+	v.Redraw()
+	go func() {
+		xevent.Main(X)
+		X.Conn().Close()
+		close(v.done)
+	}()
+	return v, nil
+}
+
+// Redraw redraws the plot in the window. It may be called
+// from any goroutine.
+func (v *Viewer) Redraw() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.redraw()
+}
+
+// Reset resets the axes of the plot to their ranges before
+// zooming and panning, and redraws the plot.
+func (v *Viewer) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.moved = false
+	v.drag.on = false
+	v.redraw()
+}
+
+// Save saves the plot as it is shown in the window to the
+// named file. The format of the file is given by its
+// extension, as for plot.Save.
+func (v *Viewer) Save(file string) error {
+	v.mu.Lock()
+	p := v.current()
+	w, h := v.size()
+	v.mu.Unlock()
+	return p.Save(w, h, file)
+}
+
+// Close closes the window of the viewer.
+func (v *Viewer) Close() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.closed {
+		return
+	}
+	v.closed = true
+	xevent.Quit(v.x)
+	if v.ximg != nil {
+		v.ximg.Destroy()
+		v.ximg = nil
+	}
+	// Destroying the window wakes the event
+	// loop so that it sees that it must quit.
+	v.win.Destroy()
+}
+
+// Wait waits until the window of the viewer is closed.
+func (v *Viewer) Wait() {
+	<-v.done
+}
+
+// current returns a copy of the plot with the
+// axis ranges of the current view.
+func (v *Viewer) current() *plot.Plot {
+	p := *v.plot()
+	if v.moved {
+		v.cur.apply(&p)
+	}
+	return &p
+}
+
+// size returns the size of the window.
+func (v *Viewer) size() (w, h vg.Length) {
+	return vg.Length(v.w) / dpi * vg.Inch, vg.Length(v.h) / dpi * vg.Inch
+}
+
+// redraw draws the plot to a new image and paints
+// it to the window. The lock must be held.
+func (v *Viewer) redraw() {
+	if v.closed || v.w <= 0 || v.h <= 0 {
+		return
+	}
+	p := v.current()
+	img := image.NewRGBA(image.Rect(0, 0, v.w, v.h))
+	c := draw.New(vgimg.NewWith(vgimg.UseImage(img), vgimg.UseDPI(dpi)))
+	p.Draw(c)
+	v.data = p.DataCanvas(c).Rectangle
+	if !v.moved {
+		v.home = viewOf(p)
+	}
+
+	if v.ximg != nil {
+		v.ximg.Destroy()
+	}
+	v.ximg = xgraphics.NewConvert(v.x, img)
+	err := v.ximg.XSurfaceSet(v.win.Id)
+	if err != nil {
+		log.Printf("vgx11: %v", err)
+		return
+	}
+	v.ximg.XDraw()
+	v.ximg.XPaint(v.win.Id)
+}
+
+// paint paints the last drawn image to the window.
+func (v *Viewer) paint() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.closed || v.ximg == nil {
+		return
+	}
+	v.ximg.XPaint(v.win.Id)
+}
+
+// resize redraws the plot to fill a window of the given size.
+func (v *Viewer) resize(w, h int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if w == v.w && h == v.h {
+		return
+	}
+	v.w, v.h = w, h
+	v.redraw()
+}
+
+// frac returns the position of the pixel at x, y as fractions
+// of the width and height of the data area of the plot, clamped
+// to the data area.
+func (v *Viewer) frac(x, y int) (fx, fy float64) {
+	px := vg.Length(x) / dpi * vg.Inch
+	py := vg.Length(v.h-y) / dpi * vg.Inch
+	fx = float64((px - v.data.Min.X) / (v.data.Max.X - v.data.Min.X))
+	fy = float64((py - v.data.Min.Y) / (v.data.Max.Y - v.data.Min.Y))
+	return math.Max(0, math.Min(fx, 1)), math.Max(0, math.Min(fy, 1))
+}
+
+// view returns the view currently shown.
+func (v *Viewer) view() view {
+	if v.moved {
+		return v.cur
+	}
+	return v.home
+}
+
+// zoom scales the axis ranges by f about the pixel at x, y.
+func (v *Viewer) zoom(x, y int, f float64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	fx, fy := v.frac(x, y)
+	v.cur = v.view().zoom(fx, fy, f)
+	v.moved = true
+	v.redraw()
+}
+
+// startDrag starts panning from the pixel at x, y.
+func (v *Viewer) startDrag(x, y int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.drag.on = true
+	v.drag.x, v.drag.y = x, y
+	v.drag.from = v.view()
+}
+
+// dragTo pans the plot so that the point under the pixel
+// where panning started is under the pixel at x, y.
+func (v *Viewer) dragTo(x, y int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !v.drag.on {
+		return
+	}
+	w, h := v.data.Max.X-v.data.Min.X, v.data.Max.Y-v.data.Min.Y
+	dx := float64(vg.Length(x-v.drag.x) / dpi * vg.Inch / w)
+	dy := float64(vg.Length(v.drag.y-y) / dpi * vg.Inch / h)
+	v.cur = v.drag.from.pan(-dx, -dy)
+	v.moved = true
+	v.redraw()
+}
+
+// endDrag ends panning.
+func (v *Viewer) endDrag() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.drag.on = false
+}
+
+// key handles a press of the named key.
+func (v *Viewer) key(name string) {
+	switch name {
+	case "r", "Home":
+		v.Reset()
+	case "s":
+		err := v.Save(v.SaveFile)
+		if err != nil {
+			log.Printf("vgx11: %v", err)
+		}
+	case "q", "Escape":
+		v.Close()
+	}
+}
+
+// view is the ranges of the X, Y, X2 and Y2 axes of a plot.
+// Zooming and panning act on the normalized ranges of the
+// axes, so that the view moves evenly across the plot and
+// stays in the domain of the scale of each axis.
+type view [4]axisView
+
+// axisView is the range of an axis and its scale.
+type axisView struct {
+	min, max float64
+	scale    plot.Normalizer
+}
+
+// viewOf returns the view of the plot p.
+func viewOf(p *plot.Plot) view {
+	var v view
+	for i, a := range axesOf(p) {
+		v[i] = axisView{min: a.Min, max: a.Max, scale: a.Scale}
+	}
+	return v
+}
+
+// axesOf returns the X, Y, X2 and Y2 axes of p.
+func axesOf(p *plot.Plot) [4]*plot.Axis {
+	return [4]*plot.Axis{&p.X, &p.Y, &p.X2, &p.Y2}
+}
+
+// apply sets the ranges of the axes of p to those of the view,
+// turning off AutoRange on them so that drawing p keeps the
+// view. Axes without a range, such as unused secondary axes,
+// are not changed.
+func (v view) apply(p *plot.Plot) {
+	for i, a := range axesOf(p) {
+		if v[i].ok() {
+			a.Min, a.Max = v[i].min, v[i].max
+			a.AutoRange = false
+		}
+	}
+}
+
+// zoom returns the view scaled by f about the point at
+// the fractions fx and fy of the ranges of the view. A
+// factor less than one zooms in.
+func (v view) zoom(fx, fy, f float64) view {
+	for i := range v {
+		t := fx
+		if i%2 == 1 {
+			t = fy
+		}
+		v[i] = v[i].between(t-t*f, t+(1-t)*f)
+	}
+	return v
+}
+
+// pan returns the view moved by the fractions dx and
+// dy of its ranges.
+func (v view) pan(dx, dy float64) view {
+	for i := range v {
+		d := dx
+		if i%2 == 1 {
+			d = dy
+		}
+		v[i] = v[i].between(d, 1+d)
+	}
+	return v
+}
+
+// ok returns whether the axis has a finite range.
+func (a axisView) ok() bool {
+	return a.min < a.max && !math.IsInf(a.min, 0) && !math.IsInf(a.max, 0)
+}
+
+// between returns the axis range between the normalized
+// positions s and t of the range of a.
+func (a axisView) between(s, t float64) axisView {
+	if !a.ok() {
+		return a
+	}
+	min, max := a.at(s), a.at(t)
+	if min > max {
+		min, max = max, min
+	}
+	if !(min < max) {
+		return a
+	}
+	return axisView{min: min, max: max, scale: a.scale}
+}
+
+// maxSteps is the greatest number of steps taken in
+// bracketing and bisecting when inverting a scale, which
+// is enough to span the range of float64.
+const maxSteps = 2200
+
+// at returns the value at the normalized position t of the range
+// of a. Positions beyond the domain of the scale are clamped to
+// the edge of the domain.
+func (a axisView) at(t float64) float64 {
+	switch a.scale.(type) {
+	case nil, plot.LinearScale:
+		return a.min + t*(a.max-a.min)
+	}
+
+	// The scale is inverted numerically. The function u is
+	// the position of x on the axis from min towards max,
+	// which increases with x. Outside the domain of the scale
+	// it is infinite, and where the scale is clamped it is
+	// flat.
+	n0, n1 := a.norm(a.min), a.norm(a.max)
+	if !(n0 != n1) {
+		return a.min
+	}
+	u := func(x float64) float64 {
+		n := a.norm(x)
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			if x < a.min {
+				return math.Inf(-1)
+			}
+			return math.Inf(1)
+		}
+		return (n - n0) / (n1 - n0)
+	}
+	s := (t - n0) / (n1 - n0)
+
+	// Bracket the position, limiting the target to
+	// the least or greatest position if the scale is
+	// clamped before reaching it.
+	lo, hi := a.min, a.max
+	orEqual := false
+	for w, i := a.max-a.min, 0; i < maxSteps && u(lo) > s; w, i = 2*w, i+1 {
+		next := lo - w
+		if u(next) == u(lo) {
+			s, orEqual = u(lo), true
+		}
+		lo = next
+	}
+	for w, i := a.max-a.min, 0; i < maxSteps && u(hi) < s; w, i = 2*w, i+1 {
+		next := hi + w
+		if u(next) == u(hi) {
+			s = u(hi)
+		}
+		hi = next
+	}
+
+	for i := 0; i < maxSteps; i++ {
+		mid := lo + (hi-lo)/2
+		if mid <= lo || mid >= hi {
+			break
+		}
+		if um := u(mid); um < s || orEqual && um == s {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	if math.IsInf(u(hi), 0) {
+		return lo
+	}
+	return hi
+}
+
+// norm returns x normalized by the scale of a, or NaN if
+// x is outside the domain of the scale.
+func (a axisView) norm(x float64) (n float64) {
+	defer func() {
+		if recover() != nil {
+			n = math.NaN()
+		}
+	}()
+	return a.scale.Normalize(a.min, a.max, x)
+}
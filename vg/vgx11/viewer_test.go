@@ -0,0 +1,156 @@
+// Copyright ©2015 The gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package vgx11
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/plotter"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/recorder"
+)
+
+func TestView(t *testing.T) {
+	near := func(a, b view) bool {
+		for i := range a {
+			if math.Abs(a[i].min-b[i].min) > 1e-9*math.Abs(b[i].min) && math.Abs(a[i].min-b[i].min) > 1e-12 ||
+				math.Abs(a[i].max-b[i].max) > 1e-9*math.Abs(b[i].max) && math.Abs(a[i].max-b[i].max) > 1e-12 {
+				return false
+			}
+		}
+		return true
+	}
+	lin := plot.LinearScale{}
+	log := plot.LogScale{}
+	none := axisView{min: math.Inf(1), max: math.Inf(-1), scale: lin}
+	v := view{{0, 10, lin}, {-1, 1, lin}, {-5, 5, lin}, none}
+
+	got := v.zoom(0.5, 0.5, 0.5)
+	if want := (view{{2.5, 7.5, lin}, {-0.5, 0.5, lin}, {-2.5, 2.5, lin}, none}); !near(got, want) {
+		t.Errorf("unexpected zoom about centre: got:%+v want:%+v", got, want)
+	}
+	got = v.zoom(0, 1, 2)
+	if want := (view{{0, 20, lin}, {-3, 1, lin}, {-5, 15, lin}, none}); !near(got, want) {
+		t.Errorf("unexpected zoom about corner: got:%+v want:%+v", got, want)
+	}
+	got = v.pan(0.1, -0.5)
+	if want := (view{{1, 11, lin}, {-2, 0, lin}, {-4, 6, lin}, none}); !near(got, want) {
+		t.Errorf("unexpected pan: got:%+v want:%+v", got, want)
+	}
+
+	// Log axes are zoomed and panned in proportion
+	// and stay positive.
+	v = view{{1, 100, log}, {1, 10, plot.InvertedScale{Normalizer: log}}, none, none}
+	got = v.pan(-0.5, 0.5)
+	if want := (view{{0.1, 10, log}, {1 / math.Sqrt(10), math.Sqrt(10), v[1].scale}, none, none}); !near(got, want) {
+		t.Errorf("unexpected pan of log axes: got:%+v want:%+v", got, want)
+	}
+	got = v.zoom(0.5, 0.5, 4)
+	if want := (view{{1e-3, 1e5, log}, {math.Pow(10, -1.5), math.Pow(10, 2.5), v[1].scale}, none, none}); !near(got, want) {
+		t.Errorf("unexpected zoom of log axes: got:%+v want:%+v", got, want)
+	}
+	got = v.pan(-100, 0)
+	if want := (axisView{1e-200, 1e-198, log}); !near(view{got[0]}, view{want}) {
+		t.Errorf("unexpected far pan of log axis: got:%+v want:%+v", got[0], want)
+	}
+
+	// Logit axes stay within the domain of the scale.
+	v = view{{0.1, 0.9, plot.LogitScale{}}, none, none, none}
+	got = v.zoom(0.5, 0.5, 100)
+	if got[0].min <= 0 || got[0].max >= 1 {
+		t.Errorf("unexpected zoom of logit axis: got:%+v", got[0])
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	line, err := plotter.NewLine(plotter.XYs{{0, 0}, {100, 100}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Add(line)
+	p.X.AutoRange = true
+	v = view{{0, 10, lin}, {-1, 1, lin}, {-5, 5, lin}, none}
+	v.apply(p)
+	p.Draw(draw.NewCanvas(new(recorder.Canvas), 100, 100))
+	if got := viewOf(p); got != v {
+		t.Errorf("unexpected view of plot: got:%+v want:%+v", got, v)
+	}
+}
+
+// TestViewer runs against an X server, such as Xvfb,
+// given by the DISPLAY environment variable.
+func TestViewer(t *testing.T) {
+	if !hasX11() {
+		t.Skip("no X11 environment")
+	}
+
+	line, err := plotter.NewLine(plotter.XYs{{0, 0}, {10, 10}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := plot.New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Add(line)
+
+	v, err := NewViewer(p, 4*vg.Inch, 3*vg.Inch, "test viewer")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer v.Close()
+	if home := v.home; home[0].min != 0 || home[0].max != 10 || home[1].min != 0 || home[1].max != 10 {
+		t.Errorf("unexpected home view: %+v", home)
+	}
+
+	// Zoom in about the centre of the data area.
+	x := int((v.data.Min.X + v.data.Max.X) / 2 / vg.Inch * dpi)
+	y := v.h - int((v.data.Min.Y+v.data.Max.Y)/2/vg.Inch*dpi)
+	v.zoom(x, y, 0.5)
+	if v.cur[0].max-v.cur[0].min >= 10 || v.cur[1].max-v.cur[1].min >= 10 {
+		t.Errorf("plot not zoomed in: %+v", v.cur)
+	}
+	if p.X.Min != 0 || p.X.Max != 10 {
+		t.Errorf("plot changed by zoom: X range [%v, %v]", p.X.Min, p.X.Max)
+	}
+
+	v.startDrag(x, y)
+	v.dragTo(x+10, y)
+	v.endDrag()
+	if v.cur[0].min >= 2.5 {
+		t.Errorf("plot not panned: %+v", v.cur)
+	}
+
+	dir, err := ioutil.TempDir("", "vgx11")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "view.png")
+	err = v.Save(file)
+	if err != nil {
+		t.Errorf("unexpected error saving view: %v", err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("view not saved: %v", err)
+	}
+
+	v.key("r")
+	if v.moved {
+		t.Errorf("view not reset")
+	}
+	v.key("q")
+	v.Wait()
+}